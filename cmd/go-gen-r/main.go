@@ -1,43 +1,211 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/BounkhongDev/go-generator/pkg/generator"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the "pgx" database/sql driver for from-db
 )
 
 func usage() {
 	fmt.Fprintf(os.Stderr, "go-gen-r - simple Go project generator\n\n")
 	fmt.Fprintf(os.Stderr, "Usage:\n")
-	fmt.Fprintf(os.Stderr, "  go-gen-r init\n")
-	fmt.Fprintf(os.Stderr, "  go-gen-r <module_name>\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r init [--name <project>] [--dir <dir>] [--db postgres|mysql] [--skip-example] [--no-deps]\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r <module_name> [field:type ...] [--id uint|int64|uuid|ulid] [--pagination offset|cursor]\n")
 	fmt.Fprintf(os.Stderr, "  go-gen-r test <module_name>\n")
 	fmt.Fprintf(os.Stderr, "  go-gen-r test <module_name> --force\n")
 	fmt.Fprintf(os.Stderr, "  go-gen-r auto-test <module_name>\n")
 	fmt.Fprintf(os.Stderr, "  go-gen-r auto-test <module_name> --force\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r upgrade [--diff] [--yes] [--backup]\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r apply <schema.yaml|schema.json> [--diff] [--yes] [--backup]\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r from-sql <schema.sql> [--diff] [--yes] [--backup]\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r from-openapi <openapi.yaml|openapi.json> [--diff] [--yes] [--backup]\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r from-json <module_name> <sample.json> [--flatten] [--diff] [--yes] [--backup]\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r from-db [--dsn <dsn>] [--tables <t1,t2>] [--schema <name>] [--diff] [--yes] [--backup]\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r remove <module_name> [--yes]\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r rename <module_name> <new_name> [--rename-table]\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r templates list\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r templates eject <name> [--dir <dir>] [--force]\n")
 	fmt.Fprintf(os.Stderr, "  go-gen-r run-test\n\n")
 	fmt.Fprintf(os.Stderr, "Examples:\n")
 	fmt.Fprintf(os.Stderr, "  go-gen-r init\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r init --name hrms-service --dir ./hrms --db postgres --skip-example --no-deps\n")
 	fmt.Fprintf(os.Stderr, "  go-gen-r user\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r product name:string price:decimal stock:int\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r order customer:belongs_to tags:many2many items:has_many:order_item\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r customer name:string email:email --id uuid\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r audit_log action:string actor_id:int64 --id int64 --pagination cursor\n")
 	fmt.Fprintf(os.Stderr, "  go-gen-r test users\n")
 	fmt.Fprintf(os.Stderr, "  go-gen-r test users --force\n")
 	fmt.Fprintf(os.Stderr, "  go-gen-r auto-test users\n")
 	fmt.Fprintf(os.Stderr, "  go-gen-r auto-test users --force\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r auto-test users --force --diff --backup\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r upgrade --dry-run\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r apply schema.yaml --dry-run\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r from-sql schema.sql\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r from-openapi openapi.yaml --dry-run\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r from-json order ./sample_order.json\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r from-db --tables users,orders --dry-run\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r remove product --dry-run\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r rename user_account account --rename-table\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r templates eject controller\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r product --templates ./my-templates\n")
+	fmt.Fprintf(os.Stderr, "  go-gen-r product name:string --dry-run\n")
 	fmt.Fprintf(os.Stderr, "  go-gen-r run-test\n\n")
 	fmt.Fprintf(os.Stderr, "Notes:\n")
+	fmt.Fprintf(os.Stderr, "  - 'init' prompts for the project name only on a terminal; scripts should pass --name\n")
 	fmt.Fprintf(os.Stderr, "  - module_name must not contain '-'\n")
 	fmt.Fprintf(os.Stderr, "  - use underscores for multi-word names (e.g. user_account)\n")
+	fmt.Fprintf(os.Stderr, "  - field types: string, text, email, uuid, ulid, int, int64, uint, float, decimal, bool, time\n")
+	fmt.Fprintf(os.Stderr, "  - relations: name:belongs_to, name:has_many and name:many2many, with ':<module>' when the\n")
+	fmt.Fprintf(os.Stderr, "    related module is not named after the field (e.g. items:has_many:order_item)\n")
+	fmt.Fprintf(os.Stderr, "  - without fields, the module gets a single name:string field\n")
+	fmt.Fprintf(os.Stderr, "  - '--id' sets the primary key: uint (auto increment, the default), int64 (snowflake),\n")
+	fmt.Fprintf(os.Stderr, "    uuid (version 7, PostgreSQL) or ulid; foreign keys to the module take the same type\n")
+	fmt.Fprintf(os.Stderr, "  - '--pagination cursor' pages the list endpoint with after/before cursors instead of\n")
+	fmt.Fprintf(os.Stderr, "    page numbers and skips counting the rows, for large tables\n")
 	fmt.Fprintf(os.Stderr, "  - 'test <module>' generates test files\n")
 	fmt.Fprintf(os.Stderr, "  - add '--force' to regenerate existing test files\n")
 	fmt.Fprintf(os.Stderr, "  - 'auto-test <module>' regenerates service tests from service methods\n")
+	fmt.Fprintf(os.Stderr, "  - '--diff' shows what --force would change and asks before overwriting ('--yes' skips\n")
+	fmt.Fprintf(os.Stderr, "    the question); '--backup' keeps <file>.orig copies of overwritten files\n")
+	fmt.Fprintf(os.Stderr, "  - '--templates <dir>' overrides built-in templates by file name; .go-gen/templates\n")
+	fmt.Fprintf(os.Stderr, "    in the project and ~/.go-gen/templates are used automatically\n")
+	fmt.Fprintf(os.Stderr, "  - '--dry-run' prints what init, <module>, test and auto-test would do without writing\n")
+	fmt.Fprintf(os.Stderr, "  - 'upgrade' re-renders the files recorded in .go-gen.lock with the current templates;\n")
+	fmt.Fprintf(os.Stderr, "    edited files get conflict markers where they differ from the new output\n")
+	fmt.Fprintf(os.Stderr, "  - 'apply <schema>' generates or updates the modules described in a YAML or JSON file;\n")
+	fmt.Fprintf(os.Stderr, "    edited files get conflict markers where they differ from the new output\n")
+	fmt.Fprintf(os.Stderr, "  - 'from-sql <file>' generates one module per CREATE TABLE in PostgreSQL DDL (e.g.\n")
+	fmt.Fprintf(os.Stderr, "    pg_dump --schema-only) and updates them like 'apply' when run again\n")
+	fmt.Fprintf(os.Stderr, "  - 'from-openapi <file>' generates one module per resource path of an OpenAPI 3 document,\n")
+	fmt.Fprintf(os.Stderr, "    with its fields, endpoints, list filters and status codes\n")
+	fmt.Fprintf(os.Stderr, "  - 'from-json <module> <file>' infers the module's fields from a sample payload; nested\n")
+	fmt.Fprintf(os.Stderr, "    objects and arrays of objects become related modules ('--flatten' inlines nested objects)\n")
+	fmt.Fprintf(os.Stderr, "  - 'from-db' generates one module per table of a live PostgreSQL database, connecting\n")
+	fmt.Fprintf(os.Stderr, "    with '--dsn' or the postgres settings of config.yaml and POSTGRES_* variables\n")
+	fmt.Fprintf(os.Stderr, "  - 'remove <module>' deletes the module's files and unwires it from main.go, the routes\n")
+	fmt.Fprintf(os.Stderr, "    and migrations; it asks first when files were edited or are used elsewhere ('--yes'\n")
+	fmt.Fprintf(os.Stderr, "    skips the question)\n")
+	fmt.Fprintf(os.Stderr, "  - 'rename <module> <new_name>' renames files, types, references, wiring, routes and the\n")
+	fmt.Fprintf(os.Stderr, "    table name; '--rename-table' adds a migration that renames the existing table\n")
 	fmt.Fprintf(os.Stderr, "  - 'run-test' runs: go test ./...\n")
 }
 
+// parseCommandArgs parses flags that may appear before, between or after the
+// positional arguments of a command (e.g. "test users --force") and returns
+// the positional arguments.
+func parseCommandArgs(flags *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			os.Exit(2)
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// options are the generator options set by command flags.
+var options generator.Options
+
+// newFlagSet returns a flag set for command with the flags shared by all
+// generating commands.
+func newFlagSet(command string) *flag.FlagSet {
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	flags.Usage = usage
+	flags.StringVar(&options.TemplateDir, "templates", "", "directory with templates overriding the built-in ones")
+	flags.BoolVar(&options.DryRun, "dry-run", false, "print the files that would be created, overwritten, skipped or patched without writing them")
+	return flags
+}
+
+// addOverwriteFlags adds the flags controlling how --force overwrites files.
+func addOverwriteFlags(flags *flag.FlagSet) (force, yes *bool) {
+	force = flags.Bool("force", false, "regenerate existing files")
+	return force, addConfirmFlags(flags)
+}
+
+// addConfirmFlags adds --diff, --yes and --backup for commands that overwrite
+// files.
+func addConfirmFlags(flags *flag.FlagSet) (yes *bool) {
+	flags.BoolVar(&options.ShowDiff, "diff", false, "show a unified diff before overwriting a file and ask for confirmation")
+	yes = flags.Bool("yes", false, "overwrite without asking (with --diff)")
+	flags.BoolVar(&options.Backup, "backup", false, "keep a <file>.orig copy of every overwritten file")
+	return yes
+}
+
+// promptOverwrite asks on stdin whether path may be overwritten.
+func promptOverwrite(path string) bool {
+	fmt.Printf("Overwrite %s? [y/N] ", path)
+	answer, _ := stdin.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// printPlanSummary prints the totals of a dry run.
+func printPlanSummary(g *generator.Generator) {
+	counts := map[string]int{}
+	for _, change := range g.TakePlan() {
+		counts[change.Action]++
+	}
+	fmt.Printf("\nDry run: %d to create, %d to overwrite, %d to patch, %d to delete, %d skipped, %d command(s) to run. No files were written.\n",
+		counts[generator.ActionCreate], counts[generator.ActionOverwrite], counts[generator.ActionPatch],
+		counts[generator.ActionDelete], counts[generator.ActionSkip], counts[generator.ActionRun])
+}
+
+// exitWithErrors prints every error joined into err as a summary and exits
+// with status 1, so scripts do not mistake a partial scaffold for success.
+func exitWithErrors(err error) {
+	errs := flattenErrors(err)
+	fmt.Fprintf(os.Stderr, "\nGeneration failed with %d error(s):\n", len(errs))
+	for _, e := range errs {
+		fmt.Fprintf(os.Stderr, "  - %v\n", e)
+	}
+	os.Exit(1)
+}
+
+// flattenErrors returns the leaves of errors built with errors.Join.
+func flattenErrors(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, e := range joined.Unwrap() {
+		errs = append(errs, flattenErrors(e)...)
+	}
+	return errs
+}
+
+// moduleArg validates and returns the module name argument.
+func moduleArg(args []string, usageLine string) string {
+	if len(args) < 1 {
+		log.Fatal("Usage: " + usageLine)
+	}
+	moduleName := strings.TrimSpace(args[0])
+	if moduleName == "" {
+		log.Fatal("Module name must not be empty")
+	}
+	if strings.Contains(moduleName, "-") {
+		log.Fatal("Module name must not contain -")
+	}
+	return moduleName
+}
+
 func main() {
 	flag.Usage = usage
 	flag.Parse()
@@ -47,104 +215,432 @@ func main() {
 	}
 
 	command := flag.Arg(0)
+	args := flag.Args()[1:]
 
-	if command == "init" {
-		fmt.Print("Enter Project Name: ")
-		var projectName string
-		fmt.Scan(&projectName)
-
-		if err := generator.Init(projectName); err != nil {
+	var g *generator.Generator
+	switch command {
+	case "init":
+		g = runInit(args)
+	case "test":
+		g = runTest(args)
+	case "auto-test":
+		g = runAutoTest(args)
+	case "upgrade":
+		g = runUpgrade(args)
+	case "apply":
+		g = runApply(args)
+	case "from-sql":
+		g = runFromSQL(args)
+	case "from-openapi":
+		g = runFromOpenAPI(args)
+	case "from-json":
+		g = runFromJSON(args)
+	case "from-db":
+		g = runFromDB(args)
+	case "remove":
+		g = runRemove(args)
+	case "rename":
+		g = runRename(args)
+	case "templates":
+		runTemplates(args)
+	case "run-test":
+		cmd := exec.Command("go", "test", "./...")
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
 			log.Fatal(err)
 		}
-		return
+	default:
+		g = runModule(flag.Args())
 	}
 
-	if command == "test" {
-		if flag.NArg() < 2 {
-			log.Fatal("Usage: go-gen-r test <module_name>")
-		}
+	if g != nil && g.DryRun {
+		printPlanSummary(g)
+	}
+}
+
+func runInit(args []string) *generator.Generator {
+	flags := newFlagSet("init")
+	name := flags.String("name", "", "Go module name of the project (prompted for when omitted on a terminal)")
+	dir := flags.String("dir", "", "directory to create the project in (default: current directory)")
+	db := flags.String("db", "postgres", "database driver: "+strings.Join(generator.Databases(), ", "))
+	skipExample := flags.Bool("skip-example", false, "do not generate the example module")
+	noDeps := flags.Bool("no-deps", false, "skip go mod tidy and go get")
+	parseCommandArgs(flags, args)
 
-		moduleName := strings.TrimSpace(flag.Arg(1))
-		if moduleName == "" {
-			log.Fatal("Module name must not be empty")
+	projectName := strings.TrimSpace(*name)
+	if projectName == "" {
+		// Only prompt on a terminal; piped input is read silently.
+		if isTerminal(os.Stdin) {
+			fmt.Print("Enter Project Name: ")
 		}
-		if strings.Contains(moduleName, "-") {
-			log.Fatal("Module name must not contain -")
+		projectName, _ = stdin.ReadString('\n')
+		if projectName = strings.TrimSpace(projectName); projectName == "" {
+			log.Fatal("Project name is required: use go-gen-r init --name <project>")
 		}
+	}
 
-		projectName, err := generator.ResolveProjectName()
-		if err != nil {
+	if *dir != "" && !options.DryRun {
+		if err := os.MkdirAll(*dir, os.ModePerm); err != nil {
 			log.Fatal(err)
 		}
+	}
 
-		force := false
-		for i := 2; i < flag.NArg(); i++ {
-			if flag.Arg(i) == "--force" {
-				force = true
-			}
-		}
+	g := generator.New(*dir, options)
+	err := g.InitWithOptions(generator.InitOptions{
+		ProjectName: projectName,
+		Database:    *db,
+		SkipExample: *skipExample,
+		NoDeps:      *noDeps,
+	})
+	if err != nil {
+		exitWithErrors(err)
+	}
+	return g
+}
 
-		if force {
-			if err := generator.GenerateTestFilesForce(moduleName, projectName); err != nil {
-				log.Fatal(err)
-			}
-			return
-		}
+// isTerminal reports whether f is an interactive terminal rather than a pipe
+// or file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
 
-		if err := generator.GenerateTestFiles(moduleName, projectName); err != nil {
-			log.Fatal(err)
-		}
-		return
+func runTest(args []string) *generator.Generator {
+	flags := newFlagSet("test")
+	force, yes := addOverwriteFlags(flags)
+	moduleName := moduleArg(parseCommandArgs(flags, args), "go-gen-r test <module_name>")
+	if options.ShowDiff && !*yes {
+		options.ConfirmOverwrite = promptOverwrite
 	}
 
-	if command == "run-test" {
-		cmd := exec.Command("go", "test", "./...")
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
-			log.Fatal(err)
-		}
-		return
+	g := generator.New("", options)
+	projectName, err := g.ResolveProjectName()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *force {
+		err = g.GenerateTestFilesForce(moduleName, projectName)
+	} else {
+		err = g.GenerateTestFiles(moduleName, projectName)
+	}
+	if err != nil {
+		exitWithErrors(err)
+	}
+	return g
+}
+
+func runAutoTest(args []string) *generator.Generator {
+	flags := newFlagSet("auto-test")
+	force, yes := addOverwriteFlags(flags)
+	moduleName := moduleArg(parseCommandArgs(flags, args), "go-gen-r auto-test <module_name>")
+	if options.ShowDiff && !*yes {
+		options.ConfirmOverwrite = promptOverwrite
 	}
 
-	if command == "auto-test" {
-		if flag.NArg() < 2 {
-			log.Fatal("Usage: go-gen-r auto-test <module_name>")
+	g := generator.New("", options)
+	projectName, err := g.ResolveProjectName()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := g.GenerateAutoServiceTests(moduleName, projectName, *force); err != nil {
+		exitWithErrors(err)
+	}
+	return g
+}
+
+func runUpgrade(args []string) *generator.Generator {
+	flags := newFlagSet("upgrade")
+	yes := addConfirmFlags(flags)
+	if len(parseCommandArgs(flags, args)) > 0 {
+		log.Fatal("Usage: go-gen-r upgrade [--diff] [--yes] [--backup] [--dry-run]")
+	}
+	if options.ShowDiff && !*yes {
+		options.ConfirmOverwrite = promptOverwrite
+	}
+
+	g := generator.New("", options)
+	result, err := g.Upgrade()
+	if err != nil {
+		exitWithErrors(err)
+	}
+	if g.DryRun {
+		return g
+	}
+	fmt.Printf("\nUpgraded %d file(s).\n", len(result.Upgraded))
+	if len(result.Merged) > 0 {
+		fmt.Printf("Merged template changes into %d edited file(s).\n", len(result.Merged))
+	}
+	if len(result.Conflicts) > 0 {
+		fmt.Printf("%d edited file(s) have conflicts; resolve the <<<<<<< markers in:\n", len(result.Conflicts))
+		for _, name := range result.Conflicts {
+			fmt.Println("  -", name)
 		}
+		os.Exit(1)
+	}
+	return g
+}
 
-		moduleName := strings.TrimSpace(flag.Arg(1))
-		if moduleName == "" {
-			log.Fatal("Module name must not be empty")
+func runApply(args []string) *generator.Generator {
+	flags := newFlagSet("apply")
+	yes := addConfirmFlags(flags)
+	positional := parseCommandArgs(flags, args)
+	if len(positional) != 1 {
+		log.Fatal("Usage: go-gen-r apply <schema.yaml|schema.json> [--diff] [--yes] [--backup] [--dry-run]")
+	}
+	if options.ShowDiff && !*yes {
+		options.ConfirmOverwrite = promptOverwrite
+	}
+	schema, err := generator.LoadSchema(positional[0])
+	if err != nil {
+		exitWithErrors(err)
+	}
+
+	return applySchema(schema, positional[0])
+}
+
+func runFromSQL(args []string) *generator.Generator {
+	flags := newFlagSet("from-sql")
+	yes := addConfirmFlags(flags)
+	positional := parseCommandArgs(flags, args)
+	if len(positional) != 1 {
+		log.Fatal("Usage: go-gen-r from-sql <schema.sql> [--diff] [--yes] [--backup] [--dry-run]")
+	}
+	if options.ShowDiff && !*yes {
+		options.ConfirmOverwrite = promptOverwrite
+	}
+	schema, warnings, err := generator.LoadSQLSchema(positional[0])
+	if err != nil {
+		exitWithErrors(err)
+	}
+	for _, warning := range warnings {
+		fmt.Println("Warning:", warning)
+	}
+	return applySchema(schema, positional[0])
+}
+
+func runFromOpenAPI(args []string) *generator.Generator {
+	flags := newFlagSet("from-openapi")
+	yes := addConfirmFlags(flags)
+	positional := parseCommandArgs(flags, args)
+	if len(positional) != 1 {
+		log.Fatal("Usage: go-gen-r from-openapi <openapi.yaml|openapi.json> [--diff] [--yes] [--backup] [--dry-run]")
+	}
+	if options.ShowDiff && !*yes {
+		options.ConfirmOverwrite = promptOverwrite
+	}
+	schema, warnings, err := generator.LoadOpenAPI(positional[0])
+	if err != nil {
+		exitWithErrors(err)
+	}
+	for _, warning := range warnings {
+		fmt.Println("Warning:", warning)
+	}
+	return applySchema(schema, positional[0])
+}
+
+func runFromJSON(args []string) *generator.Generator {
+	const usageLine = "go-gen-r from-json <module_name> <sample.json> [--flatten] [--diff] [--yes] [--backup] [--dry-run]"
+	flags := newFlagSet("from-json")
+	yes := addConfirmFlags(flags)
+	flatten := flags.Bool("flatten", false, "inline nested objects as prefixed fields instead of child modules")
+	positional := parseCommandArgs(flags, args)
+	if len(positional) != 2 {
+		log.Fatal("Usage: " + usageLine)
+	}
+	if options.ShowDiff && !*yes {
+		options.ConfirmOverwrite = promptOverwrite
+	}
+	schema, warnings, err := generator.LoadJSONSample(positional[0], positional[1], *flatten)
+	if err != nil {
+		exitWithErrors(err)
+	}
+	for _, warning := range warnings {
+		fmt.Println("Warning:", warning)
+	}
+	return applySchema(schema, positional[1])
+}
+
+func runFromDB(args []string) *generator.Generator {
+	const usageLine = "go-gen-r from-db [--dsn <dsn>] [--tables <t1,t2>] [--schema <name>] [--diff] [--yes] [--backup] [--dry-run]"
+	flags := newFlagSet("from-db")
+	yes := addConfirmFlags(flags)
+	dsn := flags.String("dsn", "", "PostgreSQL connection string (default: the postgres settings of config.yaml)")
+	tables := flags.String("tables", "", "comma-separated tables to generate (default: all tables)")
+	dbSchema := flags.String("schema", "public", "PostgreSQL schema of the tables")
+	positional := parseCommandArgs(flags, args)
+	if len(positional) != 0 {
+		log.Fatal("Usage: " + usageLine)
+	}
+	if options.ShowDiff && !*yes {
+		options.ConfirmOverwrite = promptOverwrite
+	}
+	if *dsn == "" {
+		var err error
+		if *dsn, err = generator.New("", options).DatabaseDSN(); err != nil {
+			exitWithErrors(err)
 		}
-		if strings.Contains(moduleName, "-") {
-			log.Fatal("Module name must not contain -")
+	}
+	opts := generator.IntrospectOptions{DBSchema: *dbSchema}
+	for _, table := range strings.Split(*tables, ",") {
+		if table = strings.TrimSpace(table); table != "" {
+			opts.Tables = append(opts.Tables, table)
 		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	schema, warnings, err := generator.LoadDatabaseSchema(ctx, "pgx", *dsn, opts)
+	if err != nil {
+		exitWithErrors(err)
+	}
+	for _, warning := range warnings {
+		fmt.Println("Warning:", warning)
+	}
+	return applySchema(schema, "the database schema")
+}
 
-		projectName, err := generator.ResolveProjectName()
-		if err != nil {
-			log.Fatal(err)
+// applySchema applies schema and prints a summary; it exits with status 1
+// when edited files got conflict markers.
+func applySchema(schema *generator.Schema, source string) *generator.Generator {
+	g := generator.New("", options)
+	result, err := g.Apply(schema)
+	if err != nil {
+		exitWithErrors(err)
+	}
+	if g.DryRun {
+		return g
+	}
+	fmt.Printf("\nApplied %s: %d module(s) created, %d updated.\n", source, len(result.Created), len(result.Updated))
+	if len(result.Unlisted) > 0 {
+		fmt.Printf("Not in the schema and left alone: %s (see go-gen-r remove)\n", strings.Join(result.Unlisted, ", "))
+	}
+	if len(result.Conflicts) > 0 {
+		fmt.Printf("%d edited file(s) have conflicts; resolve the <<<<<<< markers in:\n", len(result.Conflicts))
+		for _, name := range result.Conflicts {
+			fmt.Println("  -", name)
 		}
+		os.Exit(1)
+	}
+	return g
+}
 
-		force := false
-		for i := 2; i < flag.NArg(); i++ {
-			if flag.Arg(i) == "--force" {
-				force = true
-			}
-		}
+func runRemove(args []string) *generator.Generator {
+	const usageLine = "go-gen-r remove <module_name> [--yes] [--dry-run]"
+	flags := newFlagSet("remove")
+	yes := flags.Bool("yes", false, "remove without asking about edited files and references")
+	positional := parseCommandArgs(flags, args)
+	if len(positional) > 1 {
+		log.Fatal("Usage: " + usageLine)
+	}
+	moduleName := moduleArg(positional, usageLine)
 
-		if err := generator.GenerateAutoServiceTests(moduleName, projectName, force); err != nil {
-			log.Fatal(err)
+	g := generator.New("", options)
+	plan, err := g.PlanRemoval(moduleName)
+	if err != nil {
+		exitWithErrors(err)
+	}
+	if len(plan.Edited) > 0 {
+		fmt.Println("Warning: these files were edited since they were generated:")
+		for _, name := range plan.Edited {
+			fmt.Println("  -", name)
 		}
-		return
 	}
+	if len(plan.References) > 0 {
+		fmt.Printf("Warning: module %s is still used outside its files:\n", moduleName)
+		for _, ref := range plan.References {
+			fmt.Println("  -", ref)
+		}
+	}
+	if (len(plan.Edited) > 0 || len(plan.References) > 0) && !*yes && !g.DryRun {
+		if !isTerminal(os.Stdin) {
+			log.Fatal("Refusing to remove module " + moduleName + " without confirmation; pass --yes")
+		}
+		fmt.Printf("Remove module %s anyway? [y/N] ", moduleName)
+		answer, _ := stdin.ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			fmt.Println("Nothing removed.")
+			return nil
+		}
+	}
+
+	if err := g.RemoveModule(moduleName); err != nil {
+		exitWithErrors(err)
+	}
+	if !g.DryRun {
+		fmt.Printf("\nRemoved module %s (%d file(s)).\n", moduleName, len(plan.Files))
+	}
+	return g
+}
+
+func runRename(args []string) *generator.Generator {
+	const usageLine = "go-gen-r rename <module_name> <new_name> [--rename-table] [--dry-run]"
+	flags := newFlagSet("rename")
+	renameTable := flags.Bool("rename-table", false, "add a migration renaming the module's table so existing rows are kept")
+	positional := parseCommandArgs(flags, args)
+	if len(positional) != 2 {
+		log.Fatal("Usage: " + usageLine)
+	}
+	oldName := moduleArg(positional[:1], usageLine)
+	newName := moduleArg(positional[1:], usageLine)
+
+	g := generator.New("", options)
+	result, err := g.RenameModule(oldName, newName, *renameTable)
+	if err != nil {
+		exitWithErrors(err)
+	}
+	if g.DryRun {
+		return g
+	}
+	fmt.Printf("\nRenamed module %s to %s.\n", oldName, newName)
+	if !*renameTable {
+		fmt.Printf("The model now uses table %s; rows in %s are not moved unless you rename the table (see --rename-table).\n", result.NewTable, result.OldTable)
+	}
+	return g
+}
 
-	{
-		moduleName := command
-		if strings.Contains(moduleName, "-") {
-			log.Fatal("Module name must not contain -")
+func runTemplates(args []string) {
+	flags := flag.NewFlagSet("templates", flag.ExitOnError)
+	flags.Usage = usage
+	dir := flags.String("dir", "", "directory to eject into (default .go-gen/templates)")
+	force := flags.Bool("force", false, "overwrite an already ejected template")
+	positional := parseCommandArgs(flags, args)
+	if len(positional) < 1 {
+		log.Fatal("Usage: go-gen-r templates list | go-gen-r templates eject <name>")
+	}
+
+	switch positional[0] {
+	case "list":
+		for _, name := range generator.TemplateNames() {
+			fmt.Println(name)
 		}
-		if err := generator.GenerateModule(moduleName); err != nil {
-			log.Fatal(err)
+	case "eject":
+		if len(positional) < 2 {
+			log.Fatal("Usage: go-gen-r templates eject <name>")
 		}
+		for _, name := range positional[1:] {
+			target, err := generator.EjectTemplate(name, *dir, *force)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println("Ejected template:", target)
+		}
+	default:
+		log.Fatalf("Unknown templates command %q (use list or eject)", positional[0])
+	}
+}
+
+func runModule(args []string) *generator.Generator {
+	flags := newFlagSet("module")
+	id := flags.String("id", "uint", "primary key type: "+strings.Join(generator.IDTypes(), ", "))
+	pagination := flags.String("pagination", "offset", "list pagination: "+strings.Join(generator.PaginationModes(), ", "))
+	positional := parseCommandArgs(flags, args)
+	moduleName := moduleArg(positional, "go-gen-r <module_name> [field:type ...] [--id uint|int64|uuid|ulid] [--pagination offset|cursor]")
+	g := generator.New("", options)
+	opts := generator.ModuleOptions{ID: *id, Pagination: *pagination}
+	if err := g.GenerateModuleWithOptions(moduleName, opts, positional[1:]...); err != nil {
+		exitWithErrors(err)
 	}
+	return g
 }
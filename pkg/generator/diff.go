@@ -0,0 +1,240 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// diffOp is one line of an edit script: ' ' keeps, '-' deletes and '+'
+// inserts a line.
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// splitLines splits content into lines without their trailing newlines.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// diffLines returns a shortest edit script turning a into b, based on the
+// longest common subsequence of lines.
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	return ops
+}
+
+// unifiedDiff returns a unified diff of before and after for path, or an empty
+// string when they are equal.
+func unifiedDiff(path, before, after string) string {
+	if before == after {
+		return ""
+	}
+	ops := diffLines(splitLines(before), splitLines(after))
+
+	// Line numbers in before (a) and after (b) at the start of each op.
+	aLine := make([]int, len(ops)+1)
+	bLine := make([]int, len(ops)+1)
+	for k, op := range ops {
+		aLine[k+1], bLine[k+1] = aLine[k], bLine[k]
+		if op.kind != '+' {
+			aLine[k+1]++
+		}
+		if op.kind != '-' {
+			bLine[k+1]++
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- a/%s\n+++ b/%s\n", path, path)
+	for k := 0; k < len(ops); {
+		if ops[k].kind == ' ' {
+			k++
+			continue
+		}
+		// Grow the hunk while changes are at most 2*diffContext lines apart.
+		start := max(0, k-diffContext)
+		end := k
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContext {
+				break
+			}
+			end = next
+		}
+		stop := min(len(ops), end+diffContext)
+
+		aStart, bStart := aLine[start]+1, bLine[start]+1
+		aCount, bCount := aLine[stop]-aLine[start], bLine[stop]-bLine[start]
+		if aCount == 0 {
+			aStart--
+		}
+		if bCount == 0 {
+			bStart--
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+		for _, op := range ops[start:stop] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.line)
+			out.WriteByte('\n')
+		}
+		k = stop
+	}
+	return out.String()
+}
+
+// Conflict markers written by mergeConflicts and mergeThreeWay, as used by
+// git.
+const (
+	conflictStart = "<<<<<<< "
+	conflictSep   = "======="
+	conflictEnd   = ">>>>>>> "
+)
+
+// mergeConflicts returns ours with every region that differs from theirs
+// replaced by conflict markers showing both versions, and the number of
+// regions. It is the merge of last resort, when the base the two versions
+// were edited from is unknown; see mergeThreeWay.
+func mergeConflicts(ours, theirs, oursLabel, theirsLabel string) (string, int) {
+	ops := diffLines(splitLines(ours), splitLines(theirs))
+	var out strings.Builder
+	conflicts := 0
+	for k := 0; k < len(ops); {
+		if ops[k].kind == ' ' {
+			out.WriteString(ops[k].line + "\n")
+			k++
+			continue
+		}
+		var oursLines, theirsLines []string
+		for ; k < len(ops) && ops[k].kind != ' '; k++ {
+			if ops[k].kind == '-' {
+				oursLines = append(oursLines, ops[k].line)
+			} else {
+				theirsLines = append(theirsLines, ops[k].line)
+			}
+		}
+		conflicts++
+		out.WriteString(conflictStart + oursLabel + "\n")
+		for _, line := range oursLines {
+			out.WriteString(line + "\n")
+		}
+		out.WriteString(conflictSep + "\n")
+		for _, line := range theirsLines {
+			out.WriteString(line + "\n")
+		}
+		out.WriteString(conflictEnd + theirsLabel + "\n")
+	}
+	return out.String(), conflicts
+}
+
+// mergeThreeWay merges the changes from base to ours and from base to theirs,
+// as git merge does: regions only one side changed take that side's lines,
+// and regions both changed differently get conflict markers showing both
+// versions. It returns the merged content and the number of conflicts.
+func mergeThreeWay(base, ours, theirs, oursLabel, theirsLabel string) (string, int) {
+	b, o, t := splitLines(base), splitLines(ours), splitLines(theirs)
+	inOurs, inTheirs := matchLines(b, o), matchLines(b, t)
+	var out strings.Builder
+	write := func(lines []string) {
+		for _, line := range lines {
+			out.WriteString(line + "\n")
+		}
+	}
+	conflicts := 0
+	i, j, k := 0, 0, 0
+	for i < len(b) || j < len(o) || k < len(t) {
+		if i < len(b) && inOurs[i] == j && inTheirs[i] == k {
+			write(b[i : i+1]) // unchanged on both sides
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+		// The changed region runs to the next base line both sides kept.
+		ni, nj, nk := i, len(o), len(t)
+		for ; ni < len(b); ni++ {
+			if inOurs[ni] >= 0 && inTheirs[ni] >= 0 {
+				nj, nk = inOurs[ni], inTheirs[ni]
+				break
+			}
+		}
+		baseLines, oursLines, theirsLines := b[i:ni], o[j:nj], t[k:nk]
+		switch {
+		case slices.Equal(oursLines, baseLines):
+			write(theirsLines)
+		case slices.Equal(theirsLines, baseLines), slices.Equal(oursLines, theirsLines):
+			write(oursLines)
+		default:
+			conflicts++
+			out.WriteString(conflictStart + oursLabel + "\n")
+			write(oursLines)
+			out.WriteString(conflictSep + "\n")
+			write(theirsLines)
+			out.WriteString(conflictEnd + theirsLabel + "\n")
+		}
+		i, j, k = ni, nj, nk
+	}
+	return out.String(), conflicts
+}
+
+// matchLines returns, for each line of a, the index of the line of b it is
+// kept as in diffLines(a, b), or -1 when it is deleted.
+func matchLines(a, b []string) []int {
+	match := make([]int, len(a))
+	i, j := 0, 0
+	for _, op := range diffLines(a, b) {
+		switch op.kind {
+		case ' ':
+			match[i] = j
+			i++
+			j++
+		case '-':
+			match[i] = -1
+			i++
+		default:
+			j++
+		}
+	}
+	return match
+}
@@ -0,0 +1,118 @@
+package generator
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{
+			"changed line",
+			"a\nb\nc\n",
+			"a\nB\nc\n",
+			"--- a/f.go\n+++ b/f.go\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			"added to empty file",
+			"",
+			"a\n",
+			"--- a/f.go\n+++ b/f.go\n@@ -0,0 +1,1 @@\n+a\n",
+		},
+		{
+			"separate hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny\n",
+			"--- a/f.go\n+++ b/f.go\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+y\n",
+		},
+		{
+			"close changes share a hunk",
+			"1\n2\n3\n4\n5\n6\n7\n8\n",
+			"x\n2\n3\n4\n5\n6\n7\ny\n",
+			"--- a/f.go\n+++ b/f.go\n@@ -1,8 +1,8 @@\n-1\n+x\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+y\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("f.go", tt.before, tt.after); got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		ours      string
+		theirs    string
+		want      string
+		conflicts int
+	}{
+		{"equal", "a\nb\n", "a\nb\n", "a\nb\n", 0},
+		{
+			"changed line",
+			"a\nb\nc\n",
+			"a\nB\nc\n",
+			"a\n<<<<<<< ours\nb\n=======\nB\n>>>>>>> theirs\nc\n",
+			1,
+		},
+		{
+			"added and removed lines",
+			"a\nb\n",
+			"x\na\n",
+			"<<<<<<< ours\n=======\nx\n>>>>>>> theirs\na\n<<<<<<< ours\nb\n=======\n>>>>>>> theirs\n",
+			2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := mergeConflicts(tt.ours, tt.theirs, "ours", "theirs")
+			if got != tt.want || conflicts != tt.conflicts {
+				t.Errorf("mergeConflicts() = %q, %d; want %q, %d", got, conflicts, tt.want, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestMergeThreeWay(t *testing.T) {
+	tests := []struct {
+		name             string
+		base, ours, them string
+		want             string
+		conflicts        int
+	}{
+		{"unchanged", "a\nb\n", "a\nb\n", "a\nb\n", "a\nb\n", 0},
+		{"only ours changed", "a\nb\nc\n", "a\nb\nc\nfunc Discount() {}\n", "a\nb\nc\n", "a\nb\nc\nfunc Discount() {}\n", 0},
+		{"only theirs changed", "a\nb\nc\n", "a\nb\nc\n", "a\nB\nc\n", "a\nB\nc\n", 0},
+		{
+			"both changed apart",
+			"a\nb\nc\nd\n",
+			"a\nb\nc\nd\nfunc Discount() {}\n",
+			"A\nb\nc\nd\n",
+			"A\nb\nc\nd\nfunc Discount() {}\n",
+			0,
+		},
+		{"same change", "a\nb\n", "a\nB\n", "a\nB\n", "a\nB\n", 0},
+		{"removed by theirs", "a\nb\nc\n", "a\nb\nc\nd\n", "a\nc\n", "a\nc\nd\n", 0},
+		{
+			"both changed the same line",
+			"a\nb\nc\n",
+			"a\nmine\nc\n",
+			"a\ntheirs\nc\n",
+			"a\n<<<<<<< ours\nmine\n=======\ntheirs\n>>>>>>> theirs\nc\n",
+			1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicts := mergeThreeWay(tt.base, tt.ours, tt.them, "ours", "theirs")
+			if got != tt.want || conflicts != tt.conflicts {
+				t.Errorf("mergeThreeWay() = %q, %d; want %q, %d", got, conflicts, tt.want, tt.conflicts)
+			}
+		})
+	}
+}
@@ -0,0 +1,694 @@
+package generator
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// Field describes a single model attribute parsed from a "name:type" spec
+// such as "price:decimal".
+type Field struct {
+	Name     string `json:"name,omitempty"`     // snake_case name used for JSON keys and columns (e.g. "unit_price")
+	GoName   string `json:"goName,omitempty"`   // exported Go identifier (e.g. "UnitPrice")
+	Type     string `json:"type,omitempty"`     // normalized spec type (e.g. "decimal")
+	GoType   string `json:"goType,omitempty"`   // Go type used in models and requests (e.g. "float64")
+	GormTag  string `json:"gormTag,omitempty"`  // value of the gorm struct tag on the model
+	Validate string `json:"validate,omitempty"` // value of the validate struct tag on requests; empty for none
+
+	// Options set in a schema file (see Apply).
+	Optional bool   `json:"optional,omitempty"` // nullable column, not required by validation
+	Unique   bool   `json:"unique,omitempty"`   // unique index on the column
+	Size     int    `json:"size,omitempty"`     // maximum length of string and email columns; 255 when 0
+	Rules    string `json:"rules,omitempty"`    // validate tag replacing the type's; "-" for none
+	// ColumnType is the database type of the column replacing the type's,
+	// e.g. "numeric(18,4)" for a column imported from an existing schema.
+	ColumnType string `json:"columnType,omitempty"`
+
+	// Relation is "belongs_to", "has_many" or "many2many" for a field declaring
+	// an association with the module Model (e.g. "order_item"). A belongs_to
+	// field is the foreign key column (customer_id); the others are not columns.
+	Relation string `json:"relation,omitempty"`
+	Model    string `json:"model,omitempty"`
+	ModelID  string `json:"modelId,omitempty"` // ID type of Model; see IDTypes
+	relationKeys
+
+	// Sample and Updated are Go literals used by fixtures and generated tests.
+	Sample  string `json:"sample,omitempty"`
+	Updated string `json:"updated,omitempty"`
+}
+
+// relationKeys pin the keys and join table of an association, which
+// otherwise follow the module names. RenameModule sets them so a renamed model
+// keeps using the existing columns and tables.
+type relationKeys struct {
+	ForeignKey     string `json:"foreignKey,omitempty"`     // has_many: foreign key field of Model (e.g. "OrderID")
+	JoinTable      string `json:"joinTable,omitempty"`      // many2many: join table (e.g. "order_tags")
+	JoinForeignKey string `json:"joinForeignKey,omitempty"` // many2many: join column of the module
+	JoinReferences string `json:"joinReferences,omitempty"` // many2many: join column of Model
+}
+
+type fieldType struct {
+	goType   string
+	gorm     string
+	validate string
+}
+
+// fieldTypes maps the types accepted on the command line to their Go, GORM and
+// validator representations.
+var fieldTypes = map[string]fieldType{
+	"string":  {goType: "string", gorm: "size:255;not null", validate: "required,min=1,max=255"},
+	"text":    {goType: "string", gorm: "type:text;not null", validate: "required"},
+	"email":   {goType: "string", gorm: "size:255;not null", validate: "required,email,max=255"},
+	"uuid":    {goType: "string", gorm: "type:uuid;not null", validate: "required,uuid"},
+	"ulid":    {goType: "string", gorm: "type:char(26);not null", validate: "required,ulid"},
+	"int":     {goType: "int", gorm: "not null"},
+	"int64":   {goType: "int64", gorm: "not null"},
+	"uint":    {goType: "uint", gorm: "not null"},
+	"float":   {goType: "float64", gorm: "not null"},
+	"decimal": {goType: "float64", gorm: "type:decimal(12,2);not null", validate: "gte=0"},
+	"bool":    {goType: "bool", gorm: "not null"},
+	"time":    {goType: "time.Time", gorm: "not null", validate: "required"},
+}
+
+// fieldTypeAliases lets users write common synonyms for the canonical types.
+var fieldTypeAliases = map[string]string{
+	"str":      "string",
+	"varchar":  "string",
+	"integer":  "int",
+	"bigint":   "int64",
+	"float64":  "float",
+	"number":   "decimal",
+	"money":    "decimal",
+	"boolean":  "bool",
+	"date":     "time",
+	"datetime": "time",
+}
+
+// reservedFields are columns every generated model already has.
+var reservedFields = map[string]struct{}{
+	"id":         {},
+	"created_at": {},
+	"updated_at": {},
+	"deleted_at": {},
+}
+
+// goInitialisms are name segments rendered in upper case in Go identifiers.
+var goInitialisms = map[string]string{
+	"id":   "ID",
+	"ip":   "IP",
+	"url":  "URL",
+	"uri":  "URI",
+	"api":  "API",
+	"uuid": "UUID",
+	"json": "JSON",
+	"http": "HTTP",
+	"sku":  "SKU",
+	"ids":  "IDs",
+}
+
+var fieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// defaultFieldSpecs is used when a module is generated without field specs.
+var defaultFieldSpecs = []string{"name:string"}
+
+// relationTypes are the field spec types declaring an association.
+var relationTypes = []string{"belongs_to", "has_many", "many2many"}
+
+// ParseFields parses field specs of the form "name:type" (e.g. "price:decimal")
+// into fields. Supported types are string, text, email, uuid, ulid, int, int64,
+// uint, float, decimal, bool and time. Relations are declared as
+// "name:relation[:module]": "customer:belongs_to" adds a customer_id foreign
+// key, "items:has_many:order_item" and "tags:many2many" add associations. The
+// module defaults to the name (singular for has_many and many2many).
+func ParseFields(specs []string) ([]Field, error) {
+	fields := make([]Field, 0, len(specs))
+	seen := map[string]struct{}{}
+	for _, spec := range specs {
+		field, err := parseField(spec)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := seen[field.Name]; exists {
+			return nil, fmt.Errorf("duplicate field %q", field.Name)
+		}
+		seen[field.Name] = struct{}{}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func parseField(spec string) (Field, error) {
+	parts := strings.Split(strings.TrimSpace(spec), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Field{}, fmt.Errorf("invalid field %q: expected name:type", spec)
+	}
+	name := strings.ToLower(strings.TrimSpace(parts[0]))
+	typeName := strings.ToLower(strings.TrimSpace(parts[1]))
+	if len(parts) == 3 && !slices.Contains(relationTypes, typeName) {
+		return Field{}, fmt.Errorf("invalid field %q: expected name:type or name:relation:module", spec)
+	}
+
+	if !fieldNamePattern.MatchString(name) {
+		return Field{}, fmt.Errorf("invalid field %q: name must be snake_case (e.g. unit_price)", spec)
+	}
+	if _, reserved := reservedFields[name]; reserved {
+		return Field{}, fmt.Errorf("invalid field %q: %s is generated automatically", spec, name)
+	}
+	if slices.Contains(relationTypes, typeName) {
+		model := ""
+		if len(parts) == 3 {
+			model = strings.ToLower(strings.TrimSpace(parts[2]))
+		}
+		field, err := newRelationField(name, typeName, model)
+		if err != nil {
+			return Field{}, fmt.Errorf("invalid field %q: %w", spec, err)
+		}
+		return field, nil
+	}
+	return newField(name, typeName)
+}
+
+// newRelationField returns the field declaring a relation with model; see
+// ParseFields.
+func newRelationField(name, relation, model string) (Field, error) {
+	if relation == "belongs_to" {
+		name = strings.TrimSuffix(name, "_id")
+		if model == "" {
+			model = name
+		}
+	} else if model == "" {
+		model = toSingular(name)
+	}
+	if !fieldNamePattern.MatchString(model) {
+		return Field{}, fmt.Errorf("module %q must be snake_case (e.g. order_item)", model)
+	}
+	if relation != "belongs_to" {
+		return Field{
+			Name:     name,
+			GoName:   toGoName(name),
+			Type:     relation,
+			GoType:   "[]" + toPascal(model),
+			Relation: relation,
+			Model:    model,
+		}, nil
+	}
+	field, err := newField(name+"_id", "uint")
+	if err != nil {
+		return Field{}, err
+	}
+	field.GormTag, field.Validate = "not null;index", "required"
+	field.Relation, field.Model = relation, model
+	return field, nil
+}
+
+// withModelID returns the relation f for a model whose ID type is id (see
+// IDTypes): the foreign key of a belongs_to field, and the IDs a many2many
+// field is set from, get the type of the model's ID.
+func (f Field) withModelID(id string) Field {
+	id = normalizeID(id)
+	if f.Relation == "" || f.ModelID == id {
+		return f
+	}
+	f.ModelID = id
+	if f.Relation != "belongs_to" {
+		return f
+	}
+	key, _ := newField(f.Name, idSpecTypes[id])
+	f.Type, f.GoType, f.GormTag, f.Validate = key.Type, key.GoType, key.GormTag+";index", cmp.Or(key.Validate, "required")
+	return f.withOptions(f.options())
+}
+
+// spec returns the field spec f was parsed from, without options.
+func (f Field) spec() string {
+	switch f.Relation {
+	case "":
+		return f.Name + ":" + f.Type
+	case "belongs_to":
+		return f.Association() + ":" + f.Relation + ":" + f.Model
+	}
+	return f.Name + ":" + f.Relation + ":" + f.Model
+}
+
+// IsColumn reports whether f is a column of the model's table, which
+// has_many and many2many fields are not.
+func (f Field) IsColumn() bool {
+	return f.Relation == "" || f.Relation == "belongs_to"
+}
+
+// Association returns the snake_case name of a belongs_to association, the
+// foreign key without "_id" (customer_id -> customer), and the name of other
+// relations.
+func (f Field) Association() string {
+	return strings.TrimSuffix(f.Name, "_id")
+}
+
+// ModelName returns the Go type of the associated model, e.g. "OrderItem".
+func (f Field) ModelName() string {
+	return toPascal(f.Model)
+}
+
+// IDsName returns the snake_case name of the request field holding the IDs
+// of a many2many association (tags -> tag_ids).
+func (f Field) IDsName() string {
+	return toSingular(f.Name) + "_ids"
+}
+
+// IDsGoName returns the Go name of the request field holding the IDs of a
+// many2many association (tags -> TagIDs).
+func (f Field) IDsGoName() string {
+	return toGoName(f.IDsName())
+}
+
+func newField(name, typeName string) (Field, error) {
+	if alias, ok := fieldTypeAliases[typeName]; ok {
+		typeName = alias
+	}
+	ft, ok := fieldTypes[typeName]
+	if !ok {
+		return Field{}, fmt.Errorf("unsupported type %q for field %s", typeName, name)
+	}
+	return Field{
+		Name:     name,
+		GoName:   toGoName(name),
+		Type:     typeName,
+		GoType:   ft.goType,
+		GormTag:  ft.gorm,
+		Validate: ft.validate,
+	}, nil
+}
+
+// fieldOptions are the settings of a field beyond its type; see Field.
+type fieldOptions struct {
+	Optional   bool
+	Unique     bool
+	Size       int
+	Rules      string
+	ColumnType string
+}
+
+func (f Field) options() fieldOptions {
+	return fieldOptions{Optional: f.Optional, Unique: f.Unique, Size: f.Size, Rules: f.Rules, ColumnType: f.ColumnType}
+}
+
+// withOptions returns f with the options set and its GORM and validate tags
+// adjusted to them.
+func (f Field) withOptions(o fieldOptions) Field {
+	f.Optional, f.Unique, f.Size, f.Rules, f.ColumnType = o.Optional, o.Unique, o.Size, o.Rules, o.ColumnType
+	if o.ColumnType != "" {
+		f.GormTag = withGormType(f.GormTag, o.ColumnType)
+	}
+	if o.Optional && f.IsColumn() && !strings.HasPrefix(f.GoType, "*") {
+		// NULL instead of a zero value, or of a foreign key to no row, so
+		// updates keep the NULLs of the column.
+		f.GoType = "*" + f.GoType
+	}
+	if o.Size > 0 {
+		f.GormTag = strings.Replace(f.GormTag, "size:255", fmt.Sprintf("size:%d", o.Size), 1)
+		f.Validate = strings.Replace(f.Validate, "max=255", fmt.Sprintf("max=%d", o.Size), 1)
+	}
+	if o.Optional {
+		var gorm []string
+		for _, part := range strings.Split(f.GormTag, ";") {
+			if part != "not null" && part != "" {
+				gorm = append(gorm, part)
+			}
+		}
+		f.GormTag = strings.Join(gorm, ";")
+
+		validate := []string{"omitempty"}
+		for _, rule := range strings.Split(f.Validate, ",") {
+			if rule != "required" && rule != "" {
+				validate = append(validate, rule)
+			}
+		}
+		f.Validate = ""
+		if len(validate) > 1 {
+			f.Validate = strings.Join(validate, ",")
+		}
+	}
+	if o.Unique {
+		f.GormTag = strings.TrimPrefix(f.GormTag+";uniqueIndex", ";")
+	}
+	switch o.Rules {
+	case "":
+	case "-":
+		f.Validate = ""
+	default:
+		f.Validate = o.Rules
+	}
+	return f
+}
+
+// defaultFields returns the fields used when no specs are given.
+func defaultFields() []Field {
+	fields, _ := ParseFields(defaultFieldSpecs)
+	return fields
+}
+
+// withSamples returns a copy of fields with Sample and Updated literals filled
+// in for the given module.
+func withSamples(moduleName string, fields []Field) []Field {
+	result := make([]Field, len(fields))
+	for i, f := range fields {
+		switch {
+		case strings.HasPrefix(f.GoType, "*"):
+			f.Sample, f.Updated = "nil", "nil"
+			result[i] = f
+			continue
+		case !f.IsColumn():
+			result[i] = f
+			continue
+		}
+		switch f.Type {
+		case "string", "text":
+			f.Sample = fmt.Sprintf("%q", "valid "+moduleName+" "+f.Name)
+			f.Updated = fmt.Sprintf("%q", "updated "+moduleName+" "+f.Name)
+		case "email":
+			f.Sample = `"valid@example.com"`
+			f.Updated = `"updated@example.com"`
+		case "uuid":
+			f.Sample = `"8a7e4c1e-2f7b-4b8e-9f43-2d2f5a1c7e10"`
+			f.Updated = `"0c9d6f3a-5b1e-4f7a-8c2d-9e4b3a1f6d20"`
+		case "ulid":
+			f.Sample = `"01J1V6X8Q3ZK4N5P6R7S8T9V0W"`
+			f.Updated = `"01J1V6X8Q3ZK4N5P6R7S8T9V0X"`
+		case "int", "int64", "uint":
+			f.Sample = "1"
+			f.Updated = "2"
+		case "float", "decimal":
+			f.Sample = "9.99"
+			f.Updated = "19.99"
+		case "bool":
+			f.Sample = "true"
+			f.Updated = "false"
+		case "time":
+			f.Sample = "time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)"
+			f.Updated = "time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)"
+		}
+		result[i] = f
+	}
+	return result
+}
+
+// hasTimeField reports whether any field needs the "time" import.
+func hasTimeField(fields []Field) bool {
+	for _, f := range fields {
+		if strings.TrimPrefix(f.GoType, "*") == "time.Time" {
+			return true
+		}
+	}
+	return false
+}
+
+// toPascal converts a snake_case module name to PascalCase ("user_account" -> "UserAccount").
+func toPascal(s string) string {
+	return strings.Replace(
+		cases.Title(language.Und, cases.NoLower).String(strings.ReplaceAll(s, "_", " ")),
+		" ", "", -1,
+	)
+}
+
+// toGoName converts a snake_case field name to an exported Go identifier,
+// honoring common initialisms ("customer_id" -> "CustomerID").
+func toGoName(s string) string {
+	var builder strings.Builder
+	for _, part := range strings.Split(s, "_") {
+		if part == "" {
+			continue
+		}
+		if initialism, ok := goInitialisms[part]; ok {
+			builder.WriteString(initialism)
+			continue
+		}
+		builder.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return builder.String()
+}
+
+// structField is a single line of a generated struct type.
+type structField struct {
+	Name string
+	Type string
+	Tag  string
+}
+
+// alignStructFields pads names and types so the fields line up like gofmt
+// output when rendered as "Name Type `Tag`".
+func alignStructFields(fields []structField) []structField {
+	nameWidth, typeWidth := 0, 0
+	for _, f := range fields {
+		nameWidth = max(nameWidth, len(f.Name))
+		typeWidth = max(typeWidth, len(f.Type))
+	}
+	aligned := make([]structField, len(fields))
+	for i, f := range fields {
+		aligned[i] = structField{
+			Name: fmt.Sprintf("%-*s", nameWidth, f.Name),
+			Type: fmt.Sprintf("%-*s", typeWidth, f.Type),
+			Tag:  f.Tag,
+		}
+	}
+	return aligned
+}
+
+// keyWidth returns the widest Go name, used to align composite literal keys.
+func keyWidth(fields []Field) int {
+	width := 0
+	for _, f := range fields {
+		width = max(width, len(f.GoName))
+	}
+	return width
+}
+
+// alignedKey returns "GoName:" padded so values line up like gofmt output.
+func alignedKey(f Field, width int) string {
+	return f.GoName + ":" + strings.Repeat(" ", width-len(f.GoName))
+}
+
+// withGormType returns the gorm tag with its column type set to columnType.
+func withGormType(tag, columnType string) string {
+	parts := strings.Split(tag, ";")
+	for i, part := range parts {
+		if strings.HasPrefix(part, "type:") {
+			parts[i] = "type:" + columnType
+			return strings.Join(parts, ";")
+		}
+	}
+	return strings.TrimSuffix("type:"+columnType+";"+tag, ";")
+}
+
+// gormType returns the column type set in a gorm tag, e.g. "decimal(12,2)".
+func gormType(tag string) string {
+	for _, part := range strings.Split(tag, ";") {
+		if columnType, ok := strings.CutPrefix(part, "type:"); ok {
+			return columnType
+		}
+	}
+	return ""
+}
+
+// columnTypePattern matches the column types a field can set, such as
+// "numeric(18,4)" or "jsonb".
+var columnTypePattern = regexp.MustCompile(`^[a-z][a-z0-9_ ]*(\(\d+(, ?\d+)?\))?$`)
+
+// detectModelFields reads internal/models/<module>.go and returns the fields
+// of the model struct, skipping ID and timestamp columns. It returns
+// os.ErrNotExist when the model has not been generated yet.
+func (g *Generator) detectModelFields(moduleName string) ([]Field, error) {
+	modelPath := filepath.Join(WORKDIR+"models", moduleName+".go")
+	src, err := g.readFile(modelPath)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, modelPath, src, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse model file %s: %w", modelPath, err)
+	}
+
+	modelName := toPascal(moduleName)
+	var structType *ast.StructType
+	ast.Inspect(node, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != modelName {
+			return true
+		}
+		structType, _ = spec.Type.(*ast.StructType)
+		return false
+	})
+	if structType == nil {
+		return nil, fmt.Errorf("model %s not found in %s", modelName, modelPath)
+	}
+
+	var fields []Field
+	belongsTo := map[string]Field{} // belongs_to fields by foreign key Go name
+	for _, astField := range structType.Fields.List {
+		if len(astField.Names) != 1 || astField.Tag == nil {
+			continue
+		}
+		tag := reflect.StructTag(strings.Trim(astField.Tag.Value, "`"))
+		name := strings.Split(tag.Get("json"), ",")[0]
+		if _, reserved := reservedFields[name]; reserved || name == "" {
+			continue
+		}
+		goType, gormTag := exprString(astField.Type), tag.Get("gorm")
+		if model, ok := strings.CutPrefix(goType, "[]"); ok {
+			relation := "has_many"
+			if strings.Contains(gormTag, "many2many:") {
+				relation = "many2many"
+			}
+			if field, err := newRelationField(name, relation, toSnakeCase(model)); err == nil {
+				field.relationKeys = parseRelationKeys(moduleName, field, gormTag)
+				fields = append(fields, field)
+			}
+			continue
+		}
+		if _, foreignKey, ok := strings.Cut(gormTag, "foreignKey:"); ok {
+			if field, err := newRelationField(name, "belongs_to", toSnakeCase(strings.TrimPrefix(goType, "*"))); err == nil {
+				belongsTo[strings.Split(foreignKey, ";")[0]] = field
+			}
+			continue
+		}
+		field, err := newField(name, specTypeFor(strings.TrimPrefix(goType, "*"), gormTag))
+		if err != nil {
+			continue
+		}
+		field.GoName = astField.Names[0].Name
+		opts := fieldOptions{Optional: strings.HasPrefix(goType, "*")}
+		if columnType := gormType(gormTag); columnType != gormType(field.GormTag) {
+			opts.ColumnType = columnType
+		}
+		fields = append(fields, field.withOptions(opts))
+	}
+	for i, f := range fields {
+		if relation, ok := belongsTo[f.GoName]; ok {
+			fields[i] = relation.withOptions(f.options()).withModelID(idOfSpecType(f.Type))
+		}
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("model " + modelName + " has no fields")
+	}
+	return fields, nil
+}
+
+// parseRelationKeys returns the keys of the association f of moduleName set
+// in its gorm tag that differ from the generated ones; see relationKeys.
+func parseRelationKeys(moduleName string, f Field, gormTag string) relationKeys {
+	settings := map[string]string{}
+	for _, part := range strings.Split(gormTag, ";") {
+		if key, value, ok := strings.Cut(part, ":"); ok {
+			settings[key] = value
+		}
+	}
+	var keys relationKeys
+	if f.Relation == "has_many" {
+		if key := settings["foreignKey"]; key != toPascal(moduleName)+"ID" {
+			keys.ForeignKey = key
+		}
+		return keys
+	}
+	if table := settings["many2many"]; table != moduleName+"_"+f.Name {
+		keys.JoinTable = table
+	}
+	keys.JoinForeignKey, keys.JoinReferences = settings["joinForeignKey"], settings["joinReferences"]
+	return keys
+}
+
+// detectFilters returns the query parameters of the List request in the
+// module's requests file; see templateData.Filters.
+func (g *Generator) detectFilters(moduleName string) []string {
+	requestPath := filepath.Join(WORKDIR+"requests", moduleName+"_request.go")
+	src, err := g.readFile(requestPath)
+	if err != nil {
+		return nil
+	}
+	node, err := parser.ParseFile(token.NewFileSet(), requestPath, src, 0)
+	if err != nil {
+		return nil
+	}
+	var filters []string
+	ast.Inspect(node, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != "List"+toPascal(moduleName)+"Request" {
+			return true
+		}
+		if structType, ok := spec.Type.(*ast.StructType); ok {
+			for _, astField := range structType.Fields.List {
+				if astField.Tag == nil {
+					continue
+				}
+				tag := reflect.StructTag(strings.Trim(astField.Tag.Value, "`"))
+				if name := tag.Get("query"); name != "" {
+					filters = append(filters, name)
+				}
+			}
+		}
+		return false
+	})
+	return filters
+}
+
+// specTypeFor maps a model field's Go type and gorm tag back to a spec type.
+func specTypeFor(goType, gormTag string) string {
+	switch goType {
+	case "string":
+		switch {
+		case strings.Contains(gormTag, "type:text"), strings.Contains(gormTag, "type:json"):
+			return "text"
+		case strings.Contains(gormTag, "type:uuid"):
+			return "uuid"
+		case strings.Contains(gormTag, "type:char(26)"):
+			return "ulid"
+		}
+		return "string"
+	case "float64", "float32":
+		if columnType := gormType(gormTag); strings.HasPrefix(columnType, "decimal") || strings.HasPrefix(columnType, "numeric") {
+			return "decimal"
+		}
+		return "float"
+	case "time.Time":
+		return "time"
+	}
+	return goType
+}
+
+// exprString renders simple type expressions such as "int", "time.Time" or
+// "[]Tag".
+func exprString(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return exprString(e.X) + "." + e.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(e.X)
+	case *ast.ArrayType:
+		if e.Len == nil {
+			return "[]" + exprString(e.Elt)
+		}
+	}
+	return ""
+}
+
+// resolveModuleFields returns the fields of an existing module model, or the
+// default fields when the model cannot be read.
+func (g *Generator) resolveModuleFields(moduleName string) []Field {
+	fields, err := g.detectModelFields(moduleName)
+	if err != nil {
+		return defaultFields()
+	}
+	id, _ := g.detectModelID(moduleName)
+	return g.withRelationIDs(moduleName, id, fields, nil)
+}
@@ -0,0 +1,197 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields([]string{"name:string", "unit_price:money", "stock:int", "customer_id:uint", "released_at:datetime"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		goName, goType, validate string
+	}{
+		{"Name", "string", "required,min=1,max=255"},
+		{"UnitPrice", "float64", "gte=0"},
+		{"Stock", "int", ""},
+		{"CustomerID", "uint", ""},
+		{"ReleasedAt", "time.Time", "required"},
+	}
+	if len(fields) != len(want) {
+		t.Fatalf("ParseFields returned %d fields, want %d", len(fields), len(want))
+	}
+	for i, w := range want {
+		f := fields[i]
+		if f.GoName != w.goName || f.GoType != w.goType || f.Validate != w.validate {
+			t.Errorf("field %d = {%s %s %q}, want {%s %s %q}", i, f.GoName, f.GoType, f.Validate, w.goName, w.goType, w.validate)
+		}
+	}
+}
+
+func TestParseFields_Relations(t *testing.T) {
+	fields, err := ParseFields([]string{"customer:belongs_to", "parent_id:belongs_to:category", "items:has_many:order_item", "tags:many2many"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Field{
+		{Name: "customer_id", GoName: "CustomerID", GoType: "uint", Relation: "belongs_to", Model: "customer"},
+		{Name: "parent_id", GoName: "ParentID", GoType: "uint", Relation: "belongs_to", Model: "category"},
+		{Name: "items", GoName: "Items", GoType: "[]OrderItem", Relation: "has_many", Model: "order_item"},
+		{Name: "tags", GoName: "Tags", GoType: "[]Tag", Relation: "many2many", Model: "tag"},
+	}
+	for i, w := range want {
+		f := fields[i]
+		if f.Name != w.Name || f.GoName != w.GoName || f.GoType != w.GoType || f.Relation != w.Relation || f.Model != w.Model {
+			t.Errorf("field %d = {%s %s %s %s %s}, want {%s %s %s %s %s}", i,
+				f.Name, f.GoName, f.GoType, f.Relation, f.Model, w.Name, w.GoName, w.GoType, w.Relation, w.Model)
+		}
+	}
+	if got := fields[0].spec(); got != "customer:belongs_to:customer" {
+		t.Errorf("spec() = %q", got)
+	}
+	if got := fields[3].IDsName(); got != "tag_ids" {
+		t.Errorf("IDsName() = %q, want tag_ids", got)
+	}
+	if fields[2].IsColumn() || !fields[0].IsColumn() {
+		t.Error("IsColumn() is true for has_many or false for belongs_to")
+	}
+}
+
+func TestParseFields_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		specs []string
+		want  string
+	}{
+		{"missing type", []string{"name"}, "expected name:type"},
+		{"unknown type", []string{"name:blob"}, "unsupported type"},
+		{"not snake case", []string{"unit-price:decimal"}, "snake_case"},
+		{"reserved", []string{"id:uint"}, "generated automatically"},
+		{"duplicate", []string{"name:string", "name:text"}, "duplicate"},
+		{"module of a column", []string{"name:string:tag"}, "expected name:type or name:relation:module"},
+		{"relation module", []string{"items:has_many:order-item"}, "must be snake_case"},
+		{"duplicate foreign key", []string{"customer_id:uint", "customer:belongs_to"}, "duplicate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFields(tt.specs)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseFields(%v) error = %v, want containing %q", tt.specs, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestToGoName(t *testing.T) {
+	tests := map[string]string{
+		"name":        "Name",
+		"unit_price":  "UnitPrice",
+		"customer_id": "CustomerID",
+		"api_url":     "APIURL",
+	}
+	for input, want := range tests {
+		if got := toGoName(input); got != want {
+			t.Errorf("toGoName(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestGenerateModule_WithFields(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/proj\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := GenerateModule("product", "name:string", "price:decimal", "stock:int"); err != nil {
+		t.Fatalf("GenerateModule = %v", err)
+	}
+
+	wantContent := map[string][]string{
+		"internal/models/product.go": {
+			"Price     float64        `json:\"price\" gorm:\"type:decimal(12,2);not null\"`",
+			"Stock     int            `json:\"stock\" gorm:\"not null\"`",
+		},
+		"internal/requests/product_request.go": {
+			"Price float64 `json:\"price\" validate:\"gte=0\"`",
+		},
+		"internal/services/product_service.go": {
+			"Stock: req.Stock,",
+			"entity.Price = req.Price",
+		},
+		"tests/fixtures/product_fixture.go": {
+			"Price: 9.99,",
+		},
+	}
+	for rel, snippets := range wantContent {
+		content, err := os.ReadFile(filepath.Join(dir, rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, snippet := range snippets {
+			if !strings.Contains(string(content), snippet) {
+				t.Errorf("%s does not contain %q", rel, snippet)
+			}
+		}
+	}
+
+	// The auto-test path recovers the same fields from the model file.
+	fields, err := std.detectModelFields("product")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range fields {
+		names = append(names, f.Name+":"+f.Type)
+	}
+	if got := strings.Join(names, " "); got != "name:string price:decimal stock:int" {
+		t.Errorf("detectModelFields = %q", got)
+	}
+}
+
+func TestDetectModelFields_ColumnType(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	schema := &Schema{Modules: []SchemaModule{{Name: "rate", Fields: []SchemaField{
+		{Name: "amount", Type: "decimal"},
+		{Name: "rate", Type: "decimal", ColumnType: "numeric(18,4)"},
+		{Name: "terms", Type: "text", ColumnType: "jsonb"},
+		{Name: "note", Type: "text", Optional: true},
+	}}}}
+	if _, err := g.Apply(schema); err != nil {
+		t.Fatal(err)
+	}
+	fields, err := g.detectModelFields("rate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range fields {
+		got = append(got, f.Name+":"+f.Type+":"+f.ColumnType)
+		if f.Name == "note" && (!f.Optional || f.GoType != "*string") {
+			t.Errorf("detected note as %s, optional %v; want an optional *string", f.GoType, f.Optional)
+		}
+	}
+	if want := "amount:decimal: rate:decimal:numeric(18,4) terms:text:jsonb note:text:"; strings.Join(got, " ") != want {
+		t.Errorf("detectModelFields = %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestGenerateModule_InvalidField(t *testing.T) {
+	if err := GenerateModule("product", "price"); err == nil {
+		t.Fatal("GenerateModule with an invalid field spec expected an error, got nil")
+	}
+}
@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// formatGo gofmts generated Go source and groups its imports goimports-style:
+// standard library, third-party packages, then packages of the project
+// localPrefix (the module path; may be empty).
+func formatGo(src []byte, localPrefix string) ([]byte, error) {
+	out, err := format.Source(src)
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(groupImports(out, localPrefix))
+}
+
+// groupImports rewrites every parenthesized import declaration of gofmt-clean
+// src into its groups, separated by blank lines. Comments stay with the import
+// they precede or follow on the same line.
+func groupImports(src []byte, localPrefix string) []byte {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return src
+	}
+
+	var edits []edit
+	for _, decl := range file.Decls {
+		lparen, rparen := importParens(fset, decl)
+		if lparen < 0 {
+			continue
+		}
+		var groups [3][]string
+		var pending []string // comment lines waiting for their import
+		for _, line := range strings.Split(string(src[lparen+1:rparen]), "\n") {
+			trimmed := strings.TrimSpace(line)
+			switch {
+			case trimmed == "":
+			case strings.HasPrefix(trimmed, "//"):
+				pending = append(pending, line)
+			default:
+				group := importGroup(trimmed, localPrefix)
+				groups[group] = append(groups[group], append(pending, line)...)
+				pending = nil
+			}
+		}
+
+		var block []string
+		for _, group := range groups {
+			if len(group) == 0 {
+				continue
+			}
+			if len(block) > 0 {
+				block = append(block, "")
+			}
+			block = append(block, group...)
+		}
+		block = append(block, pending...)
+		edits = append(edits, edit{offset: lparen + 1, text: "\n" + strings.Join(block, "\n") + "\n", remove: rparen - lparen - 1})
+	}
+
+	out := append([]byte(nil), src...)
+	for i := len(edits) - 1; i >= 0; i-- {
+		e := edits[i]
+		out = append(out[:e.offset], append([]byte(e.text), out[e.offset+e.remove:]...)...)
+	}
+	return out
+}
+
+// importParens returns the byte offsets of the parentheses of an import
+// declaration, or -1 when decl is not a parenthesized import.
+func importParens(fset *token.FileSet, decl ast.Decl) (int, int) {
+	gen, ok := decl.(*ast.GenDecl)
+	if !ok || gen.Tok != token.IMPORT || !gen.Lparen.IsValid() {
+		return -1, -1
+	}
+	return fset.Position(gen.Lparen).Offset, fset.Position(gen.Rparen).Offset
+}
+
+// importGroup classifies an import spec line: 0 for the standard library, 1
+// for third-party packages and 2 for packages below localPrefix.
+func importGroup(line, localPrefix string) int {
+	start := strings.IndexAny(line, "\"`")
+	if start < 0 {
+		return 1
+	}
+	end := strings.IndexAny(line[start+1:], "\"`")
+	if end < 0 {
+		return 1
+	}
+	path, err := strconv.Unquote(line[start : start+end+2])
+	if err != nil {
+		return 1
+	}
+	switch {
+	case localPrefix != "" && (path == localPrefix || strings.HasPrefix(path, localPrefix+"/")):
+		return 2
+	case !strings.Contains(strings.SplitN(path, "/", 2)[0], "."):
+		return 0
+	default:
+		return 1
+	}
+}
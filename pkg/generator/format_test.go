@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormatGo(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		local string
+		want  string
+	}{
+		{
+			name:  "groups standard, third-party and project imports",
+			src:   "package a\nimport (\n\"example.com/app/config\"\n\"fmt\"\n\"gorm.io/gorm\"\n\t\"log\"\n)\nvar _ = fmt.Sprint\n",
+			local: "example.com/app",
+			want:  "package a\n\nimport (\n\t\"fmt\"\n\t\"log\"\n\n\t\"gorm.io/gorm\"\n\n\t\"example.com/app/config\"\n)\n\nvar _ = fmt.Sprint\n",
+		},
+		{
+			name:  "project without a dot in its module path",
+			src:   "package a\n\nimport (\n\t\"my-api/config\"\n\t\"time\"\n)\n",
+			local: "my-api",
+			want:  "package a\n\nimport (\n\t\"time\"\n\n\t\"my-api/config\"\n)\n",
+		},
+		{
+			name: "keeps comments and names with their import",
+			src:  "package a\n\nimport (\n\t// logging\n\tzap \"go.uber.org/zap\" // structured\n\t\"os\"\n)\n",
+			want: "package a\n\nimport (\n\t\"os\"\n\n\t// logging\n\tzap \"go.uber.org/zap\" // structured\n)\n",
+		},
+		{
+			name: "aligns trailing comments",
+			src:  "package a\n\nfunc f() {\n\tg(1) // one\n\tg(100) // hundred\n}\n",
+			want: "package a\n\nfunc f() {\n\tg(1)   // one\n\tg(100) // hundred\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatGo([]byte(tt.src), tt.local)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("formatGo() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderFile_InvalidGo(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "model.tmpl"), []byte("package models\n\ntype {{.ModelName}} struct {\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g := New(t.TempDir(), Options{TemplateDir: dir})
+
+	err := g.CreateModels("product")
+	if err == nil || !strings.Contains(err.Error(), "template templates/model.tmpl produced invalid Go") {
+		t.Fatalf("CreateModels() = %v, want an error naming model.tmpl", err)
+	}
+	if g.fileExists("internal/models/product.go") {
+		t.Error("invalid Go was written")
+	}
+}
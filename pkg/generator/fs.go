@@ -0,0 +1,278 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FS is the file system a Generator reads project files from and writes
+// generated files to. Names are slash-separated and relative to the project
+// root, as in io/fs.
+type FS interface {
+	// ReadFile returns an error wrapping fs.ErrNotExist for missing files.
+	ReadFile(name string) ([]byte, error)
+	Stat(name string) (fs.FileInfo, error)
+	// WriteFile creates or truncates name, creating parent directories.
+	WriteFile(name string, data []byte) error
+	MkdirAll(name string) error
+	// Remove deletes a file or an empty directory.
+	Remove(name string) error
+	// ReadDir returns the entries of a directory sorted by name.
+	ReadDir(name string) ([]fs.DirEntry, error)
+}
+
+// DirFS returns an FS for the directory root on disk; an empty root is the
+// current directory.
+func DirFS(root string) FS {
+	return dirFS(root)
+}
+
+type dirFS string
+
+func (d dirFS) path(name string) string {
+	return filepath.Join(string(d), filepath.FromSlash(name))
+}
+
+func (d dirFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(d.path(name))
+}
+
+func (d dirFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(d.path(name))
+}
+
+func (d dirFS) WriteFile(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(d.path(name)), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(d.path(name), data, 0644)
+}
+
+func (d dirFS) MkdirAll(name string) error {
+	return os.MkdirAll(d.path(name), os.ModePerm)
+}
+
+func (d dirFS) Remove(name string) error {
+	return os.Remove(d.path(name))
+}
+
+func (d dirFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(d.path(name))
+}
+
+// MemFS is an in-memory FS, e.g. to render a project and serve it as a zip or
+// tar archive. It is safe for concurrent use.
+type MemFS struct {
+	mu    sync.Mutex
+	files map[string][]byte
+	dirs  map[string]bool
+}
+
+// NewMemFS returns an empty MemFS.
+func NewMemFS() *MemFS {
+	return &MemFS{files: map[string][]byte{}, dirs: map[string]bool{}}
+}
+
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[path.Clean(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = path.Clean(name)
+	if data, ok := m.files[name]; ok {
+		return memFileInfo{name: path.Base(name), size: int64(len(data))}, nil
+	}
+	if m.dirs[name] {
+		return memFileInfo{name: path.Base(name), dir: true}, nil
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+func (m *MemFS) WriteFile(name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = path.Clean(name)
+	m.addDirs(path.Dir(name))
+	m.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *MemFS) MkdirAll(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.addDirs(path.Clean(name))
+	return nil
+}
+
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = path.Clean(name)
+	if _, ok := m.files[name]; ok {
+		delete(m.files, name)
+		return nil
+	}
+	if !m.dirs[name] {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	for other := range m.files {
+		if strings.HasPrefix(other, name+"/") {
+			return &fs.PathError{Op: "remove", Path: name, Err: errors.New("directory not empty")}
+		}
+	}
+	for other := range m.dirs {
+		if strings.HasPrefix(other, name+"/") {
+			return &fs.PathError{Op: "remove", Path: name, Err: errors.New("directory not empty")}
+		}
+	}
+	delete(m.dirs, name)
+	return nil
+}
+
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = path.Clean(name)
+	if name != "." && !m.dirs[name] {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	var entries []fs.DirEntry
+	for dir := range m.dirs {
+		if path.Dir(dir) == name {
+			entries = append(entries, fs.FileInfoToDirEntry(memFileInfo{name: path.Base(dir), dir: true}))
+		}
+	}
+	for file, data := range m.files {
+		if path.Dir(file) == name {
+			entries = append(entries, fs.FileInfoToDirEntry(memFileInfo{name: path.Base(file), size: int64(len(data))}))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// addDirs records dir and its parents.
+func (m *MemFS) addDirs(dir string) {
+	for dir != "." && dir != "/" && !m.dirs[dir] {
+		m.dirs[dir] = true
+		dir = path.Dir(dir)
+	}
+}
+
+// Files returns the names of all files in lexical order.
+func (m *MemFS) Files() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.files))
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// entries returns all directories (with a trailing slash) and files in
+// lexical order, so parents come before their children.
+func (m *MemFS) entries() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.files)+len(m.dirs))
+	for dir := range m.dirs {
+		names = append(names, dir+"/")
+	}
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteZip writes all files and directories as a zip archive to w, with every
+// name prefixed by prefix (e.g. "hrms-service/"; may be empty).
+func (m *MemFS) WriteZip(w io.Writer, prefix string) error {
+	zw := zip.NewWriter(w)
+	modified := time.Now()
+	for _, name := range m.entries() {
+		header := &zip.FileHeader{Name: prefix + name, Method: zip.Deflate, Modified: modified}
+		if strings.HasSuffix(name, "/") {
+			header.Method = zip.Store
+			if _, err := zw.CreateHeader(header); err != nil {
+				return err
+			}
+			continue
+		}
+		header.SetMode(0644)
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		data, _ := m.ReadFile(name)
+		if _, err := fw.Write(data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// WriteTar writes all files and directories as a tar stream to w, with every
+// name prefixed by prefix. Wrap w in a gzip.Writer for a .tar.gz.
+func (m *MemFS) WriteTar(w io.Writer, prefix string) error {
+	tw := tar.NewWriter(w)
+	modified := time.Now()
+	for _, name := range m.entries() {
+		if strings.HasSuffix(name, "/") {
+			header := &tar.Header{Typeflag: tar.TypeDir, Name: prefix + name, Mode: 0755, ModTime: modified}
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			continue
+		}
+		data, _ := m.ReadFile(name)
+		header := &tar.Header{Typeflag: tar.TypeReg, Name: prefix + name, Mode: 0644, Size: int64(len(data)), ModTime: modified}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// memFileInfo is the fs.FileInfo of a MemFS entry.
+type memFileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (fi memFileInfo) Name() string       { return fi.name }
+func (fi memFileInfo) Size() int64        { return fi.size }
+func (fi memFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi memFileInfo) IsDir() bool        { return fi.dir }
+func (fi memFileInfo) Sys() any           { return nil }
+
+func (fi memFileInfo) Mode() fs.FileMode {
+	if fi.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
@@ -0,0 +1,169 @@
+package generator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMemFS_GenerateProject(t *testing.T) {
+	cwd, _ := os.Getwd()
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.GenerateModule("product", "name:string", "price:decimal", "stock:int", "released_at:time"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Same project as TestGolden, rendered without touching the disk.
+	for _, name := range mem.Files() {
+		got, _ := mem.ReadFile(name)
+		if name == ManifestFile {
+			continue
+		}
+		if name == "go.mod" {
+			if want := "module example.com/app\n\ngo " + defaultGoVersion + "\n"; string(got) != want {
+				t.Errorf("go.mod = %q, want %q", got, want)
+			}
+			continue
+		}
+		want, err := os.ReadFile(filepath.Join("testdata", "golden", filepath.FromSlash(name)+".golden"))
+		if err != nil {
+			t.Errorf("unexpected file %s", name)
+			continue
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s differs from its golden file", name)
+		}
+	}
+	if info, err := mem.Stat("internal"); err != nil || !info.IsDir() {
+		t.Errorf("Stat(internal) = %v, %v; want a directory", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(cwd, "main.go")); err == nil {
+		t.Error("generating into a MemFS wrote main.go to disk")
+	}
+}
+
+func TestMemFS_Archives(t *testing.T) {
+	mem := NewMemFS()
+	_ = mem.WriteFile("main.go", []byte("package main\n"))
+	_ = mem.WriteFile("internal/models/user.go", []byte("package models\n"))
+	_ = mem.MkdirAll("tests")
+
+	want := []string{
+		"app/internal/",
+		"app/internal/models/",
+		"app/internal/models/user.go",
+		"app/main.go",
+		"app/tests/",
+	}
+
+	var zipBuf bytes.Buffer
+	if err := mem.WriteZip(&zipBuf, "app/"); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(zipBuf.Bytes()), int64(zipBuf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var zipNames []string
+	for _, f := range zr.File {
+		zipNames = append(zipNames, f.Name)
+		if f.Name == "app/main.go" {
+			rc, _ := f.Open()
+			content, _ := io.ReadAll(rc)
+			rc.Close()
+			if string(content) != "package main\n" {
+				t.Errorf("zip main.go = %q", content)
+			}
+		}
+	}
+
+	var tarBuf bytes.Buffer
+	if err := mem.WriteTar(&tarBuf, "app/"); err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(&tarBuf)
+	var tarNames []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		tarNames = append(tarNames, header.Name)
+	}
+
+	for _, got := range [][]string{zipNames, tarNames} {
+		if len(got) != len(want) {
+			t.Fatalf("archive entries = %v, want %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("archive entry %d = %q, want %q", i, got[i], want[i])
+			}
+		}
+	}
+}
+
+// failingFS is a MemFS whose writes fail for the listed names.
+type failingFS struct {
+	*MemFS
+	fail map[string]bool
+}
+
+func (f failingFS) WriteFile(name string, data []byte) error {
+	if f.fail[name] {
+		return errors.New("disk full")
+	}
+	return f.MemFS.WriteFile(name, data)
+}
+
+func TestGenerateModule_RollsBackOnWriteErrors(t *testing.T) {
+	mem := NewMemFS()
+	_ = mem.WriteFile("go.mod", []byte("module example.com/app\n"))
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.CreateMainGo("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	mainBefore, _ := mem.ReadFile("main.go")
+	g.FS = failingFS{MemFS: mem, fail: map[string]bool{
+		"internal/models/product.go":                 true,
+		"internal/controllers/product_controller.go": true,
+	}}
+
+	err := g.GenerateModule("product")
+	if err == nil {
+		t.Fatal("GenerateModule succeeded although writes failed")
+	}
+	for _, path := range []string{"internal/models/product.go", "internal/controllers/product_controller.go"} {
+		if !strings.Contains(err.Error(), path) {
+			t.Errorf("error %q does not mention %s", err, path)
+		}
+	}
+	// The files that were written are rolled back and the module is not wired in.
+	for _, name := range mem.Files() {
+		if name != "go.mod" && name != "main.go" && name != ManifestFile {
+			t.Errorf("%s was left behind after a failed generation", name)
+		}
+	}
+	if _, err := mem.Stat("internal"); err == nil {
+		t.Error("directory internal was left behind after a failed generation")
+	}
+	if mainAfter, _ := mem.ReadFile("main.go"); !bytes.Equal(mainAfter, mainBefore) {
+		t.Error("main.go was patched for a module with missing files")
+	}
+}
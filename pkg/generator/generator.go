@@ -13,27 +13,111 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
-	"os"
+	"maps"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
-	"text/template"
-
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
 var (
 	WORKDIR = "internal/"
 )
 
+// Defaults for the package-level functions, which generate into the current
+// directory. See Options for their meaning.
+var (
+	TemplateDir      = ""
+	DryRun           = false
+	ShowDiff         = false
+	ConfirmOverwrite func(path string) bool
+	Backup           = false
+)
+
 //go:embed templates
 var templatesFS embed.FS
 
-// runCmd runs a command and returns an error on failure.
-func runCmd(name string, args ...string) error {
+// Options control how a Generator renders and writes files.
+type Options struct {
+	// TemplateDir is an optional directory whose templates shadow the
+	// built-in ones. Templates are looked up by file name, e.g.
+	// "controller.tmpl", in TemplateDir, then in .go-gen/templates of the
+	// project, then in ~/.go-gen/templates, and finally in the embedded set.
+	TemplateDir string
+
+	// DryRun reports what would be created, overwritten, skipped or patched
+	// without touching the disk. Files "written" during a dry run are kept in
+	// memory so later steps (e.g. registering a module in main.go created by
+	// the same run) see them. Use TakePlan to read and reset the plan.
+	DryRun bool
+
+	// ShowDiff prints a unified diff of every file that is about to be
+	// overwritten (e.g. by GenerateTestFilesForce).
+	ShowDiff bool
+
+	// ConfirmOverwrite, when set together with ShowDiff, is asked after the
+	// diff whether a changed file may be overwritten. A nil func overwrites.
+	ConfirmOverwrite func(path string) bool
+
+	// Backup keeps a copy of every overwritten file next to it as <file>.orig.
+	Backup bool
+}
+
+// Generator scaffolds a project rooted at Root. All paths it reads and writes
+// are relative to Root, so several generators can work on different projects
+// at the same time. A single Generator must not be used concurrently.
+type Generator struct {
+	Root string // project directory; empty means the current directory
+	FS   FS     // where files are read and written; defaults to DirFS(Root)
+	Options
+
+	plan    []PlannedChange
+	overlay map[string][]byte // files written during a dry run
+	staged  *stage            // writes of the running transaction
+	txDepth int               // nesting of running transactions
+
+	manifest        *Manifest // loaded by Manifest, reset after each transaction
+	manifestChanged bool
+
+	regenerate bool     // Apply: existing files are rendered again, not skipped
+	conflicts  []string // files regenerated with conflict markers
+}
+
+// New returns a Generator for the project in root.
+func New(root string, opts Options) *Generator {
+	return &Generator{Root: root, Options: opts}
+}
+
+// std backs the package-level functions.
+var std = &Generator{}
+
+// defaultGenerator returns std configured from the package-level variables.
+func defaultGenerator() *Generator {
+	std.Options = Options{
+		TemplateDir:      TemplateDir,
+		DryRun:           DryRun,
+		ShowDiff:         ShowDiff,
+		ConfirmOverwrite: ConfirmOverwrite,
+		Backup:           Backup,
+	}
+	return std
+}
+
+// runCmd runs a command in the project root and returns an error on failure.
+// During a dry run the command is only recorded; it is skipped when the
+// generator does not write to disk.
+func (g *Generator) runCmd(name string, args ...string) error {
+	if g.DryRun {
+		g.recordChange(ActionRun, strings.Join(append([]string{name}, args...), " "))
+		return nil
+	}
+	if !g.onDisk() {
+		return nil
+	}
 	cmd := exec.Command(name, args...)
+	cmd.Dir = g.Root
 	if errors.Is(cmd.Err, exec.ErrDot) {
 		cmd.Err = nil
 	}
@@ -43,19 +127,67 @@ func runCmd(name string, args ...string) error {
 	return nil
 }
 
+// databaseDriver describes a database that can be selected with
+// InitOptions.Database.
+type databaseDriver struct {
+	driverPackage string // GORM driver module fetched with go get
+	template      string
+	outputPath    string
+	connect       string // connection func in the generated database package
+}
+
+const defaultDatabase = "postgres"
+
+// defaultGoVersion is the go directive of go.mod files written without
+// running go mod init.
+const defaultGoVersion = "1.23"
+
+var databaseDrivers = map[string]databaseDriver{
+	"postgres": {
+		driverPackage: "gorm.io/driver/postgres",
+		template:      "templates/database_postgres.tmpl",
+		outputPath:    "database/postgres.go",
+		connect:       "PostgresConnection",
+	},
+	"mysql": {
+		driverPackage: "gorm.io/driver/mysql",
+		template:      "templates/database_mysql.tmpl",
+		outputPath:    "database/mysql.go",
+		connect:       "MysqlConnection",
+	},
+}
+
+// Databases returns the names accepted by InitOptions.Database.
+func Databases() []string {
+	names := make([]string, 0, len(databaseDrivers))
+	for name := range databaseDrivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// InitOptions configures InitWithOptions.
+type InitOptions struct {
+	ProjectName string // Go module name, e.g. "github.com/acme/hrms-service"
+	Database    string // one of Databases(); defaults to "postgres"
+	SkipExample bool   // do not generate the example module
+	NoDeps      bool   // skip go mod tidy and go get (go.mod is still created)
+}
+
 // initPackages runs go get for the default dependencies.
-func initPackages() error {
+func (g *Generator) initPackages(database string) error {
 	packages := []string{
 		"github.com/gofiber/fiber/v2",
 		"gorm.io/gorm",
 		"github.com/go-playground/validator/v10",
 		"go.uber.org/zap",
 		"github.com/spf13/viper",
-		"gorm.io/driver/postgres",
+		databaseDrivers[database].driverPackage,
 		"github.com/stretchr/testify",
 	}
 	for _, pkg := range packages {
-		if err := runCmd("go", "get", pkg); err != nil {
+		if err := g.runCmd("go", "get", pkg); err != nil {
 			return err
 		}
 	}
@@ -66,1506 +198,351 @@ func initPackages() error {
 // It runs go mod init, go mod tidy, installs dependencies, and generates the
 // full project structure including an example module. Call this from the
 // directory that will become the project root.
-func Init(projectName string) error {
-	projectName = strings.TrimSpace(projectName)
+func (g *Generator) Init(projectName string) error {
+	return g.InitWithOptions(InitOptions{ProjectName: projectName})
+}
+
+// InitWithOptions is Init with a choice of database, without the example
+// module or without fetching dependencies.
+func (g *Generator) InitWithOptions(opts InitOptions) error {
+	projectName := strings.TrimSpace(opts.ProjectName)
 	if projectName == "" {
 		return errors.New("project name must not be empty")
 	}
 	if strings.Contains(projectName, " ") {
 		return errors.New("project name must not contain spaces")
 	}
-	if err := runCmd("go", "mod", "init", projectName); err != nil {
-		return err
+	opts.ProjectName = projectName
+	if opts.Database == "" {
+		opts.Database = defaultDatabase
 	}
-	if err := runCmd("go", "mod", "tidy"); err != nil {
-		return err
+	if _, ok := databaseDrivers[opts.Database]; !ok {
+		return fmt.Errorf("unsupported database %q (use one of: %s)", opts.Database, strings.Join(Databases(), ", "))
 	}
-	if err := initPackages(); err != nil {
-		return err
+	// go mod init is only recorded during a dry run and cannot run outside a
+	// directory on disk; write the go.mod it would create instead.
+	if g.DryRun || !g.onDisk() {
+		if g.fileExists("go.mod") {
+			return errors.New("go.mod already exists")
+		}
+		goMod := fmt.Sprintf("module %s\n\ngo %s\n", projectName, defaultGoVersion)
+		if err := g.writeFile("go.mod", []byte(goMod), ActionCreate); err != nil {
+			return err
+		}
 	}
-	return GenerateInitialStructure(projectName)
-}
-
-// GenerateInitialStructure creates the full project layout (config, database,
-// routes, middleware, etc.) and an example module. projectName is the Go
-// module name (e.g. from go mod init).
-func GenerateInitialStructure(projectName string) error {
-	CreateConfigEnv(projectName)
-	CreateConfigTimezonse(projectName)
-	CreateDatabaseConnection(projectName)
-	CreateLoggers(projectName)
-	CreatePagination(projectName)
-	CreateAppErrs()
-	CreateRoutes()
-	CreateFiberRoutes(projectName)
-	CreateHandleResponse(projectName)
-	CreateValidation()
-	CreateMainGo(projectName)
-	CreateSrcDir()
-	CreateExampleConfig(projectName)
-	if err := GenerateModule("example"); err != nil {
+	if err := g.runCmd("go", "mod", "init", projectName); err != nil {
 		return err
 	}
-	CreateMiddleware(projectName)
-	return nil
-}
-
-func CreateMainGo(projectName string) {
-	file := "main.go"
-	if _, err := os.Stat(file); os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
+	if !opts.NoDeps {
+		if err := g.runCmd("go", "mod", "tidy"); err != nil {
+			return err
 		}
-		defer destination.Close()
-
-		fmt.Fprintf(destination, "package main\n\n")
-
-		fmt.Fprintf(destination, "import (\n")
-		fmt.Fprintf(destination, "\t\"context\"\n")
-		fmt.Fprintf(destination, "\t\"encoding/json\"\n")
-		fmt.Fprintf(destination, "\t\"fmt\"\n")
-		fmt.Fprintf(destination, "\t\"log\"\n")
-		fmt.Fprintf(destination, "\t\"net/http\"\n\n")
-		fmt.Fprintf(destination, "\t\"github.com/gofiber/fiber/v2\"\n")
-		fmt.Fprintf(destination, "\t\"github.com/gofiber/fiber/v2/middleware/cors\"\n")
-		fmt.Fprintf(destination, "\t\"github.com/gofiber/fiber/v2/middleware/logger\"\n")
-		fmt.Fprintf(destination, "\t\"github.com/gofiber/fiber/v2/middleware/recover\"\n")
-		fmt.Fprintf(destination, "\t\"github.com/gofiber/fiber/v2/middleware/requestid\"\n\n")
-		fmt.Fprintf(destination, "\t\"%s/config\"\n", projectName)
-		fmt.Fprintf(destination, "\t\"%s/database\"\n", projectName)
-		fmt.Fprintf(destination, "\t\"%s/internal/controllers\"\n", projectName)
-		fmt.Fprintf(destination, "\t\"%s/internal/repositories\"\n", projectName)
-		fmt.Fprintf(destination, "\t\"%s/internal/services\"\n", projectName)
-		fmt.Fprintf(destination, "\t\"%s/migrations\"\n", projectName)
-		fmt.Fprintf(destination, "\t\"%s/routes\"\n", projectName)
-		fmt.Fprintf(destination, ")\n\n")
-
-		fmt.Fprintf(destination, "func main() {\n")
-		fmt.Fprintf(destination, "\t// Connect to database\n")
-		fmt.Fprintf(destination, "\tdb, err := database.PostgresConnection()\n")
-		fmt.Fprintf(destination, "\tif err != nil {\n")
-		fmt.Fprintf(destination, "\t\tlog.Fatal(err)\n")
-		fmt.Fprintf(destination, "\t}\n\n")
-
-		fmt.Fprintf(destination, "\t// Run migrations\n")
-		fmt.Fprintf(destination, "\tif err := migrations.MigrateAll(db); err != nil {\n")
-		fmt.Fprintf(destination, "\t\tlog.Fatalf(\"Migration failed: %%v\", err)\n")
-		fmt.Fprintf(destination, "\t}\n\n")
-
-		fmt.Fprintf(destination, "\t// Initialize example module\n")
-		fmt.Fprintf(destination, "\texampleRepo := repositories.NewExampleRepository(db)\n")
-		fmt.Fprintf(destination, "\texampleService := services.NewExampleService(exampleRepo)\n")
-		fmt.Fprintf(destination, "\texampleController := controllers.NewExampleController(exampleService)\n\n")
-
-		fmt.Fprintf(destination, "\t// TODO: Add more modules here...\n\n")
-
-		fmt.Fprintf(destination, "\t// Create Fiber app with custom error handler\n")
-		fmt.Fprintf(destination, "\tapp := fiber.New(fiber.Config{\n")
-		fmt.Fprintf(destination, "\t\tJSONEncoder: json.Marshal,\n")
-		fmt.Fprintf(destination, "\t\tJSONDecoder: json.Unmarshal,\n")
-		fmt.Fprintf(destination, "\t\tErrorHandler: func(ctx *fiber.Ctx, err error) error {\n")
-		fmt.Fprintf(destination, "\t\t\tcode := http.StatusInternalServerError\n")
-		fmt.Fprintf(destination, "\t\t\tif e, ok := err.(*fiber.Error); ok {\n")
-		fmt.Fprintf(destination, "\t\t\t\tcode = e.Code\n")
-		fmt.Fprintf(destination, "\t\t\t}\n")
-		fmt.Fprintf(destination, "\t\t\treturn ctx.Status(code).JSON(fiber.Map{\n")
-		fmt.Fprintf(destination, "\t\t\t\t\"type\":   \"about:blank\",\n")
-		fmt.Fprintf(destination, "\t\t\t\t\"title\":  http.StatusText(code),\n")
-		fmt.Fprintf(destination, "\t\t\t\t\"status\": code,\n")
-		fmt.Fprintf(destination, "\t\t\t\t\"detail\": err.Error(),\n")
-		fmt.Fprintf(destination, "\t\t\t})\n")
-		fmt.Fprintf(destination, "\t\t},\n")
-		fmt.Fprintf(destination, "\t})\n\n")
-
-		fmt.Fprintf(destination, "\t// Middleware - ECS-formatted JSON logs for Elastic/Kibana\n")
-		fmt.Fprintf(destination, "\tapp.Use(recover.New())\n")
-		fmt.Fprintf(destination, "\tapp.Use(requestid.New())\n")
-		fmt.Fprintf(destination, "\tapp.Use(func(c *fiber.Ctx) error {\n")
-		fmt.Fprintf(destination, "\t\tif id := c.Locals(\"requestid\"); id != nil {\n")
-		fmt.Fprintf(destination, "\t\t\tc.SetUserContext(context.WithValue(c.Context(), \"requestid\", id))\n")
-		fmt.Fprintf(destination, "\t\t}\n")
-		fmt.Fprintf(destination, "\t\treturn c.Next()\n")
-		fmt.Fprintf(destination, "\t})\n")
-		fmt.Fprintf(destination, "\tserviceName := config.GetEnv(\"app.name\", \"%s\")\n", projectName)
-		fmt.Fprintf(destination, "\tapp.Use(logger.New(logger.Config{\n")
-		fmt.Fprintf(destination, "\t\tFormat:     \"{\\\"@timestamp\\\":\\\"${time}\\\",\\\"log.level\\\":\\\"info\\\",\\\"message\\\":\\\"http request\\\",\\\"http.request.method\\\":\\\"${method}\\\",\\\"http.response.status_code\\\":${status},\\\"event.duration\\\":\\\"${latency}\\\",\\\"client.ip\\\":\\\"${ip}\\\",\\\"url.path\\\":\\\"${path}\\\",\\\"trace.id\\\":\\\"${locals:requestid}\\\",\\\"service.name\\\":\\\"\" + serviceName + \"\\\"}\",\n")
-		fmt.Fprintf(destination, "\t\tTimeFormat: \"2006-01-02T15:04:05.000Z07:00\",\n")
-		fmt.Fprintf(destination, "\t\tTimeZone:   \"UTC\",\n")
-		fmt.Fprintf(destination, "\t}))\n")
-		fmt.Fprintf(destination, "\tapp.Use(cors.New(cors.Config{\n")
-		fmt.Fprintf(destination, "\t\tAllowOrigins: \"*\",\n")
-		fmt.Fprintf(destination, "\t\tAllowMethods: \"GET,POST,PUT,DELETE,PATCH,OPTIONS\",\n")
-		fmt.Fprintf(destination, "\t\tAllowHeaders: \"Origin,Content-Type,Accept,Authorization\",\n")
-		fmt.Fprintf(destination, "\t}))\n\n")
-
-		fmt.Fprintf(destination, "\t// Register routes\n")
-		fmt.Fprintf(destination, "\troutes.NewFiberRoutes(exampleController).Install(app)\n\n")
-
-		fmt.Fprintf(destination, "\t// Start server\n")
-		fmt.Fprintf(destination, "\tport := config.Env(\"app.port\")\n")
-		fmt.Fprintf(destination, "\tif port == \"\" {\n")
-		fmt.Fprintf(destination, "\t\tport = \"8080\"\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\tlog.Printf(\"Server starting on port %%s\", port)\n")
-		fmt.Fprintf(destination, "\tlog.Fatal(app.Listen(fmt.Sprintf(\":%%s\", port)))\n")
-		fmt.Fprintf(destination, "}\n")
-
-		fmt.Println("Created main.go successfully:", file)
-	} else {
-		fmt.Println("File already exists:", file)
-	}
-}
-
-func CreateSrcDir() {
-	pathFolder := "internal"
-	if _, err := os.Stat(pathFolder); os.IsNotExist(err) {
-		err := os.Mkdir(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-			return
+		if err := g.initPackages(opts.Database); err != nil {
+			return err
 		}
 	}
+	return g.generateInitialStructure(opts)
 }
 
-func CreateValidation() {
-	pathFolder := "validation"
-	if _, err := os.Stat(pathFolder); os.IsNotExist(err) {
-		err := os.Mkdir(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-	}
-
-	path := pathFolder + "/"
-	file := path + "fiber.go"
-	var _, err = os.Stat(file)
-
-	if os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer destination.Close()
-
-		fmt.Fprintf(destination, "package validation\n\n")
-		fmt.Fprintf(destination, "import \"github.com/go-playground/validator/v10\"\n\n")
-		fmt.Fprintf(destination, "type ErrorResponse struct {\n")
-		fmt.Fprintf(destination, "	FailedField string `json:\"failed_field\"`\n")
-		fmt.Fprintf(destination, "	Tag         string `json:\"tag\"`\n")
-		fmt.Fprintf(destination, "	Value       string `json:\"value\"`\n")
-		fmt.Fprintf(destination, "}\n\n")
-		fmt.Fprintf(destination, "func ValidateStruct(myStruct interface{}) (string, error) {\n")
-		fmt.Fprintf(destination, "	var errorX []*ErrorResponse\n")
-		fmt.Fprintf(destination, "	validate := validator.New()\n")
-		fmt.Fprintf(destination, "	err := validate.Struct(myStruct)\n")
-		fmt.Fprintf(destination, "	if err != nil {\n")
-		fmt.Fprintf(destination, "		for _, err := range err.(validator.ValidationErrors) {\n")
-		fmt.Fprintf(destination, "			var element ErrorResponse\n")
-		fmt.Fprintf(destination, "			element.FailedField = err.Field() + \" \" + err.Tag() + \" \" + err.Param()\n")
-		fmt.Fprintf(destination, "			element.Tag = err.Tag()\n")
-		fmt.Fprintf(destination, "			element.Value = err.Param()\n")
-		fmt.Fprintf(destination, "			errorX = append(errorX, &element)\n")
-		fmt.Fprintf(destination, "		}\n")
-		fmt.Fprintf(destination, "	}\n")
-		fmt.Fprintf(destination, "	if errorX != nil {\n")
-		fmt.Fprintf(destination, "		return errorX[0].FailedField, err\n")
-		fmt.Fprintf(destination, "	}\n")
-		fmt.Fprintf(destination, "	return \"\", nil\n")
-		fmt.Fprintf(destination, "}\n")
-
-		fmt.Println("Created Validation successfully:", file)
+// GenerateInitialStructure creates the full project layout (config, database,
+// routes, middleware, etc.) and an example module. projectName is the Go
+// module name (e.g. from go mod init). Like GenerateModule it writes nothing
+// unless every file renders and returns all failures joined.
+func (g *Generator) GenerateInitialStructure(projectName string) error {
+	return g.generateInitialStructure(InitOptions{ProjectName: projectName, Database: defaultDatabase})
+}
+
+func (g *Generator) generateInitialStructure(opts InitOptions) error {
+	return g.transaction(func() error {
+		return g.createInitialFiles(opts)
+	})
+}
+
+// createInitialFiles creates the files of generateInitialStructure.
+func (g *Generator) createInitialFiles(opts InitOptions) error {
+	projectName := opts.ProjectName
+	data := newProjectData(projectName)
+	data.Database = opts.Database
+	data.WithExample = !opts.SkipExample
+
+	errs := []error{
+		g.CreateConfigEnv(projectName),
+		g.CreateConfigTimezonse(projectName),
+		g.createDatabaseConnection(data),
+		g.CreateLoggers(projectName),
+		g.CreatePagination(projectName),
+		g.CreateAppErrs(),
+		g.CreateRoutes(),
+		g.createFiberRoutes(data),
+		g.CreateHandleResponse(projectName),
+		g.CreateValidation(),
+		g.createMainGo(data),
+		g.CreateSrcDir(),
+		g.createExampleConfig(data),
+	}
+	if opts.SkipExample {
+		errs = append(errs, g.CreateEmptyMigrations())
 	} else {
-		fmt.Println("File already exists!", file)
+		errs = append(errs, g.GenerateModule("example"))
 	}
+	errs = append(errs, g.CreateMiddleware(projectName))
+	return errors.Join(errs...)
 }
 
-func CreateDatabaseConnection(projectName string) {
-	pathFolder := "database"
-	if _, err := os.Stat(pathFolder); os.IsNotExist(err) {
-		err := os.Mkdir(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-	}
-
-	path := pathFolder + "/"
-	file := path + "postgres.go"
-	var _, err = os.Stat(file)
-
-	if os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer destination.Close()
-
-		fmt.Fprintf(destination, "package database\n\n")
-
-		fmt.Fprintf(destination, "import (\n")
-		fmt.Fprintf(destination, "\t\"fmt\"\n")
-		fmt.Fprintf(destination, "\t\"log\"\n")
-		fmt.Fprintf(destination, "\t\"%s/config\"\n", projectName)
-		fmt.Fprintf(destination, "\t\"time\"\n\n")
-		fmt.Fprintf(destination, "\t\"gorm.io/driver/postgres\"\n")
-		fmt.Fprintf(destination, "\t\"gorm.io/gorm\"\n")
-		fmt.Fprintf(destination, "\t\"gorm.io/gorm/logger\"\n")
-		fmt.Fprintf(destination, ")\n\n")
-
-		fmt.Fprintf(destination, "type SqlLogger struct {\n")
-		fmt.Fprintf(destination, "\tlogger.Interface\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "var openConnectionDB *gorm.DB\n")
-		fmt.Fprintf(destination, "var err error\n\n")
-
-		fmt.Fprintf(destination, "func PostgresConnection() (*gorm.DB, error) {\n")
-		fmt.Fprintf(destination, "\tmyDSN := fmt.Sprintf(\"host=%%v user=%%v password=%%v dbname=%%v port=%%v sslmode=disable TimeZone=Asia/Bangkok\",\n")
-		fmt.Fprintf(destination, "\t\tconfig.Env(\"postgres.host\"),\n")
-		fmt.Fprintf(destination, "\t\tconfig.Env(\"postgres.user\"),\n")
-		fmt.Fprintf(destination, "\t\tconfig.Env(\"postgres.password\"),\n")
-		fmt.Fprintf(destination, "\t\tconfig.Env(\"postgres.database\"),\n")
-		fmt.Fprintf(destination, "\t\tconfig.Env(\"postgres.port\"),\n")
-		fmt.Fprintf(destination, "\t)\n\n")
-
-		fmt.Fprintf(destination, "\tfmt.Println(\"CONNECTING_TO_POSTGRES_DB\")\n")
-		fmt.Fprintf(destination, "\topenConnectionDB, err = gorm.Open(postgres.Open(myDSN), &gorm.Config{\n")
-		fmt.Fprintf(destination, "\t\tNowFunc: func() time.Time {\n")
-		fmt.Fprintf(destination, "\t\t\tti, _ := time.LoadLocation(\"Asia/Bangkok\")\n")
-		fmt.Fprintf(destination, "\t\t\treturn time.Now().In(ti)\n")
-		fmt.Fprintf(destination, "\t\t},\n")
-		fmt.Fprintf(destination, "\t})\n")
-		fmt.Fprintf(destination, "\tif err != nil {\n")
-		fmt.Fprintf(destination, "\t\tlog.Fatal(\"ERROR_PING_POSTGRES\", err)\n")
-		fmt.Fprintf(destination, "\t\treturn nil, err\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\tfmt.Println(\"POSTGRES_CONNECTED\")\n")
-		fmt.Fprintf(destination, "\treturn openConnectionDB, nil\n")
-		fmt.Fprintf(destination, "}\n")
-
-		fmt.Println("Created Database Connection successfully:", file)
-	} else {
-		fmt.Println("File already exists!", file)
-	}
+func (g *Generator) CreateMainGo(projectName string) error {
+	return g.createMainGo(newProjectData(projectName))
 }
 
-func CreateHandleResponse(projectName string) {
-	pathFolder := "responses"
-	if _, err := os.Stat(pathFolder); os.IsNotExist(err) {
-		err := os.Mkdir(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-	}
-
-	path := pathFolder + "/"
-	file := path + "handle_responses.go"
-	if _, err := os.Stat(file); os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer destination.Close()
-
-		// Write response handling code - team standard format
-		fmt.Fprintf(destination, "package responses\n\n")
-		fmt.Fprintf(destination, "import (\n")
-		fmt.Fprintf(destination, "	\"net/http\"\n\n")
-		fmt.Fprintf(destination, "	\"%s/errs\"\n", projectName)
-		fmt.Fprintf(destination, "	\"github.com/gofiber/fiber/v2\"\n")
-		fmt.Fprintf(destination, ")\n\n")
-
-		fmt.Fprintf(destination, "// ValidationError represents a single validation error (field + message).\n")
-		fmt.Fprintf(destination, "type ValidationError struct {\n")
-		fmt.Fprintf(destination, "	Field   string `json:\"field\"`\n")
-		fmt.Fprintf(destination, "	Message string `json:\"message\"`\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "// APIResponse is the standard response structure for success and error.\n")
-		fmt.Fprintf(destination, "// Success: { success: true, message, data, errors: null }\n")
-		fmt.Fprintf(destination, "// Error:   { success: false, message, data: null, errors: [...] or null }\n")
-		fmt.Fprintf(destination, "type APIResponse struct {\n")
-		fmt.Fprintf(destination, "	Success bool        `json:\"success\"`\n")
-		fmt.Fprintf(destination, "	Message string      `json:\"message\"`\n")
-		fmt.Fprintf(destination, "	Data    interface{} `json:\"data\"`\n")
-		fmt.Fprintf(destination, "	Errors  interface{} `json:\"errors\"`\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "// NewSuccessResponse sends a 200 OK - success: true, data, errors: null.\n")
-		fmt.Fprintf(destination, "func NewSuccessResponse(ctx *fiber.Ctx, message string, data interface{}) error {\n")
-		fmt.Fprintf(destination, "	return ctx.Status(http.StatusOK).JSON(APIResponse{\n")
-		fmt.Fprintf(destination, "		Success: true,\n")
-		fmt.Fprintf(destination, "		Message: message,\n")
-		fmt.Fprintf(destination, "		Data:    data,\n")
-		fmt.Fprintf(destination, "		Errors:  nil,\n")
-		fmt.Fprintf(destination, "	})\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "// NewCreatedResponse sends a 201 Created - success: true, data, errors: null.\n")
-		fmt.Fprintf(destination, "func NewCreatedResponse(ctx *fiber.Ctx, message string, data interface{}) error {\n")
-		fmt.Fprintf(destination, "	return ctx.Status(http.StatusCreated).JSON(APIResponse{\n")
-		fmt.Fprintf(destination, "		Success: true,\n")
-		fmt.Fprintf(destination, "		Message: message,\n")
-		fmt.Fprintf(destination, "		Data:    data,\n")
-		fmt.Fprintf(destination, "		Errors:  nil,\n")
-		fmt.Fprintf(destination, "	})\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "// NewValidationError sends 422 - success: false, data: null, errors: [{field, message}].\n")
-		fmt.Fprintf(destination, "func NewValidationError(ctx *fiber.Ctx, validationErrors []ValidationError) error {\n")
-		fmt.Fprintf(destination, "	return ctx.Status(http.StatusUnprocessableEntity).JSON(APIResponse{\n")
-		fmt.Fprintf(destination, "		Success: false,\n")
-		fmt.Fprintf(destination, "		Message: \"Validation failed\",\n")
-		fmt.Fprintf(destination, "		Data:    nil,\n")
-		fmt.Fprintf(destination, "		Errors:  validationErrors,\n")
-		fmt.Fprintf(destination, "	})\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "// NewErrorResponse sends general error - success: false, message, data: null, errors: null.\n")
-		fmt.Fprintf(destination, "func NewErrorResponse(ctx *fiber.Ctx, err error) error {\n")
-		fmt.Fprintf(destination, "	var status int\n")
-		fmt.Fprintf(destination, "	var message string\n")
-		fmt.Fprintf(destination, "	switch e := err.(type) {\n")
-		fmt.Fprintf(destination, "	case errs.AppError:\n")
-		fmt.Fprintf(destination, "		status = e.Status\n")
-		fmt.Fprintf(destination, "		message = e.Message\n")
-		fmt.Fprintf(destination, "	default:\n")
-		fmt.Fprintf(destination, "		status = http.StatusInternalServerError\n")
-		fmt.Fprintf(destination, "		message = err.Error()\n")
-		fmt.Fprintf(destination, "	}\n")
-		fmt.Fprintf(destination, "	return ctx.Status(status).JSON(APIResponse{\n")
-		fmt.Fprintf(destination, "		Success: false,\n")
-		fmt.Fprintf(destination, "		Message: message,\n")
-		fmt.Fprintf(destination, "		Data:    nil,\n")
-		fmt.Fprintf(destination, "		Errors:  nil,\n")
-		fmt.Fprintf(destination, "	})\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "// NewNoContentResponse sends a 204 No Content response.\n")
-		fmt.Fprintf(destination, "func NewNoContentResponse(ctx *fiber.Ctx) error {\n")
-		fmt.Fprintf(destination, "	return ctx.SendStatus(http.StatusNoContent)\n")
-		fmt.Fprintf(destination, "}\n")
-
-		fmt.Println("Created responses package successfully:", file)
-	} else {
-		fmt.Println("File already exists:", file)
-	}
+func (g *Generator) createMainGo(data templateData) error {
+	return g.renderScaffold("templates/main.tmpl", "main.go", data)
 }
 
-func CreateConfigEnv(projectName string) {
-	pathFolder := "config"
-	if _, err := os.Stat(pathFolder); errors.Is(err, os.ErrNotExist) {
-		err := os.Mkdir(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-		}
-	}
-
-	path := "config/"
-	file := path + "env.go"
-	var _, err = os.Stat(file)
-
-	if os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer destination.Close()
-		fmt.Fprintf(destination, "package config\n\n")
-
-		fmt.Fprintf(destination, "import (\n")
-		fmt.Fprintf(destination, "\t\"fmt\"\n")
-		fmt.Fprintf(destination, "\t\"os\"\n")
-		fmt.Fprintf(destination, "\t\"strings\"\n\n")
-		fmt.Fprintf(destination, "\t\"github.com/spf13/viper\"\n")
-		fmt.Fprintf(destination, ")\n\n")
-
-		fmt.Fprintf(destination, "func init() {\n")
-		fmt.Fprintf(destination, "\t// Set up Viper for environment variable handling\n")
-		fmt.Fprintf(destination, "\tviper.AutomaticEnv()\n")
-		fmt.Fprintf(destination, "\tviper.SetEnvKeyReplacer(strings.NewReplacer(\".\", \"_\"))\n\n")
-		fmt.Fprintf(destination, "\t// Try loading config.yaml first\n")
-		fmt.Fprintf(destination, "\tviper.SetConfigName(\"config\")\n")
-		fmt.Fprintf(destination, "\tviper.SetConfigType(\"yaml\")\n")
-		fmt.Fprintf(destination, "\tviper.AddConfigPath(\"./\")\n\n")
-		fmt.Fprintf(destination, "\terr := viper.ReadInConfig()\n")
-		fmt.Fprintf(destination, "\tif err != nil {\n")
-		fmt.Fprintf(destination, "\t\t// If config.yaml not found, try loading .env instead\n")
-		fmt.Fprintf(destination, "\t\tfmt.Println(\"config.yaml not found, trying .env file\")\n")
-		fmt.Fprintf(destination, "\t\tviper.SetConfigName(\".env\")\n")
-		fmt.Fprintf(destination, "\t\tviper.SetConfigType(\"env\")\n")
-		fmt.Fprintf(destination, "\t\terr = viper.ReadInConfig()\n")
-		fmt.Fprintf(destination, "\t\tif err != nil {\n")
-		fmt.Fprintf(destination, "\t\t\tfmt.Println(\"ERROR_READING_CONFIG_FILE\", err)\n")
-		fmt.Fprintf(destination, "\t\t\treturn\n")
-		fmt.Fprintf(destination, "\t\t}\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\tfmt.Println(\"SUCCESS_READING_CONFIG_FILE\")\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "func GetEnv(key, defaultValue string) string {\n")
-		fmt.Fprintf(destination, "\t// Prioritize environment variables (for Kubernetes Secrets)\n")
-		fmt.Fprintf(destination, "\tif val, found := os.LookupEnv(key); found {\n")
-		fmt.Fprintf(destination, "\t\treturn val\n")
-		fmt.Fprintf(destination, "\t}\n\n")
-		fmt.Fprintf(destination, "\t// Fallback to Viper if the environment variable is not set\n")
-		fmt.Fprintf(destination, "\treadValue := viper.GetString(key)\n")
-		fmt.Fprintf(destination, "\tif readValue == \"\" {\n")
-		fmt.Fprintf(destination, "\t\treturn defaultValue\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\treturn readValue\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "func Env(key string) string {\n")
-		fmt.Fprintf(destination, "\t// Prioritize environment variables (for Kubernetes Secrets)\n")
-		fmt.Fprintf(destination, "\tif val, found := os.LookupEnv(key); found {\n")
-		fmt.Fprintf(destination, "\t\treturn val\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\treturn viper.GetString(key)\n")
-		fmt.Fprintf(destination, "}\n")
-	} else {
-		fmt.Println("File already exists!", file)
-		return
-	}
-
-	fmt.Println("Created Config successfully", file)
+func (g *Generator) CreateSrcDir() error {
+	return g.mkdir("internal")
 }
 
-func CreateConfigTimezonse(projectName string) {
-	pathFolder := "config"
-	if _, err := os.Stat(pathFolder); errors.Is(err, os.ErrNotExist) {
-		err := os.Mkdir(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println("Failed to create folder:", err)
-			return
-		}
-	}
-
-	file := pathFolder + "/timezone.go"
-	if _, err := os.Stat(file); os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println("Failed to create file:", err)
-			return
-		}
-		defer destination.Close()
-
-		content := `package config
-
-import (
-	"log"
-	"time"
-	_ "time/tzdata"
-)
-
-func init() {
-	location, err := time.LoadLocation("Asia/Bangkok")
-	if err != nil {
-		log.Fatal("ERROR_LOADING_TIMEZONE", err)
-	}
-	time.Local = location
+func (g *Generator) CreateValidation() error {
+	return g.renderScaffold("templates/validation.tmpl", "validation/fiber.go", newProjectData(""))
 }
-`
-		_, err = destination.WriteString(content)
-		if err != nil {
-			fmt.Println("Failed to write content:", err)
-			return
-		}
 
-		fmt.Println("Created Config Timezone successfully:", file)
-	} else {
-		fmt.Println("File already exists:", file)
-	}
+func (g *Generator) CreateDatabaseConnection(projectName string) error {
+	return g.createDatabaseConnection(newProjectData(projectName))
 }
 
-func CreateAppErrs() {
-	pathFolder := "errs"
-	if _, err := os.Stat(pathFolder); os.IsNotExist(err) {
-		err := os.Mkdir(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-		}
-	}
-
-	path := "errs/"
-	file := path + "errors.go"
-	if _, err := os.Stat(file); os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer destination.Close()
-
-		content := `package errs
-
-import "net/http"
-
-// AppError represents a standard application error with HTTP status code.
-type AppError struct {
-	Status  int
-	Message string
-	Code    string
+func (g *Generator) createDatabaseConnection(data templateData) error {
+	driver := databaseDrivers[data.Database]
+	return g.renderScaffold(driver.template, driver.outputPath, data)
 }
 
-func (a AppError) Error() string {
-	return a.Message
+func (g *Generator) CreateHandleResponse(projectName string) error {
+	return g.renderScaffold("templates/handle_responses.tmpl", "responses/handle_responses.go", newProjectData(projectName))
 }
 
-// NewError creates a custom error with specified code and message.
-func NewError(code int, errMsg string) error {
-	return AppError{
-		Status:  code,
-		Message: errMsg,
-	}
+func (g *Generator) CreateConfigEnv(projectName string) error {
+	return g.renderScaffold("templates/config_env.tmpl", "config/env.go", newProjectData(projectName))
 }
 
-// ErrorBadRequest returns a 400 Bad Request error.
-func ErrorBadRequest(errorMessage string) error {
-	return AppError{
-		Status:  http.StatusBadRequest,
-		Message: errorMessage,
-		Code:    "BAD_REQUEST",
-	}
+func (g *Generator) CreateConfigTimezonse(projectName string) error {
+	return g.renderScaffold("templates/config_timezone.tmpl", "config/timezone.go", newProjectData(projectName))
 }
 
-// ErrorUnauthorized returns a 401 Unauthorized error.
-func ErrorUnauthorized(errorMessage string) error {
-	return AppError{
-		Status:  http.StatusUnauthorized,
-		Message: errorMessage,
-		Code:    "UNAUTHORIZED",
-	}
+func (g *Generator) CreateAppErrs() error {
+	return g.renderScaffold("templates/errs.tmpl", "errs/errors.go", newProjectData(""))
 }
 
-// ErrorForbidden returns a 403 Forbidden error.
-func ErrorForbidden(errorMessage string) error {
-	return AppError{
-		Status:  http.StatusForbidden,
-		Message: errorMessage,
-		Code:    "FORBIDDEN",
-	}
+// CreateIDs creates the ids package, which makes and parses the IDs of
+// modules with a generated primary key.
+func (g *Generator) CreateIDs(projectName string) error {
+	return g.renderScaffold("templates/ids.tmpl", "ids/ids.go", newProjectData(projectName))
 }
 
-// ErrorNotFound returns a 404 Not Found error.
-func ErrorNotFound(errorMessage string) error {
-	return AppError{
-		Status:  http.StatusNotFound,
-		Message: errorMessage,
-		Code:    "NOT_FOUND",
-	}
+func (g *Generator) CreateLoggers(projectName string) error {
+	return g.renderScaffold("templates/loggers.tmpl", "logs/loggers.go", newProjectData(projectName))
 }
 
-// ErrorConflict returns a 409 Conflict error.
-func ErrorConflict(errorMessage string) error {
-	return AppError{
-		Status:  http.StatusConflict,
-		Message: errorMessage,
-		Code:    "CONFLICT",
-	}
+func (g *Generator) CreatePagination(projectName string) error {
+	return g.renderScaffold("templates/pagination.tmpl", "paginates/pagination.go", newProjectData(projectName))
 }
 
-// ErrorUnprocessableEntity returns a 422 Unprocessable Entity error.
-func ErrorUnprocessableEntity(errorMessage string) error {
-	return AppError{
-		Status:  http.StatusUnprocessableEntity,
-		Message: errorMessage,
-		Code:    "UNPROCESSABLE_ENTITY",
-	}
+func (g *Generator) CreateRoutes() error {
+	return g.renderScaffold("templates/routes.tmpl", "routes/routes.go", newProjectData(""))
 }
 
-// ErrorInternalServerError returns a 500 Internal Server Error.
-func ErrorInternalServerError(errorMessage string) error {
-	return AppError{
-		Status:  http.StatusInternalServerError,
-		Message: errorMessage,
-		Code:    "INTERNAL_SERVER_ERROR",
-	}
-}
-`
-		destination.WriteString(content)
-		fmt.Println("Created AppErrs successfully", file)
-	} else {
-		fmt.Println("File already exists!", file)
-	}
+func (g *Generator) CreateFiberRoutes(projectName string) error {
+	return g.createFiberRoutes(newProjectData(projectName))
 }
 
-func CreateLoggers(projectName string) {
-	pathFolder := "logs"
-	if _, err := os.Stat(pathFolder); errors.Is(err, os.ErrNotExist) {
-		err := os.Mkdir(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-		}
-	}
-
-	path := "logs/"
-	file := path + "loggers.go"
-	var _, err = os.Stat(file)
-
-	if os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer destination.Close()
-
-		// Write the logging package code - ECS format for Elastic/Kibana
-		fmt.Fprintf(destination, "package logs\n\n")
-
-		fmt.Fprintf(destination, "import (\n")
-		fmt.Fprintf(destination, "\t\"context\"\n")
-		fmt.Fprintf(destination, "\t\"fmt\"\n")
-		fmt.Fprintf(destination, "\n")
-		fmt.Fprintf(destination, "\t\"%s/config\"\n", projectName)
-		fmt.Fprintf(destination, "\t\"go.uber.org/zap\"\n")
-		fmt.Fprintf(destination, "\t\"go.uber.org/zap/zapcore\"\n")
-		fmt.Fprintf(destination, ")\n\n")
-
-		fmt.Fprintf(destination, "var log *zap.Logger\n")
-		fmt.Fprintf(destination, "var err error\n\n")
-
-		fmt.Fprintf(destination, "func init() {\n")
-		fmt.Fprintf(destination, "\tcfg := zap.NewProductionConfig()\n")
-		fmt.Fprintf(destination, "\t// ECS field names for Elastic/Kibana\n")
-		fmt.Fprintf(destination, "\tcfg.EncoderConfig.TimeKey = \"@timestamp\"\n")
-		fmt.Fprintf(destination, "\tcfg.EncoderConfig.LevelKey = \"log.level\"\n")
-		fmt.Fprintf(destination, "\tcfg.EncoderConfig.MessageKey = \"message\"\n")
-		fmt.Fprintf(destination, "\tcfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder\n")
-		fmt.Fprintf(destination, "\tcfg.EncoderConfig.StacktraceKey = \"\"\n")
-		fmt.Fprintf(destination, "\tcfg.InitialFields = map[string]interface{}{\"service.name\": config.GetEnv(\"app.name\", \"%s\")}\n", projectName)
-		fmt.Fprintf(destination, "\tlog, err = cfg.Build(zap.AddCallerSkip(1))\n")
-		fmt.Fprintf(destination, "\tif err != nil {\n")
-		fmt.Fprintf(destination, "\t\tfmt.Println(err)\n")
-		fmt.Fprintf(destination, "\t\treturn\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "// Info logs with ECS fields: @timestamp, log.level, message, trace.id, service.name\n")
-		fmt.Fprintf(destination, "func Info(message string, ctx context.Context, fields ...zap.Field) {\n")
-		fmt.Fprintf(destination, "\tif id := ctx.Value(\"requestid\"); id != nil {\n")
-		fmt.Fprintf(destination, "\t\tfields = append(fields, zap.String(\"trace.id\", fmt.Sprint(id)))\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\tlog.Info(message, fields...)\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "// Warn logs with ECS fields\n")
-		fmt.Fprintf(destination, "func Warn(message string, ctx context.Context, fields ...zap.Field) {\n")
-		fmt.Fprintf(destination, "\tif id := ctx.Value(\"requestid\"); id != nil {\n")
-		fmt.Fprintf(destination, "\t\tfields = append(fields, zap.String(\"trace.id\", fmt.Sprint(id)))\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\tlog.Warn(message, fields...)\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "// Error logs with ECS fields: @timestamp, log.level, message, error.message, trace.id, service.name\n")
-		fmt.Fprintf(destination, "func Error(message interface{}, ctx context.Context, fields ...zap.Field) {\n")
-		fmt.Fprintf(destination, "\tif id := ctx.Value(\"requestid\"); id != nil {\n")
-		fmt.Fprintf(destination, "\t\tfields = append(fields, zap.String(\"trace.id\", fmt.Sprint(id)))\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\tswitch v := message.(type) {\n")
-		fmt.Fprintf(destination, "\tcase error:\n")
-		fmt.Fprintf(destination, "\t\tfields = append(fields, zap.String(\"error.message\", v.Error()))\n")
-		fmt.Fprintf(destination, "\t\tlog.Error(v.Error(), fields...)\n")
-		fmt.Fprintf(destination, "\tcase string:\n")
-		fmt.Fprintf(destination, "\t\tlog.Error(v, fields...)\n")
-		fmt.Fprintf(destination, "\tdefault:\n")
-		fmt.Fprintf(destination, "\t\tlog.Error(fmt.Sprint(v), fields...)\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "// Debug logs with ECS fields\n")
-		fmt.Fprintf(destination, "func Debug(message string, fields ...zap.Field) {\n")
-		fmt.Fprintf(destination, "\tlog.Debug(message, fields...)\n")
-		fmt.Fprintf(destination, "}\n")
-
-		fmt.Println("Created Loggers successfully", file)
-	} else {
-		fmt.Println("File already exists!", file)
-		return
-	}
+func (g *Generator) createFiberRoutes(data templateData) error {
+	return g.renderScaffold("templates/fiber_routes.tmpl", fiberRoutesFile, data)
 }
 
-func CreatePagination(projectName string) {
-	pathFolder := "paginates"
-	if _, err := os.Stat(pathFolder); os.IsNotExist(err) {
-		err := os.Mkdir(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-	}
-
-	path := pathFolder + "/"
-	file := path + "pagination.go"
-	if _, err := os.Stat(file); os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer destination.Close()
-
-		fmt.Fprintf(destination, "package paginates\n\n")
-		fmt.Fprintf(destination, "import (\n")
-		fmt.Fprintf(destination, "\t\"gorm.io/gorm\"\n")
-		fmt.Fprintf(destination, "\t\"gorm.io/gorm/clause\"\n")
-		fmt.Fprintf(destination, ")\n\n")
-
-		fmt.Fprintf(destination, "// PaginateRequest holds pagination and filter parameters\n")
-		fmt.Fprintf(destination, "type PaginateRequest struct {\n")
-		fmt.Fprintf(destination, "\tLimit     int    `json:\"limit\"`\n")
-		fmt.Fprintf(destination, "\tPage      int    `json:\"page\"`\n")
-		fmt.Fprintf(destination, "\tStatus    string `json:\"status\"`\n")
-		fmt.Fprintf(destination, "\tSearch    string `json:\"search\"`\n")
-		fmt.Fprintf(destination, "\tOrderBy   string `json:\"order_by\"`\n")
-		fmt.Fprintf(destination, "\tSortBy    string `json:\"sort_by\"`\n")
-		fmt.Fprintf(destination, "\tStartDate string `json:\"start_date\"`\n")
-		fmt.Fprintf(destination, "\tEndDate   string `json:\"end_date\"`\n")
-		fmt.Fprintf(destination, "\tUserID    string `json:\"user_id\"`\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "// PaginatedResponse is the full API response for paginated data\n")
-		fmt.Fprintf(destination, "// Format: { success, message, pagination: { total_items, items_per_page, current_page, total_pages, next_page, previous_page }, data, errors: null }\n")
-		fmt.Fprintf(destination, "type PaginatedResponse struct {\n")
-		fmt.Fprintf(destination, "\tSuccess    bool        `json:\"success\"`\n")
-		fmt.Fprintf(destination, "\tMessage    string      `json:\"message\"`\n")
-		fmt.Fprintf(destination, "\tPagination Pagination  `json:\"pagination\"`\n")
-		fmt.Fprintf(destination, "\tData       interface{} `json:\"data\"`\n")
-		fmt.Fprintf(destination, "\tErrors     interface{} `json:\"errors\"`\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "// Pagination holds page information\n")
-		fmt.Fprintf(destination, "type Pagination struct {\n")
-		fmt.Fprintf(destination, "\tTotalItems   int  `json:\"total_items\"`\n")
-		fmt.Fprintf(destination, "\tItemsPerPage int  `json:\"items_per_page\"`\n")
-		fmt.Fprintf(destination, "\tCurrentPage  int  `json:\"current_page\"`\n")
-		fmt.Fprintf(destination, "\tTotalPages   int  `json:\"total_pages\"`\n")
-		fmt.Fprintf(destination, "\tNextPage     int  `json:\"next_page\"`\n")
-		fmt.Fprintf(destination, "\tPreviousPage *int `json:\"previous_page\"`\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "// Paginate applies limit, offset, and preload to the DB query\n")
-		fmt.Fprintf(destination, "func Paginate(db *gorm.DB, paginate PaginateRequest, resultModel interface{}) (*PaginatedResponse, error) {\n")
-		fmt.Fprintf(destination, "\tif paginate.Limit <= 0 {\n")
-		fmt.Fprintf(destination, "\t\tpaginate.Limit = 10\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\tif paginate.Page <= 0 {\n")
-		fmt.Fprintf(destination, "\t\tpaginate.Page = 1\n")
-		fmt.Fprintf(destination, "\t}\n\n")
-
-		fmt.Fprintf(destination, "\tvar total int64\n")
-		fmt.Fprintf(destination, "\tdb.Count(&total)\n")
-		fmt.Fprintf(destination, "\ttotalPages := (int(total) + paginate.Limit - 1) / paginate.Limit\n")
-		fmt.Fprintf(destination, "\toffset := (paginate.Page - 1) * paginate.Limit\n\n")
-
-		fmt.Fprintf(destination, "\tresult := db.Limit(paginate.Limit).\n")
-		fmt.Fprintf(destination, "\t\tOffset(offset).Preload(clause.Associations).Find(resultModel)\n")
-		fmt.Fprintf(destination, "\tif result.Error != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn nil, result.Error\n")
-		fmt.Fprintf(destination, "\t}\n\n")
-
-		fmt.Fprintf(destination, "\tnextPage := paginate.Page + 1\n")
-		fmt.Fprintf(destination, "\tif nextPage > totalPages {\n")
-		fmt.Fprintf(destination, "\t\tnextPage = 0\n")
-		fmt.Fprintf(destination, "\t}\n\n")
-
-		fmt.Fprintf(destination, "\tvar previousPage *int\n")
-		fmt.Fprintf(destination, "\tif paginate.Page > 1 {\n")
-		fmt.Fprintf(destination, "\t\tprev := paginate.Page - 1\n")
-		fmt.Fprintf(destination, "\t\tpreviousPage = &prev\n")
-		fmt.Fprintf(destination, "\t}\n\n")
-
-		fmt.Fprintf(destination, "\tresponse := &PaginatedResponse{\n")
-		fmt.Fprintf(destination, "\t\tSuccess: true,\n")
-		fmt.Fprintf(destination, "\t\tMessage: \"Data retrieved successfully\",\n")
-		fmt.Fprintf(destination, "\t\tPagination: Pagination{\n")
-		fmt.Fprintf(destination, "\t\t\tTotalItems:   int(total),\n")
-		fmt.Fprintf(destination, "\t\t\tItemsPerPage: paginate.Limit,\n")
-		fmt.Fprintf(destination, "\t\t\tCurrentPage:  paginate.Page,\n")
-		fmt.Fprintf(destination, "\t\t\tTotalPages:   totalPages,\n")
-		fmt.Fprintf(destination, "\t\t\tNextPage:     nextPage,\n")
-		fmt.Fprintf(destination, "\t\t\tPreviousPage: previousPage,\n")
-		fmt.Fprintf(destination, "\t\t},\n")
-		fmt.Fprintf(destination, "\t\tData:   resultModel,\n")
-		fmt.Fprintf(destination, "\t\tErrors: nil,\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\treturn response, nil\n")
-		fmt.Fprintf(destination, "}\n")
-
-		fmt.Println("Created pagination.go in paginates folder")
-	} else {
-		fmt.Println("File already exists:", file)
-	}
+// GenerateModule generates a new module (model, repository, service, controller,
+// requests, responses, tests, migrations) for the given module name. Optional
+// field specs such as "price:decimal" define the model fields; without them the
+// model gets a single name:string field. The project name is read from go.mod
+// in the current directory. Call this from the project root. The module files
+// are written only when all of them render; otherwise every failure is
+// returned joined and the project is left unchanged.
+func (g *Generator) GenerateModule(moduleName string, fieldSpecs ...string) error {
+	return g.GenerateModuleWithID(moduleName, "", fieldSpecs...)
 }
 
-func CreateRoutes() {
-	pathFolder := "routes"
-	if _, err := os.Stat(pathFolder); os.IsNotExist(err) {
-		err := os.Mkdir(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-	}
-
-	path := pathFolder + "/"
-	file := path + "routes.go"
-	var _, err = os.Stat(file)
+// GenerateModuleWithID generates a module as GenerateModule does, with a
+// primary key of type idType, one of IDTypes; empty for uint. Foreign keys
+// referencing another module take the type of its ID.
+func (g *Generator) GenerateModuleWithID(moduleName, idType string, fieldSpecs ...string) error {
+	return g.GenerateModuleWithOptions(moduleName, ModuleOptions{ID: idType}, fieldSpecs...)
+}
 
-	if os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer destination.Close()
-
-		fmt.Fprintf(destination, "package routes\n\n")
-		fmt.Fprintf(destination, "import \"github.com/gofiber/fiber/v2\"\n\n")
-		fmt.Fprintf(destination, "// Routes defines the interface for route installation.\n")
-		fmt.Fprintf(destination, "type Routes interface {\n")
-		fmt.Fprintf(destination, "\tInstall(app *fiber.App)\n")
-		fmt.Fprintf(destination, "}\n\n")
-		fmt.Fprintf(destination, "// RouteRegistrar defines the interface for controllers that register routes.\n")
-		fmt.Fprintf(destination, "type RouteRegistrar interface {\n")
-		fmt.Fprintf(destination, "\tRegisterRoutes(router fiber.Router)\n")
-		fmt.Fprintf(destination, "}\n")
-
-		fmt.Println("Created Routes successfully:", file)
-	} else {
-		fmt.Println("File already exists!", file)
-	}
+// ModuleOptions are the settings of a module generated by
+// GenerateModuleWithOptions; the zero value is GenerateModule's.
+type ModuleOptions struct {
+	ID         string // primary key type, one of IDTypes; uint when empty
+	Pagination string // list pagination, one of PaginationModes; offset when empty
 }
 
-func CreateFiberRoutes(projectName string) {
-	pathFolder := "routes"
-	if _, err := os.Stat(pathFolder); os.IsNotExist(err) {
-		err := os.Mkdir(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
+// GenerateModuleWithOptions generates a module as GenerateModule does, with
+// the settings in opts.
+func (g *Generator) GenerateModuleWithOptions(moduleName string, opts ModuleOptions, fieldSpecs ...string) error {
+	moduleName = strings.ToLower(moduleName)
+	if err := errors.Join(validateID(opts.ID), validatePagination(opts.Pagination)); err != nil {
+		return err
 	}
+	idType := normalizeID(opts.ID)
 
-	path := pathFolder + "/"
-	file := path + "fiber_routes.go"
-	var _, err = os.Stat(file)
-
-	if os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer destination.Close()
-
-		fmt.Fprintf(destination, "package routes\n\n")
-		fmt.Fprintf(destination, "import (\n")
-		fmt.Fprintf(destination, "\t\"%s/internal/controllers\"\n", projectName)
-		fmt.Fprintf(destination, "\t\"github.com/gofiber/fiber/v2\"\n")
-		fmt.Fprintf(destination, ")\n\n")
-
-		fmt.Fprintf(destination, "// FiberRoutes manages route registration.\n")
-		fmt.Fprintf(destination, "type FiberRoutes struct {\n")
-		fmt.Fprintf(destination, "\texampleController *controllers.ExampleController\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "// NewFiberRoutes creates a new FiberRoutes instance.\n")
-		fmt.Fprintf(destination, "func NewFiberRoutes(exampleController *controllers.ExampleController) *FiberRoutes {\n")
-		fmt.Fprintf(destination, "\treturn &FiberRoutes{\n")
-		fmt.Fprintf(destination, "\t\texampleController: exampleController,\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		fmt.Fprintf(destination, "// Install registers all routes with API versioning.\n")
-		fmt.Fprintf(destination, "func (r *FiberRoutes) Install(app *fiber.App) {\n")
-		fmt.Fprintf(destination, "\t// Health check endpoint\n")
-		fmt.Fprintf(destination, "\tapp.Get(\"/health\", func(ctx *fiber.Ctx) error {\n")
-		fmt.Fprintf(destination, "\t\treturn ctx.JSON(fiber.Map{\"status\": \"healthy\"})\n")
-		fmt.Fprintf(destination, "\t})\n\n")
-
-		fmt.Fprintf(destination, "\t// API v1 routes with versioning\n")
-		fmt.Fprintf(destination, "\tv1 := app.Group(\"/api/v1\")\n\n")
-
-		fmt.Fprintf(destination, "\t// Register module routes\n")
-		fmt.Fprintf(destination, "\tr.exampleController.RegisterRoutes(v1)\n\n")
-
-		fmt.Fprintf(destination, "\t// TODO: Add more module routes here\n")
-		fmt.Fprintf(destination, "\t// r.userController.RegisterRoutes(v1)\n")
-		fmt.Fprintf(destination, "}\n")
-
-		fmt.Println("Created fiber_routes.go successfully:", file)
-	} else {
-		fmt.Println("File already exists!", file)
+	if len(fieldSpecs) == 0 {
+		fieldSpecs = defaultFieldSpecs
+	}
+	fields, err := ParseFields(fieldSpecs)
+	if err != nil {
+		return err
 	}
-}
-
-// GenerateModule generates a new module (model, repository, service, controller,
-// requests, responses, tests, migrations) for the given module name. The project
-// name is read from go.mod in the current directory. Call this from the project root.
-func GenerateModule(moduleName string) error {
-	moduleName = strings.ToLower(moduleName)
 
-	projectName, err := getProjectName()
+	projectName, err := g.getProjectName()
 	if err != nil {
 		return fmt.Errorf("could not determine project name: %w", err)
 	}
-
-	CreateRequests(moduleName)
-	CreateResponses(moduleName)
-	CreateModels(moduleName)
-	CreateRepositories(moduleName, projectName)
-	CreateServices(moduleName, projectName)
-	CreateControllers(moduleName, projectName)
-	if err := GenerateTestFiles(moduleName, projectName); err != nil {
-		return err
+	fields = g.withRelationIDs(moduleName, idType, fields, nil)
+	// A has_many needs the foreign key of its module in the child: existing
+	// parents add theirs here, and existing children get this module's below.
+	for _, key := range g.parentKeys(moduleName, fields) {
+		fmt.Printf("Adding field %s to module %s for the has_many of module %s.\n", key.Name, moduleName, key.Model)
+		fields = append(fields, key)
 	}
-	CreateMigrations(moduleName, projectName)
-	return nil
-}
-
-func CreateRequests(filename string) {
-	pathFolder := WORKDIR + "requests"
-	if _, err := os.Stat(pathFolder); errors.Is(err, os.ErrNotExist) {
-		err := os.MkdirAll(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
+	children, declared := g.hasManyChildren(moduleName, idType, fields)
+	for _, child := range slices.Sorted(maps.Keys(declared)) {
+		if _, ok := children[child]; !ok {
+			fmt.Printf("Note: module %s does not exist yet; it gets the %s foreign key when generated.\n", child, declared[child][0].Name)
 		}
 	}
-
-	path := WORKDIR + "requests/"
-	file := path + filename + "_request" + ".go"
-	var _, err = os.Stat(file)
-
-	if os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer destination.Close()
-
-		upper := strings.Replace(
-			cases.Title(language.Und, cases.NoLower).String(strings.ReplaceAll(filename, "_", " ")),
-			" ", "", -1,
-		)
-
-		fmt.Fprintf(destination, "package requests\n\n")
-		fmt.Fprintf(destination, "// Create%sRequest represents the request body for creating a %s.\n", upper, filename)
-		fmt.Fprintf(destination, "type Create%sRequest struct {\n", upper)
-		fmt.Fprintf(destination, "\tName string `json:\"name\" validate:\"required,min=1,max=255\"`\n")
-		fmt.Fprintf(destination, "}\n\n")
-		fmt.Fprintf(destination, "// Update%sRequest represents the request body for updating a %s.\n", upper, filename)
-		fmt.Fprintf(destination, "type Update%sRequest struct {\n", upper)
-		fmt.Fprintf(destination, "\tName string `json:\"name\" validate:\"required,min=1,max=255\"`\n")
-		fmt.Fprintf(destination, "}\n")
-	} else {
-		fmt.Println("File already exists!", file)
-		return
+	// The related modules may be generated next, so these are only warnings.
+	for _, problem := range g.relationProblems(moduleName, fields, declared) {
+		fmt.Println("Warning:", problem)
 	}
 
-	fmt.Println("Created Request successfully", file)
-}
-
-func CreateResponses(filename string) {
-	pathFolder := WORKDIR + "responses"
-	if _, err := os.Stat(pathFolder); errors.Is(err, os.ErrNotExist) {
-		err := os.MkdirAll(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
+	err = g.transaction(func() error {
+		if err := g.createModule(moduleName, projectName, fields, moduleOptions{ID: idType, Pagination: opts.Pagination}); err != nil {
+			return err
 		}
-	}
-	path := WORKDIR + "responses/"
-	file := path + filename + "_response" + ".go"
-	var _, err = os.Stat(file)
-
-	if os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
+		var errs []error
+		for _, child := range slices.Sorted(maps.Keys(children)) {
+			fmt.Printf("Adding field %s to module %s for the has_many of module %s.\n", children[child].Name, child, moduleName)
+			if err := g.addParentKey(child, projectName, children[child]); err != nil {
+				errs = append(errs, fmt.Errorf("module %s: %w", child, err))
+			}
 		}
-		defer destination.Close()
-
-		upper := strings.Replace(
-			cases.Title(language.Und, cases.NoLower).String(strings.ReplaceAll(filename, "_", " ")),
-			" ", "", -1,
-		)
-
-		fmt.Fprintf(destination, "package responses\n\n")
-		fmt.Fprintf(destination, "import \"time\"\n\n")
-		fmt.Fprintf(destination, "// %sResponse represents the response body for %s.\n", upper, filename)
-		fmt.Fprintf(destination, "type %sResponse struct {\n", upper)
-		fmt.Fprintf(destination, "\tID        uint      `json:\"id\"`\n")
-		fmt.Fprintf(destination, "\tName      string    `json:\"name\"`\n")
-		fmt.Fprintf(destination, "\tCreatedAt time.Time `json:\"created_at\"`\n")
-		fmt.Fprintf(destination, "\tUpdatedAt time.Time `json:\"updated_at\"`\n")
-		fmt.Fprintf(destination, "}\n")
-	} else {
-		fmt.Println("File already exists!", file)
-		return
+		return errors.Join(errs...)
+	})
+	if err != nil {
+		// Wiring a module with missing files into main.go would break the build.
+		return err
 	}
-
-	fmt.Println("Created Response successfully", file)
+	// Registered separately, so a main.go that no longer looks generated keeps
+	// the module files for manual wiring.
+	return g.RegisterModule(moduleName, projectName)
+}
+
+// moduleOptions are the settings of a module beyond its fields; see
+// templateData.
+type moduleOptions struct {
+	Endpoints  []string       // controller endpoints; all when empty
+	Table      string         // database table; see templateData.TableName
+	Filters    []string       // fields List filters on; see templateData.Filters
+	Status     map[string]int // success statuses; see templateData.SuccessStatus
+	ID         string         // primary key type; see templateData.ID
+	Pagination string         // list pagination; see templateData.Pagination
+}
+
+// moduleData returns the template data of a module with opts set.
+func (o moduleOptions) moduleData(projectName, moduleName string, fields []Field) templateData {
+	data := newModuleData(projectName, moduleName, fields)
+	data.Endpoints, data.Table, data.Filters, data.Status, data.ID = o.Endpoints, o.Table, o.Filters, o.Status, normalizeID(o.ID)
+	data.Pagination = normalizePagination(o.Pagination)
+	return data
+}
+
+// createModule creates the files of a module and adds it to the migrations.
+// Modules with a generated ID also get the ids package.
+func (g *Generator) createModule(moduleName, projectName string, fields []Field, opts moduleOptions) error {
+	var ids error
+	if normalizeID(opts.ID) != "" {
+		ids = g.CreateIDs(projectName)
+	}
+	return errors.Join(
+		ids,
+		g.createRequests(moduleName, fields, opts),
+		g.createResponses(moduleName, fields, opts),
+		g.createModel(moduleName, projectName, fields, opts),
+		g.createRepository(moduleName, projectName, fields, opts),
+		g.createService(moduleName, projectName, fields, opts),
+		g.createController(moduleName, projectName, fields, opts),
+		g.generateTestFiles(moduleName, projectName, fields, opts, false),
+		g.CreateMigrations(moduleName, projectName),
+	)
 }
 
-func CreateModels(filename string) {
-	pathFolder := WORKDIR + "models"
-	if _, err := os.Stat(pathFolder); errors.Is(err, os.ErrNotExist) {
-		err := os.MkdirAll(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-		}
-	}
+func (g *Generator) CreateRequests(filename string, fields ...Field) error {
+	return g.createRequests(filename, fields, moduleOptions{})
+}
 
-	path := WORKDIR + "models/"
-	file := path + filename + ".go"
-	var _, err = os.Stat(file)
+func (g *Generator) createRequests(filename string, fields []Field, opts moduleOptions) error {
+	return g.renderScaffold("templates/request.tmpl", WORKDIR+"requests/"+filename+"_request.go", opts.moduleData("", filename, fields))
+}
 
-	if os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer destination.Close()
-		upperString := strings.Replace(cases.Title(language.Und, cases.NoLower).String(strings.Replace(filename, "_", " ", -1)), " ", "", -1)
-
-		fmt.Fprintf(destination, "package models\n\n")
-		fmt.Fprintf(destination, "import (\n")
-		fmt.Fprintf(destination, "\t\"time\"\n\n")
-		fmt.Fprintf(destination, "\t\"gorm.io/gorm\"\n")
-		fmt.Fprintf(destination, ")\n\n")
-		fmt.Fprintf(destination, "// %s represents the %s entity.\n", upperString, filename)
-		fmt.Fprintf(destination, "type %s struct {\n", upperString)
-		fmt.Fprintf(destination, "\tID        uint           `json:\"id\" gorm:\"primaryKey\"`\n")
-		fmt.Fprintf(destination, "\tName      string         `json:\"name\" gorm:\"size:255;not null\"`\n")
-		fmt.Fprintf(destination, "\tCreatedAt time.Time      `json:\"created_at\"`\n")
-		fmt.Fprintf(destination, "\tUpdatedAt time.Time      `json:\"updated_at\"`\n")
-		fmt.Fprintf(destination, "\tDeletedAt gorm.DeletedAt `json:\"deleted_at,omitempty\" gorm:\"index\"`\n")
-		fmt.Fprintf(destination, "}\n\n")
-		fmt.Fprintf(destination, "// TableName specifies the table name for %s model.\n", upperString)
-		fmt.Fprintf(destination, "func (%s) TableName() string {\n", upperString)
-		fmt.Fprintf(destination, "\treturn \"%ss\"\n", filename)
-		fmt.Fprintf(destination, "}\n")
-	} else {
-		fmt.Println("File already exists!", file)
-		return
-	}
+func (g *Generator) CreateResponses(filename string, fields ...Field) error {
+	return g.createResponses(filename, fields, moduleOptions{})
+}
 
-	fmt.Println("Created Model successfully", file)
+func (g *Generator) createResponses(filename string, fields []Field, opts moduleOptions) error {
+	data := newModuleData("", filename, fields)
+	data.ID = normalizeID(opts.ID)
+	return g.renderScaffold("templates/response.tmpl", WORKDIR+"responses/"+filename+"_response.go", data)
 }
 
-func CreateRepositories(filename string, projectName string) {
-	pathFolder := WORKDIR + "repositories"
-	if _, err := os.Stat(pathFolder); errors.Is(err, os.ErrNotExist) {
-		err := os.MkdirAll(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-	}
+func (g *Generator) CreateModels(filename string, fields ...Field) error {
+	return g.createModel(filename, "", fields, moduleOptions{})
+}
 
-	path := WORKDIR + "repositories/"
-	file := path + filename + "_repository" + ".go"
-	if _, err := os.Stat(file); os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer destination.Close()
-
-		upper := strings.Replace(
-			cases.Title(language.Und, cases.NoLower).String(strings.ReplaceAll(filename, "_", " ")),
-			" ", "", -1,
-		)
-		lower := strings.ToLower(string(upper[0])) + upper[1:]
-
-		fmt.Fprintf(destination, "package repositories\n\n")
-		fmt.Fprintf(destination, "import (\n")
-		fmt.Fprintf(destination, "\t\"%s/internal/models\"\n\n", projectName)
-		fmt.Fprintf(destination, "\t\"gorm.io/gorm\"\n")
-		fmt.Fprintf(destination, ")\n\n")
-
-		// Interface with CRUD methods
-		fmt.Fprintf(destination, "// %sRepository defines the interface for %s data operations.\n", upper, filename)
-		fmt.Fprintf(destination, "type %sRepository interface {\n", upper)
-		fmt.Fprintf(destination, "\tFindAll() ([]models.%s, error)\n", upper)
-		fmt.Fprintf(destination, "\tFindByID(id uint) (*models.%s, error)\n", upper)
-		fmt.Fprintf(destination, "\tCreate(entity *models.%s) error\n", upper)
-		fmt.Fprintf(destination, "\tUpdate(entity *models.%s) error\n", upper)
-		fmt.Fprintf(destination, "\tDelete(id uint) error\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		// Struct
-		fmt.Fprintf(destination, "type %sRepository struct {\n", lower)
-		fmt.Fprintf(destination, "\tdb *gorm.DB\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		// Constructor
-		fmt.Fprintf(destination, "// New%sRepository creates a new %s repository instance.\n", upper, filename)
-		fmt.Fprintf(destination, "func New%sRepository(db *gorm.DB) %sRepository {\n", upper, upper)
-		fmt.Fprintf(destination, "\treturn &%sRepository{db: db}\n", lower)
-		fmt.Fprintf(destination, "}\n\n")
-
-		// FindAll
-		fmt.Fprintf(destination, "// FindAll retrieves all %s records.\n", filename)
-		fmt.Fprintf(destination, "func (r *%sRepository) FindAll() ([]models.%s, error) {\n", lower, upper)
-		fmt.Fprintf(destination, "\tvar entities []models.%s\n", upper)
-		fmt.Fprintf(destination, "\tif err := r.db.Find(&entities).Error; err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn nil, err\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\treturn entities, nil\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		// FindByID
-		fmt.Fprintf(destination, "// FindByID retrieves a %s by its ID.\n", filename)
-		fmt.Fprintf(destination, "func (r *%sRepository) FindByID(id uint) (*models.%s, error) {\n", lower, upper)
-		fmt.Fprintf(destination, "\tvar entity models.%s\n", upper)
-		fmt.Fprintf(destination, "\tif err := r.db.First(&entity, id).Error; err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn nil, err\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\treturn &entity, nil\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		// Create
-		fmt.Fprintf(destination, "// Create inserts a new %s record.\n", filename)
-		fmt.Fprintf(destination, "func (r *%sRepository) Create(entity *models.%s) error {\n", lower, upper)
-		fmt.Fprintf(destination, "\treturn r.db.Create(entity).Error\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		// Update
-		fmt.Fprintf(destination, "// Update modifies an existing %s record.\n", filename)
-		fmt.Fprintf(destination, "func (r *%sRepository) Update(entity *models.%s) error {\n", lower, upper)
-		fmt.Fprintf(destination, "\treturn r.db.Save(entity).Error\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		// Delete
-		fmt.Fprintf(destination, "// Delete removes a %s by its ID (soft delete).\n", filename)
-		fmt.Fprintf(destination, "func (r *%sRepository) Delete(id uint) error {\n", lower)
-		fmt.Fprintf(destination, "\treturn r.db.Delete(&models.%s{}, id).Error\n", upper)
-		fmt.Fprintf(destination, "}\n")
-
-		fmt.Println("Created Repository successfully", file)
-	} else {
-		fmt.Println("File already exists!", file)
+// createModel creates the module's model; projectName is only needed for a
+// generated ID.
+func (g *Generator) createModel(filename, projectName string, fields []Field, opts moduleOptions) error {
+	data := newModuleData("", filename, fields)
+	data.Table, data.ID = opts.Table, normalizeID(opts.ID)
+	if data.GeneratesID() {
+		data.ProjectName = projectName
 	}
+	return g.renderScaffold("templates/model.tmpl", WORKDIR+"models/"+filename+".go", data)
 }
 
-func CreateServices(filename string, projectName string) {
-	pathFolder := WORKDIR + "services"
-	if _, err := os.Stat(pathFolder); errors.Is(err, os.ErrNotExist) {
-		err := os.MkdirAll(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-		}
-	}
+// CreateRepositories creates the module's repository, preloading the
+// associations its model declares.
+func (g *Generator) CreateRepositories(filename string, projectName string) error {
+	id, _ := g.detectModelID(filename)
+	opts := moduleOptions{ID: id, Pagination: g.detectPagination(filename)}
+	return g.createRepository(filename, projectName, g.resolveModuleFields(filename), opts)
+}
 
-	path := WORKDIR + "services/"
-	file := path + filename + "_service" + ".go"
-	var _, err = os.Stat(file)
+func (g *Generator) createRepository(filename, projectName string, fields []Field, opts moduleOptions) error {
+	return g.renderScaffold("templates/repository.tmpl", WORKDIR+"repositories/"+filename+"_repository.go", opts.moduleData(projectName, filename, fields))
+}
 
-	if os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer destination.Close()
-		upper := strings.Replace(cases.Title(language.Und, cases.NoLower).String(strings.Replace(filename, "_", " ", -1)), " ", "", -1)
-		lower := strings.ToLower(string(upper[0])) + string(upper[1:])
-
-		fmt.Fprintf(destination, "package services\n\n")
-		fmt.Fprintf(destination, "import (\n")
-		fmt.Fprintf(destination, "\t\"errors\"\n\n")
-		fmt.Fprintf(destination, "\t\"%s/errs\"\n", projectName)
-		fmt.Fprintf(destination, "\t\"%s/internal/models\"\n", projectName)
-		fmt.Fprintf(destination, "\t\"%s/internal/repositories\"\n", projectName)
-		fmt.Fprintf(destination, "\t\"%s/internal/requests\"\n\n", projectName)
-		fmt.Fprintf(destination, "\t\"gorm.io/gorm\"\n")
-		fmt.Fprintf(destination, ")\n\n")
-
-		// Interface with CRUD methods
-		fmt.Fprintf(destination, "// %sService defines the interface for %s business logic.\n", upper, filename)
-		fmt.Fprintf(destination, "type %sService interface {\n", upper)
-		fmt.Fprintf(destination, "\tList() ([]models.%s, error)\n", upper)
-		fmt.Fprintf(destination, "\tGet(id uint) (*models.%s, error)\n", upper)
-		fmt.Fprintf(destination, "\tCreate(req *requests.Create%sRequest) (*models.%s, error)\n", upper, upper)
-		fmt.Fprintf(destination, "\tUpdate(id uint, req *requests.Update%sRequest) (*models.%s, error)\n", upper, upper)
-		fmt.Fprintf(destination, "\tDelete(id uint) error\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		// Struct
-		fmt.Fprintf(destination, "type %sService struct {\n", lower)
-		fmt.Fprintf(destination, "\trepo repositories.%sRepository\n", upper)
-		fmt.Fprintf(destination, "}\n\n")
-
-		// Constructor
-		fmt.Fprintf(destination, "// New%sService creates a new %s service instance.\n", upper, filename)
-		fmt.Fprintf(destination, "func New%sService(repo repositories.%sRepository) %sService {\n", upper, upper, upper)
-		fmt.Fprintf(destination, "\treturn &%sService{repo: repo}\n", lower)
-		fmt.Fprintf(destination, "}\n\n")
-
-		// List
-		fmt.Fprintf(destination, "// List retrieves all %s records.\n", filename)
-		fmt.Fprintf(destination, "func (s *%sService) List() ([]models.%s, error) {\n", lower, upper)
-		fmt.Fprintf(destination, "\treturn s.repo.FindAll()\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		// Get
-		fmt.Fprintf(destination, "// Get retrieves a %s by ID.\n", filename)
-		fmt.Fprintf(destination, "func (s *%sService) Get(id uint) (*models.%s, error) {\n", lower, upper)
-		fmt.Fprintf(destination, "\tentity, err := s.repo.FindByID(id)\n")
-		fmt.Fprintf(destination, "\tif err != nil {\n")
-		fmt.Fprintf(destination, "\t\tif errors.Is(err, gorm.ErrRecordNotFound) {\n")
-		fmt.Fprintf(destination, "\t\t\treturn nil, errs.ErrorNotFound(\"%s not found\")\n", upper)
-		fmt.Fprintf(destination, "\t\t}\n")
-		fmt.Fprintf(destination, "\t\treturn nil, err\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\treturn entity, nil\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		// Create
-		fmt.Fprintf(destination, "// Create creates a new %s.\n", filename)
-		fmt.Fprintf(destination, "func (s *%sService) Create(req *requests.Create%sRequest) (*models.%s, error) {\n", lower, upper, upper)
-		fmt.Fprintf(destination, "\tentity := &models.%s{\n", upper)
-		fmt.Fprintf(destination, "\t\tName: req.Name,\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\tif err := s.repo.Create(entity); err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn nil, err\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\treturn entity, nil\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		// Update
-		fmt.Fprintf(destination, "// Update modifies an existing %s.\n", filename)
-		fmt.Fprintf(destination, "func (s *%sService) Update(id uint, req *requests.Update%sRequest) (*models.%s, error) {\n", lower, upper, upper)
-		fmt.Fprintf(destination, "\tentity, err := s.repo.FindByID(id)\n")
-		fmt.Fprintf(destination, "\tif err != nil {\n")
-		fmt.Fprintf(destination, "\t\tif errors.Is(err, gorm.ErrRecordNotFound) {\n")
-		fmt.Fprintf(destination, "\t\t\treturn nil, errs.ErrorNotFound(\"%s not found\")\n", upper)
-		fmt.Fprintf(destination, "\t\t}\n")
-		fmt.Fprintf(destination, "\t\treturn nil, err\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\tentity.Name = req.Name\n")
-		fmt.Fprintf(destination, "\tif err := s.repo.Update(entity); err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn nil, err\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\treturn entity, nil\n")
-		fmt.Fprintf(destination, "}\n\n")
-
-		// Delete
-		fmt.Fprintf(destination, "// Delete removes a %s by ID.\n", filename)
-		fmt.Fprintf(destination, "func (s *%sService) Delete(id uint) error {\n", lower)
-		fmt.Fprintf(destination, "\t_, err := s.repo.FindByID(id)\n")
-		fmt.Fprintf(destination, "\tif err != nil {\n")
-		fmt.Fprintf(destination, "\t\tif errors.Is(err, gorm.ErrRecordNotFound) {\n")
-		fmt.Fprintf(destination, "\t\t\treturn errs.ErrorNotFound(\"%s not found\")\n", upper)
-		fmt.Fprintf(destination, "\t\t}\n")
-		fmt.Fprintf(destination, "\t\treturn err\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\treturn s.repo.Delete(id)\n")
-		fmt.Fprintf(destination, "}\n")
-	} else {
-		fmt.Println("File already exists!", file)
-		return
-	}
+func (g *Generator) CreateServices(filename string, projectName string, fields ...Field) error {
+	id, _ := g.detectModelID(filename)
+	return g.createService(filename, projectName, fields, moduleOptions{ID: id, Pagination: g.detectPagination(filename)})
+}
 
-	fmt.Println("Created Service successfully", file)
+func (g *Generator) createService(filename, projectName string, fields []Field, opts moduleOptions) error {
+	return g.renderScaffold("templates/service.tmpl", WORKDIR+"services/"+filename+"_service.go", opts.moduleData(projectName, filename, fields))
 }
 
-func CreateControllers(filename string, projectName string) {
-	pathFolder := WORKDIR + "controllers"
-	if _, err := os.Stat(pathFolder); errors.Is(err, os.ErrNotExist) {
-		err := os.MkdirAll(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-		}
-	}
+func (g *Generator) CreateControllers(filename string, projectName string) error {
+	id, _ := g.detectModelID(filename)
+	return g.createController(filename, projectName, nil, moduleOptions{ID: id, Pagination: g.detectPagination(filename)})
+}
 
-	path := WORKDIR + "controllers/"
-	file := path + filename + "_controller" + ".go"
-	if _, err := os.Stat(file); os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer destination.Close()
-
-		upper := strings.Replace(
-			cases.Title(language.Und, cases.NoLower).String(strings.ReplaceAll(filename, "_", " ")),
-			" ", "", -1,
-		)
-		plural := toPlural(filename)
-
-		fmt.Fprintf(destination, "package controllers\n\n")
-		fmt.Fprintf(destination, "import (\n")
-		fmt.Fprintf(destination, "\t\"strconv\"\n\n")
-		fmt.Fprintf(destination, "\t\"%s/internal/requests\"\n", projectName)
-		fmt.Fprintf(destination, "\t\"%s/internal/services\"\n", projectName)
-		fmt.Fprintf(destination, "\t\"%s/responses\"\n", projectName)
-		fmt.Fprintf(destination, "\t\"%s/validation\"\n\n", projectName)
-		fmt.Fprintf(destination, "\t\"github.com/gofiber/fiber/v2\"\n")
-		fmt.Fprintf(destination, ")\n\n")
-
-		// Struct
-		fmt.Fprintf(destination, "// %sController handles HTTP requests for %s resources.\n", upper, filename)
-		fmt.Fprintf(destination, "type %sController struct {\n", upper)
-		fmt.Fprintf(destination, "\tservice services.%sService\n", upper)
-		fmt.Fprintf(destination, "}\n\n")
-
-		// Constructor
-		fmt.Fprintf(destination, "// New%sController creates a new %s controller instance.\n", upper, filename)
-		fmt.Fprintf(destination, "func New%sController(service services.%sService) *%sController {\n", upper, upper, upper)
-		fmt.Fprintf(destination, "\treturn &%sController{service: service}\n", upper)
-		fmt.Fprintf(destination, "}\n\n")
-
-		// RegisterRoutes method for RESTful routing
-		fmt.Fprintf(destination, "// RegisterRoutes registers all %s routes.\n", filename)
-		fmt.Fprintf(destination, "func (c *%sController) RegisterRoutes(router fiber.Router) {\n", upper)
-		fmt.Fprintf(destination, "\tgroup := router.Group(\"/%s\")\n", plural)
-		fmt.Fprintf(destination, "\tgroup.Get(\"/\", c.List)       // GET /%s\n", plural)
-		fmt.Fprintf(destination, "\tgroup.Get(\"/:id\", c.Get)     // GET /%s/:id\n", plural)
-		fmt.Fprintf(destination, "\tgroup.Post(\"/\", c.Create)    // POST /%s\n", plural)
-		fmt.Fprintf(destination, "\tgroup.Put(\"/:id\", c.Update)  // PUT /%s/:id\n", plural)
-		fmt.Fprintf(destination, "\tgroup.Delete(\"/:id\", c.Delete) // DELETE /%s/:id\n", plural)
-		fmt.Fprintf(destination, "}\n\n")
-
-		// List - GET /resources
-		fmt.Fprintf(destination, "// List retrieves all %s.\n", plural)
-		fmt.Fprintf(destination, "// @Summary      List all %s\n", plural)
-		fmt.Fprintf(destination, "// @Description  Get a list of all %s\n", plural)
-		fmt.Fprintf(destination, "// @Tags         %s\n", plural)
-		fmt.Fprintf(destination, "// @Accept       json\n")
-		fmt.Fprintf(destination, "// @Produce      json\n")
-		fmt.Fprintf(destination, "// @Success      200  {object}  responses.APIResponse\n")
-		fmt.Fprintf(destination, "// @Router       /%s [get]\n", plural)
-		fmt.Fprintf(destination, "func (c *%sController) List(ctx *fiber.Ctx) error {\n", upper)
-		fmt.Fprintf(destination, "\tdata, err := c.service.List()\n")
-		fmt.Fprintf(destination, "\tif err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn responses.NewErrorResponse(ctx, err)\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\treturn responses.NewSuccessResponse(ctx, \"%s retrieved successfully\", data)\n", upper)
-		fmt.Fprintf(destination, "}\n\n")
-
-		// Get - GET /resources/:id
-		fmt.Fprintf(destination, "// Get retrieves a %s by ID.\n", filename)
-		fmt.Fprintf(destination, "// @Summary      Get a %s\n", filename)
-		fmt.Fprintf(destination, "// @Description  Get a %s by its ID\n", filename)
-		fmt.Fprintf(destination, "// @Tags         %s\n", plural)
-		fmt.Fprintf(destination, "// @Accept       json\n")
-		fmt.Fprintf(destination, "// @Produce      json\n")
-		fmt.Fprintf(destination, "// @Param        id   path      int  true  \"%s ID\"\n", upper)
-		fmt.Fprintf(destination, "// @Success      200  {object}  responses.APIResponse\n")
-		fmt.Fprintf(destination, "// @Failure      404  {object}  responses.ProblemDetail\n")
-		fmt.Fprintf(destination, "// @Router       /%s/{id} [get]\n", plural)
-		fmt.Fprintf(destination, "func (c *%sController) Get(ctx *fiber.Ctx) error {\n", upper)
-		fmt.Fprintf(destination, "\tid, err := strconv.ParseUint(ctx.Params(\"id\"), 10, 32)\n")
-		fmt.Fprintf(destination, "\tif err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn responses.NewErrorResponse(ctx, err)\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\tdata, err := c.service.Get(uint(id))\n")
-		fmt.Fprintf(destination, "\tif err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn responses.NewErrorResponse(ctx, err)\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\treturn responses.NewSuccessResponse(ctx, \"%s retrieved successfully\", data)\n", upper)
-		fmt.Fprintf(destination, "}\n\n")
-
-		// Create - POST /resources
-		fmt.Fprintf(destination, "// Create creates a new %s.\n", filename)
-		fmt.Fprintf(destination, "// @Summary      Create a %s\n", filename)
-		fmt.Fprintf(destination, "// @Description  Create a new %s\n", filename)
-		fmt.Fprintf(destination, "// @Tags         %s\n", plural)
-		fmt.Fprintf(destination, "// @Accept       json\n")
-		fmt.Fprintf(destination, "// @Produce      json\n")
-		fmt.Fprintf(destination, "// @Param        request  body      requests.Create%sRequest  true  \"Create %s Request\"\n", upper, upper)
-		fmt.Fprintf(destination, "// @Success      201      {object}  responses.APIResponse\n")
-		fmt.Fprintf(destination, "// @Failure      422      {object}  responses.ProblemDetail\n")
-		fmt.Fprintf(destination, "// @Router       /%s [post]\n", plural)
-		fmt.Fprintf(destination, "func (c *%sController) Create(ctx *fiber.Ctx) error {\n", upper)
-		fmt.Fprintf(destination, "\tvar req requests.Create%sRequest\n", upper)
-		fmt.Fprintf(destination, "\tif err := ctx.BodyParser(&req); err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn responses.NewErrorResponse(ctx, err)\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\tif errMsg, err := validation.ValidateStruct(req); err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn responses.NewValidationError(ctx, []responses.ValidationError{{Field: \"validation\", Message: errMsg}})\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\tdata, err := c.service.Create(&req)\n")
-		fmt.Fprintf(destination, "\tif err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn responses.NewErrorResponse(ctx, err)\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\treturn responses.NewCreatedResponse(ctx, \"%s created successfully\", data)\n", upper)
-		fmt.Fprintf(destination, "}\n\n")
-
-		// Update - PUT /resources/:id
-		fmt.Fprintf(destination, "// Update modifies an existing %s.\n", filename)
-		fmt.Fprintf(destination, "// @Summary      Update a %s\n", filename)
-		fmt.Fprintf(destination, "// @Description  Update an existing %s by ID\n", filename)
-		fmt.Fprintf(destination, "// @Tags         %s\n", plural)
-		fmt.Fprintf(destination, "// @Accept       json\n")
-		fmt.Fprintf(destination, "// @Produce      json\n")
-		fmt.Fprintf(destination, "// @Param        id       path      int                        true  \"%s ID\"\n", upper)
-		fmt.Fprintf(destination, "// @Param        request  body      requests.Update%sRequest  true  \"Update %s Request\"\n", upper, upper)
-		fmt.Fprintf(destination, "// @Success      200      {object}  responses.APIResponse\n")
-		fmt.Fprintf(destination, "// @Failure      404      {object}  responses.ProblemDetail\n")
-		fmt.Fprintf(destination, "// @Failure      422      {object}  responses.ProblemDetail\n")
-		fmt.Fprintf(destination, "// @Router       /%s/{id} [put]\n", plural)
-		fmt.Fprintf(destination, "func (c *%sController) Update(ctx *fiber.Ctx) error {\n", upper)
-		fmt.Fprintf(destination, "\tid, err := strconv.ParseUint(ctx.Params(\"id\"), 10, 32)\n")
-		fmt.Fprintf(destination, "\tif err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn responses.NewErrorResponse(ctx, err)\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\tvar req requests.Update%sRequest\n", upper)
-		fmt.Fprintf(destination, "\tif err := ctx.BodyParser(&req); err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn responses.NewErrorResponse(ctx, err)\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\tif errMsg, err := validation.ValidateStruct(req); err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn responses.NewValidationError(ctx, []responses.ValidationError{{Field: \"validation\", Message: errMsg}})\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\tdata, err := c.service.Update(uint(id), &req)\n")
-		fmt.Fprintf(destination, "\tif err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn responses.NewErrorResponse(ctx, err)\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\treturn responses.NewSuccessResponse(ctx, \"%s updated successfully\", data)\n", upper)
-		fmt.Fprintf(destination, "}\n\n")
-
-		// Delete - DELETE /resources/:id
-		fmt.Fprintf(destination, "// Delete removes a %s by ID.\n", filename)
-		fmt.Fprintf(destination, "// @Summary      Delete a %s\n", filename)
-		fmt.Fprintf(destination, "// @Description  Delete a %s by its ID\n", filename)
-		fmt.Fprintf(destination, "// @Tags         %s\n", plural)
-		fmt.Fprintf(destination, "// @Accept       json\n")
-		fmt.Fprintf(destination, "// @Produce      json\n")
-		fmt.Fprintf(destination, "// @Param        id   path      int  true  \"%s ID\"\n", upper)
-		fmt.Fprintf(destination, "// @Success      204\n")
-		fmt.Fprintf(destination, "// @Failure      404  {object}  responses.ProblemDetail\n")
-		fmt.Fprintf(destination, "// @Router       /%s/{id} [delete]\n", plural)
-		fmt.Fprintf(destination, "func (c *%sController) Delete(ctx *fiber.Ctx) error {\n", upper)
-		fmt.Fprintf(destination, "\tid, err := strconv.ParseUint(ctx.Params(\"id\"), 10, 32)\n")
-		fmt.Fprintf(destination, "\tif err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn responses.NewErrorResponse(ctx, err)\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\tif err := c.service.Delete(uint(id)); err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn responses.NewErrorResponse(ctx, err)\n")
-		fmt.Fprintf(destination, "\t}\n")
-		fmt.Fprintf(destination, "\treturn responses.NewNoContentResponse(ctx)\n")
-		fmt.Fprintf(destination, "}\n")
-
-		fmt.Println("Created Controller successfully", file)
-	} else {
-		fmt.Println("File already exists!", file)
-	}
+func (g *Generator) createController(filename, projectName string, fields []Field, opts moduleOptions) error {
+	data := opts.moduleData(projectName, filename, fields)
+	return g.renderScaffold("templates/controller.tmpl", WORKDIR+"controllers/"+filename+"_controller.go", data)
 }
 
 // toPlural converts a singular word to plural (simple rules).
@@ -1592,14 +569,13 @@ func isVowel(b byte) bool {
 }
 
 // getProjectName reads the project name from the go.mod file
-func getProjectName() (string, error) {
-	file, err := os.Open("go.mod")
+func (g *Generator) getProjectName() (string, error) {
+	content, err := g.readFile("go.mod")
 	if err != nil {
 		return "", err
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
 	if scanner.Scan() {
 		line := scanner.Text()
 		if strings.HasPrefix(line, "module") {
@@ -1614,125 +590,83 @@ func getProjectName() (string, error) {
 }
 
 // ResolveProjectName returns the module path from go.mod in current directory.
-func ResolveProjectName() (string, error) {
-	return getProjectName()
-}
-
-func CreateExampleConfig(projectName string) {
-	pathFolder := "./"
-	if _, err := os.Stat(pathFolder); errors.Is(err, os.ErrNotExist) {
-		err := os.Mkdir(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-		}
-	}
-
-	path := pathFolder
-	file := path + "example.config.yaml"
-	var _, err = os.Stat(file)
-
-	if os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer destination.Close()
-
-		// Write the example config
-		fmt.Fprintf(destination, "app:\n")
-		fmt.Fprintf(destination, "  name: \"%s\"  # ECS service.name for Elastic/Kibana\n", projectName)
-		fmt.Fprintf(destination, "  port: 8080\n")
-		fmt.Fprintf(destination, "\n")
-		fmt.Fprintf(destination, "secrete:\n")
-		fmt.Fprintf(destination, "  jwt: \"secrete\"\n")
-		fmt.Fprintf(destination, "\n")
-		fmt.Fprintf(destination, "postgres:\n")
-		fmt.Fprintf(destination, "  host: localhost\n")
-		fmt.Fprintf(destination, "  port: 5432\n")
-		fmt.Fprintf(destination, "  user: postgres\n")
-		fmt.Fprintf(destination, "  password: postgres\n")
-		fmt.Fprintf(destination, "  database: postgresdb\n")
-		fmt.Fprintf(destination, "\n")
-		fmt.Fprintf(destination, "redis:\n")
-		fmt.Fprintf(destination, "  host: localhost\n")
-		fmt.Fprintf(destination, "  port: 6379\n")
-
-		fmt.Println("Created Example Config successfully", file)
-	} else {
-		fmt.Println("File already exists!", file)
-		return
-	}
-}
-
-type moduleTemplateData struct {
-	ProjectName    string
-	ModuleName     string
-	ServiceName    string
-	RepositoryName string
-	ModelName      string
+func (g *Generator) ResolveProjectName() (string, error) {
+	return g.getProjectName()
 }
 
-type renderResult struct {
-	created bool
+func (g *Generator) CreateExampleConfig(projectName string) error {
+	return g.createExampleConfig(newProjectData(projectName))
 }
 
-type autoServiceTemplateData struct {
-	ProjectName string
-	ModuleName  string
-	ModelName   string
-	HasList     bool
-	HasGet      bool
-	HasCreate   bool
-	HasUpdate   bool
-	HasDelete   bool
+func (g *Generator) createExampleConfig(data templateData) error {
+	return g.renderScaffold("templates/example_config.tmpl", "example.config.yaml", data)
 }
 
 // GenerateTestFiles creates tests/services, tests/mocks, tests/fixtures and
 // renders module test files from template files.
-func GenerateTestFiles(moduleName, projectName string) error {
-	return generateTestFiles(moduleName, projectName, false)
+func (g *Generator) GenerateTestFiles(moduleName, projectName string) error {
+	return g.transaction(func() error {
+		return g.generateTestFiles(moduleName, projectName, nil, moduleOptions{}, false)
+	})
 }
 
-func generateTestFiles(moduleName, projectName string, force bool) error {
+// generateTestFiles renders the module test files. When fields is nil they are
+// read from the module's model file, and the filters from its requests.
+func (g *Generator) generateTestFiles(moduleName, projectName string, fields []Field, opts moduleOptions, force bool) error {
 	moduleName = strings.ToLower(strings.TrimSpace(moduleName))
 	if moduleName == "" {
 		return errors.New("module name must not be empty")
 	}
-
-	testDirs := []string{
-		"tests/services",
-		"tests/mocks",
-		"tests/fixtures",
+	if fields == nil {
+		fields = g.resolveModuleFields(moduleName)
+		opts.Filters = g.detectFilters(moduleName)
+		opts.ID, _ = g.detectModelID(moduleName)
+		opts.Pagination = g.detectPagination(moduleName)
 	}
-	for _, dir := range testDirs {
-		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-			return fmt.Errorf("failed to create test directory %s: %w", dir, err)
-		}
+
+	outputFiles := append([]testFile{{
+		templatePath: "templates/service_test.tmpl",
+		outputPath:   filepath.Join("tests/services", moduleName+"_service_test.go"),
+	}}, testSupportFiles(moduleName)...)
+
+	createdCount, keptCount, err := g.renderTestFiles(outputFiles, opts.moduleData(projectName, moduleName, fields), force)
+	if err != nil {
+		return err
 	}
 
-	pascalModuleName := strings.Replace(
-		cases.Title(language.Und, cases.NoLower).String(strings.ReplaceAll(moduleName, "_", " ")),
-		" ",
-		"",
-		-1,
-	)
-	data := moduleTemplateData{
-		ProjectName:    projectName,
-		ModuleName:     moduleName,
-		ServiceName:    pascalModuleName + "Service",
-		RepositoryName: pascalModuleName + "Repository",
-		ModelName:      pascalModuleName,
+	skippedCount := len(outputFiles) - createdCount
+	if g.DryRun || g.regenerate {
+		return nil
+	}
+	switch {
+	case createdCount == 0 && keptCount > 0:
+		fmt.Printf("No test files changed for module %s; kept %d edited file(s).\n", moduleName, keptCount)
+		return nil
+	case createdCount == 0 && force:
+		fmt.Printf("No test files changed for module %s; they are up to date.\n", moduleName)
+		return nil
+	case createdCount == 0:
+		fmt.Printf("No test files created for module %s. All files already exist.\n", moduleName)
+		fmt.Printf("Use force mode to regenerate: go-gen-r test %s --force\n", moduleName)
+		return nil
+	case skippedCount > 0:
+		fmt.Printf("Created %d test file(s) for module %s, skipped %d existing file(s).\n", createdCount, moduleName, skippedCount)
+		return nil
 	}
 
-	outputFiles := []struct {
-		templatePath string
-		outputPath   string
-	}{
-		{
-			templatePath: "templates/service_test.tmpl",
-			outputPath:   filepath.Join("tests/services", moduleName+"_service_test.go"),
-		},
+	fmt.Printf("Created all test files successfully for module: %s\n", moduleName)
+	return nil
+}
+
+// testFile is a test file rendered from a template.
+type testFile struct {
+	templatePath string
+	outputPath   string
+}
+
+// testSupportFiles returns the repository mock and fixture of a module.
+func testSupportFiles(moduleName string) []testFile {
+	return []testFile{
 		{
 			templatePath: "templates/mock_repository.tmpl",
 			outputPath:   filepath.Join("tests/mocks", moduleName+"_repository_mock.go"),
@@ -1742,53 +676,48 @@ func generateTestFiles(moduleName, projectName string, force bool) error {
 			outputPath:   filepath.Join("tests/fixtures", moduleName+"_fixture.go"),
 		},
 	}
+}
 
-	createdCount := 0
-	for _, file := range outputFiles {
-		result, err := renderTemplateToFile(file.templatePath, file.outputPath, data, force)
+// renderTestFiles renders files with data and returns how many were created
+// or changed, and how many were kept because overwriting them was declined.
+func (g *Generator) renderTestFiles(files []testFile, data templateData, force bool) (created, kept int, err error) {
+	for _, file := range files {
+		result, err := g.renderTemplateToFile(file.templatePath, file.outputPath, data, force)
 		if err != nil {
-			return err
+			return created, kept, err
 		}
 		if result.created {
-			createdCount++
+			created++
+		}
+		if result.kept {
+			kept++
 		}
 	}
-
-	skippedCount := len(outputFiles) - createdCount
-	if createdCount == 0 {
-		fmt.Printf("No test files created for module %s. All files already exist.\n", moduleName)
-		fmt.Printf("Use force mode to regenerate: go-gen-r test %s --force\n", moduleName)
-		return nil
-	}
-
-	if skippedCount > 0 {
-		fmt.Printf("Created %d test file(s) for module %s, skipped %d existing file(s).\n", createdCount, moduleName, skippedCount)
-		return nil
-	}
-
-	fmt.Printf("Created all test files successfully for module: %s\n", moduleName)
-	return nil
+	return created, kept, nil
 }
 
 // GenerateTestFilesForce recreates test scaffolding files for a module.
-func GenerateTestFilesForce(moduleName, projectName string) error {
-	return generateTestFiles(moduleName, projectName, true)
+func (g *Generator) GenerateTestFilesForce(moduleName, projectName string) error {
+	return g.transaction(func() error {
+		return g.generateTestFiles(moduleName, projectName, nil, moduleOptions{}, true)
+	})
 }
 
 // GenerateAutoServiceTests regenerates the module service test based on current
 // service methods. It also ensures mocks/fixtures exist.
-func GenerateAutoServiceTests(moduleName, projectName string, force bool) error {
+func (g *Generator) GenerateAutoServiceTests(moduleName, projectName string, force bool) error {
+	return g.transaction(func() error {
+		return g.generateAutoServiceTests(moduleName, projectName, force)
+	})
+}
+
+func (g *Generator) generateAutoServiceTests(moduleName, projectName string, force bool) error {
 	moduleName = strings.ToLower(strings.TrimSpace(moduleName))
 	if moduleName == "" {
 		return errors.New("module name must not be empty")
 	}
 
-	// Ensure fixtures and mocks are present (service test can be regenerated).
-	if err := generateTestFiles(moduleName, projectName, force); err != nil {
-		return err
-	}
-
-	methods, err := detectServiceMethods(moduleName)
+	methods, err := g.detectServiceMethods(moduleName)
 	if err != nil {
 		return err
 	}
@@ -1797,37 +726,41 @@ func GenerateAutoServiceTests(moduleName, projectName string, force bool) error
 		return fmt.Errorf("no service methods found in internal/services/%s_service.go", moduleName)
 	}
 
-	modelName := strings.Replace(
-		cases.Title(language.Und, cases.NoLower).String(strings.ReplaceAll(moduleName, "_", " ")),
-		" ",
-		"",
-		-1,
-	)
-
-	data := autoServiceTemplateData{
-		ProjectName: projectName,
-		ModuleName:  moduleName,
-		ModelName:   modelName,
-		HasList:     methods["List"],
-		HasGet:      methods["Get"],
-		HasCreate:   methods["Create"],
-		HasUpdate:   methods["Update"],
-		HasDelete:   methods["Delete"],
+	// Ensure fixtures and mocks are present (regenerated with force); the
+	// service test itself is always regenerated below.
+	id, _ := g.detectModelID(moduleName)
+	data := moduleOptions{Filters: g.detectFilters(moduleName), ID: id, Pagination: g.detectPagination(moduleName)}.moduleData(projectName, moduleName, g.resolveModuleFields(moduleName))
+	if _, _, err := g.renderTestFiles(testSupportFiles(moduleName), data, force); err != nil {
+		return err
 	}
 
+	data.HasList = methods["List"]
+	data.HasGet = methods["Get"]
+	data.HasCreate = methods["Create"]
+	data.HasUpdate = methods["Update"]
+	data.HasDelete = methods["Delete"]
+
 	outputPath := filepath.Join("tests/services", moduleName+"_service_test.go")
-	if _, err := renderTemplateToFile("templates/auto_service_test.tmpl", outputPath, data, true); err != nil {
+	result, err := g.renderTemplateToFile("templates/auto_service_test.tmpl", outputPath, data, true)
+	if err != nil {
 		return err
 	}
 
+	if g.DryRun || !result.created {
+		return nil
+	}
 	fmt.Printf("Auto-generated service test successfully for module: %s\n", moduleName)
 	return nil
 }
 
-func detectServiceMethods(moduleName string) (map[string]bool, error) {
+func (g *Generator) detectServiceMethods(moduleName string) (map[string]bool, error) {
 	servicePath := filepath.Join("internal/services", moduleName+"_service.go")
+	src, err := g.readFile(servicePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse service file %s: %w", servicePath, err)
+	}
 	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, servicePath, nil, 0)
+	node, err := parser.ParseFile(fset, servicePath, src, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse service file %s: %w", servicePath, err)
 	}
@@ -1856,139 +789,73 @@ func detectServiceMethods(moduleName string) (map[string]bool, error) {
 
 // CreateTestsStructure keeps backwards compatibility and delegates to
 // GenerateTestFiles.
-func CreateTestsStructure(moduleName string, projectName string) {
-	if err := GenerateTestFiles(moduleName, projectName); err != nil {
-		fmt.Println("Error creating test structure:", err)
-	}
+func (g *Generator) CreateTestsStructure(moduleName string, projectName string) error {
+	return g.GenerateTestFiles(moduleName, projectName)
 }
 
-func renderTemplateToFile(templatePath, outputPath string, data interface{}, force bool) (renderResult, error) {
-	if _, err := os.Stat(outputPath); err == nil && !force {
-		fmt.Println("File already exists:", outputPath)
-		return renderResult{created: false}, nil
-	}
+func (g *Generator) CreateMiddleware(projectName string) error {
+	return g.renderScaffold("templates/middleware_logging.tmpl", "middleware/logging.go", newProjectData(projectName))
+}
 
-	rawTemplate, err := templatesFS.ReadFile(templatePath)
-	if err != nil {
-		return renderResult{}, fmt.Errorf("failed to read template %s: %w", templatePath, err)
-	}
+// CreateEmptyMigrations creates migrations/migrations.go without models, for
+// projects initialized without the example module.
+func (g *Generator) CreateEmptyMigrations() error {
+	data := newProjectData("")
+	data.MigrationImports = []string{"gorm.io/gorm"}
+	return g.renderScaffold("templates/migrations.tmpl", migrationsFile, data)
+}
 
-	tmpl, err := template.New(filepath.Base(templatePath)).Parse(string(rawTemplate))
-	if err != nil {
-		return renderResult{}, fmt.Errorf("failed to parse template %s: %w", templatePath, err)
-	}
+func (g *Generator) CreateMigrations(filename string, projectName string) error {
+	// Format model name: "user_account" -> "UserAccount"
+	modelName := toPascal(filename)
 
-	var buffer bytes.Buffer
-	if err := tmpl.Execute(&buffer, data); err != nil {
-		return renderResult{}, fmt.Errorf("failed to render template %s: %w", templatePath, err)
-	}
+	// Build import and model reference
+	importPath := fmt.Sprintf("%s/internal/%s", projectName, "models")
+	modelLine := fmt.Sprintf("&%s.%s{}", "models", modelName)
 
-	if err := os.WriteFile(outputPath, buffer.Bytes(), 0644); err != nil {
-		return renderResult{}, fmt.Errorf("failed to write file %s: %w", outputPath, err)
-	}
+	migrations, exists := g.readMigrations()
 
-	if force {
-		fmt.Println("Regenerated file:", outputPath)
-	} else {
-		fmt.Println("Created file:", outputPath)
-	}
-	return renderResult{created: true}, nil
-}
+	// Add new entries
+	migrations.imports[importPath] = struct{}{}
+	migrations.models[modelLine] = struct{}{}
 
-func CreateMiddleware(projectName string) {
-	pathFolder := "middleware"
-	if _, err := os.Stat(pathFolder); os.IsNotExist(err) {
-		err := os.Mkdir(pathFolder, os.ModePerm)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
+	if err := g.writeMigrations(projectName, migrations, exists); err != nil {
+		return err
 	}
-
-	file := pathFolder + "/logging.go"
-	if _, err := os.Stat(file); os.IsNotExist(err) {
-		destination, err := os.Create(file)
-		if err != nil {
-			fmt.Println(err)
-			return
-		}
-		defer destination.Close()
-
-		fmt.Fprintf(destination, "package middleware\n\n")
-
-		fmt.Fprintf(destination, "import (\n")
-		fmt.Fprintf(destination, "\t\"%s/logs\"\n", projectName)
-		fmt.Fprintf(destination, "\t\"time\"\n")
-		fmt.Fprintf(destination, "\n")
-		fmt.Fprintf(destination, "\t\"github.com/gofiber/fiber/v2\"\n")
-		fmt.Fprintf(destination, "\t\"go.uber.org/zap\"\n")
-		fmt.Fprintf(destination, "\t\"go.uber.org/zap/zapcore\"\n")
-		fmt.Fprintf(destination, ")\n\n")
-
-		fmt.Fprintf(destination, "func LogInfo(ctx *fiber.Ctx) error {\n")
-		fmt.Fprintf(destination, "\tbeginTime := time.Now()\n")
-		fmt.Fprintf(destination, "\treqHeader := ctx.GetReqHeaders()\n")
-		fmt.Fprintf(destination, "\treqPath := ctx.OriginalURL()\n\n")
-
-		fmt.Fprintf(destination, "\tif err := ctx.Next(); err != nil {\n")
-		fmt.Fprintf(destination, "\t\treturn err\n")
-		fmt.Fprintf(destination, "\t}\n\n")
-
-		fmt.Fprintf(destination, "\tlatency := time.Since(beginTime)\n")
-		fmt.Fprintf(destination, "\tresHeader := ctx.GetRespHeaders()\n\n")
-
-		fmt.Fprintf(destination, "\tlogs.Info(\n")
-		fmt.Fprintf(destination, "\t\t\"\",\n")
-		fmt.Fprintf(destination, "\t\tctx.Context(),\n")
-		fmt.Fprintf(destination, "\t\tzapcore.Field{\n")
-		fmt.Fprintf(destination, "\t\t\tKey:    \"latency\",\n")
-		fmt.Fprintf(destination, "\t\t\tType:   zapcore.DurationType,\n")
-		fmt.Fprintf(destination, "\t\t\tString: latency.String(),\n")
-		fmt.Fprintf(destination, "\t\t},\n")
-		fmt.Fprintf(destination, "\t\tzap.Any(\"req_header\", reqHeader),\n")
-		fmt.Fprintf(destination, "\t\tzap.Any(\"req_path\", reqPath),\n")
-		fmt.Fprintf(destination, "\t\tzap.Any(\"res_header\", resHeader),\n")
-		fmt.Fprintf(destination, "\t\tzap.Any(\"requester_ip\", ctx.IP()),\n")
-		fmt.Fprintf(destination, "\t)\n\n")
-
-		fmt.Fprintf(destination, "\treturn nil\n")
-		fmt.Fprintf(destination, "}\n")
-
-		fmt.Println("Created logging.go successfully:", file)
-	} else {
-		fmt.Println("File already exists!", file)
+	if g.DryRun {
+		return nil
 	}
-}
 
-func CreateMigrations(filename string, projectName string) {
-	migrationDir := "migrations/"
-	filePath := migrationDir + "migrations.go"
+	fmt.Println("migrations/migrations.go updated with model:", modelLine)
+	return nil
+}
 
-	// Ensure migrations directory exists
-	if _, err := os.Stat(migrationDir); os.IsNotExist(err) {
-		err := os.MkdirAll(migrationDir, os.ModePerm)
-		if err != nil {
-			fmt.Println("Failed to create migrations directory:", err)
-			return
-		}
-	}
+// migrationSet holds the entries of migrations.go.
+type migrationSet struct {
+	imports map[string]struct{}
+	models  map[string]struct{}
+	renames []tableRename // applied in order before AutoMigrate
+}
 
-	// Format model name: "user_account" -> "UserAccount"
-	modelName := strings.Replace(
-		cases.Title(language.Und, cases.NoLower).String(strings.ReplaceAll(filename, "_", " ")),
-		" ", "", -1,
-	)
+// tableRename renames a table left behind by a renamed module.
+type tableRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
 
-	// Build import and model reference
-	importPath := fmt.Sprintf("%s/internal/%s", projectName, "models")
-	modelLine := fmt.Sprintf("&%s.%s{}", "models", modelName)
+// renameTableCall matches the RenameTable call of a migrations.tmpl rename.
+var renameTableCall = regexp.MustCompile(`RenameTable\("([^"]*)", "([^"]*)"\)`)
 
+// readMigrations returns the entries of migrations.go and whether it exists.
+func (g *Generator) readMigrations() (migrations migrationSet, exists bool) {
 	// Maps for uniqueness
-	imports := map[string]struct{}{"gorm.io/gorm": {}} // pre-add gorm
-	models := map[string]struct{}{}
+	migrations.imports = map[string]struct{}{"gorm.io/gorm": {}} // pre-add gorm
+	migrations.models = map[string]struct{}{}
 
 	// Parse existing file if present
-	if content, err := os.ReadFile(filePath); err == nil {
+	content, err := g.readFile(migrationsFile)
+	exists = err == nil
+	if exists {
 		lines := strings.Split(string(content), "\n")
 		inImport := false
 		inMigrate := false
@@ -2007,7 +874,7 @@ func CreateMigrations(filename string, projectName string) {
 				}
 				trim = strings.Trim(trim, `"`)
 				if trim != "" {
-					imports[trim] = struct{}{}
+					migrations.imports[trim] = struct{}{}
 				}
 				continue
 			}
@@ -2016,51 +883,53 @@ func CreateMigrations(filename string, projectName string) {
 				inMigrate = true
 				continue
 			}
-			if inMigrate && strings.HasPrefix(trim, "&") {
-				models[strings.TrimRight(trim, ",")] = struct{}{}
+			if !inMigrate {
+				continue
+			}
+			if strings.HasPrefix(trim, "&") {
+				migrations.models[strings.TrimRight(trim, ",")] = struct{}{}
+			}
+			if m := renameTableCall.FindStringSubmatch(trim); m != nil {
+				migrations.renames = append(migrations.renames, tableRename{From: m[1], To: m[2]})
 			}
 		}
 	}
+	return migrations, exists
+}
 
-	// Add new entries
-	imports[importPath] = struct{}{}
-	models[modelLine] = struct{}{}
-
-	// Sort imports and models
-	importList := make([]string, 0, len(imports))
-	for imp := range imports {
+// writeMigrations renders migrations.go with the given entries.
+func (g *Generator) writeMigrations(projectName string, migrations migrationSet, exists bool) error {
+	// Sort imports and models; parents migrate before the models referencing them
+	importList := make([]string, 0, len(migrations.imports))
+	for imp := range migrations.imports {
 		importList = append(importList, imp)
 	}
 	sort.Strings(importList)
 
-	modelList := make([]string, 0, len(models))
-	for model := range models {
+	modelList := make([]string, 0, len(migrations.models))
+	for model := range migrations.models {
 		modelList = append(modelList, model)
 	}
 	sort.Strings(modelList)
+	modelList = g.orderMigrations(modelList)
 
-	// Build output
-	var builder strings.Builder
-	fmt.Fprintf(&builder, "package migrations\n\n")
-	fmt.Fprintf(&builder, "import (\n")
-	for _, imp := range importList {
-		fmt.Fprintf(&builder, "\t\"%s\"\n", imp)
+	data := newProjectData(projectName)
+	data.MigrationImports = importList
+	data.MigrationModels = modelList
+	data.MigrationRenames = migrations.renames
+	out, err := g.renderFile("templates/migrations.tmpl", migrationsFile, data)
+	if err == nil {
+		action := ActionCreate
+		if exists {
+			action = ActionPatch
+		}
+		err = g.writeFile(migrationsFile, out, action)
 	}
-	fmt.Fprintf(&builder, ")\n\n")
-
-	fmt.Fprintf(&builder, "func MigrateAll(db *gorm.DB) error {\n")
-	fmt.Fprintf(&builder, "\treturn db.AutoMigrate(\n")
-	for _, model := range modelList {
-		fmt.Fprintf(&builder, "\t\t%s,\n", model)
+	if err == nil {
+		err = g.recordFile(migrationsFile, "templates/migrations.tmpl", data, out)
 	}
-	fmt.Fprintf(&builder, "\t)\n")
-	fmt.Fprintf(&builder, "}\n")
-
-	// Write to file
-	if err := os.WriteFile(filePath, []byte(builder.String()), 0644); err != nil {
-		fmt.Println("Failed to write migrations.go:", err)
-		return
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", migrationsFile, err)
 	}
-
-	fmt.Println("migrations/migrations.go updated with model:", modelLine)
+	return nil
 }
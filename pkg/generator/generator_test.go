@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -80,7 +81,7 @@ func TestGetProjectName(t *testing.T) {
 	}
 
 	// No go.mod
-	_, err := getProjectName()
+	_, err := std.getProjectName()
 	if err == nil {
 		t.Error("getProjectName with no go.mod expected error, got nil")
 	}
@@ -89,7 +90,7 @@ func TestGetProjectName(t *testing.T) {
 	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/myapp\n\ngo 1.21\n"), 0644); err != nil {
 		t.Fatal(err)
 	}
-	got, err := getProjectName()
+	got, err := std.getProjectName()
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -136,6 +137,109 @@ func TestGenerateModule(t *testing.T) {
 	}
 }
 
+func TestGenerateModule_FailedStepLeavesProjectUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/proj\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := New(dir, Options{}).GenerateInitialStructure("example.com/proj"); err != nil {
+		t.Fatal(err)
+	}
+	before := snapshotDir(t, dir)
+
+	// A broken service test template fails after the model, repository and
+	// service were rendered, and before migrations.go is rewritten.
+	templates := t.TempDir()
+	if err := os.WriteFile(filepath.Join(templates, "service_test.tmpl"), []byte("{{.Missing"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g := New(dir, Options{TemplateDir: templates})
+	err := g.GenerateModule("product")
+	if err == nil || !strings.Contains(err.Error(), "service_test.tmpl") {
+		t.Fatalf("GenerateModule() = %v, want an error naming service_test.tmpl", err)
+	}
+
+	after := snapshotDir(t, dir)
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			t.Errorf("%s was created by a failed generation", name)
+		}
+	}
+	for name, content := range before {
+		if after[name] != content {
+			t.Errorf("%s was changed by a failed generation", name)
+		}
+	}
+
+	// Fixing the template is enough to rerun without cleaning up.
+	if err := os.Remove(filepath.Join(templates, "service_test.tmpl")); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.GenerateModule("product"); err != nil {
+		t.Fatalf("rerun after fixing the template: %v", err)
+	}
+}
+
+func TestInitWithOptions_InvalidDatabase(t *testing.T) {
+	err := InitWithOptions(InitOptions{ProjectName: "example.com/app", Database: "oracle"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported database") {
+		t.Errorf("InitWithOptions error = %v, want unsupported database", err)
+	}
+}
+
+func TestGenerateInitialStructure_Options(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("go.mod", []byte("module example.com/app\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := std.generateInitialStructure(InitOptions{ProjectName: "example.com/app", Database: "mysql", SkipExample: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rel := range []string{"internal/models/example.go", "database/postgres.go"} {
+		if _, err := os.Stat(rel); err == nil {
+			t.Errorf("%s was generated", rel)
+		}
+	}
+	mainSrc, _ := os.ReadFile("main.go")
+	if strings.Contains(string(mainSrc), "exampleController") || !strings.Contains(string(mainSrc), "database.MysqlConnection()") {
+		t.Errorf("main.go does not match a mysql project without example:\n%s", mainSrc)
+	}
+	if config, _ := os.ReadFile("example.config.yaml"); !strings.Contains(string(config), "mysql:\n  host: localhost\n  port: 3306") {
+		t.Errorf("example.config.yaml has no mysql section:\n%s", config)
+	}
+
+	// The first module is wired into the empty main.go and routes.
+	if err := GenerateModule("order"); err != nil {
+		t.Fatal(err)
+	}
+	mainSrc, _ = os.ReadFile("main.go")
+	routesSrc, _ := os.ReadFile("routes/fiber_routes.go")
+	checks := []struct {
+		src     []byte
+		snippet string
+		want    int
+	}{
+		{mainSrc, "\"example.com/app/internal/controllers\"", 1},
+		{mainSrc, "orderController := controllers.NewOrderController(orderService)\n\n\t// Register routes", 1},
+		{mainSrc, "routes.NewFiberRoutes(orderController)", 1},
+		{routesSrc, "\"example.com/app/internal/controllers\"", 1},
+		{routesSrc, "r.orderController.RegisterRoutes(v1)", 1},
+		{routesSrc, "_ = v1", 0},
+	}
+	for _, c := range checks {
+		if n := strings.Count(string(c.src), c.snippet); n != c.want {
+			t.Errorf("found %q %d times, want %d", c.snippet, n, c.want)
+		}
+	}
+}
+
 func ExampleInit() {
 	// Init validates the project name and returns an error for invalid input.
 	// Run from an empty directory to actually create a project.
@@ -163,3 +267,46 @@ func ExampleGenerateModule() {
 	}
 	// Example runs without output check (generator prints to stdout).
 }
+
+func TestGenerator_Root(t *testing.T) {
+	cwd, _ := os.Getwd()
+	roots := []string{t.TempDir(), t.TempDir()}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(roots))
+	for i, root := range roots {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n\ngo 1.21\n"), 0644); err != nil {
+				errs[i] = err
+				return
+			}
+			g := New(root, Options{})
+			if err := g.GenerateInitialStructure("example.com/app"); err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = g.GenerateModule("order", "total:decimal")
+		}()
+	}
+	wg.Wait()
+
+	for i, root := range roots {
+		if errs[i] != nil {
+			t.Fatalf("generating into %s: %v", root, errs[i])
+		}
+		for _, rel := range []string{"main.go", "internal/models/order.go", "tests/fixtures/order_fixture.go"} {
+			if _, err := os.Stat(filepath.Join(root, rel)); err != nil {
+				t.Errorf("expected %s in %s: %v", rel, root, err)
+			}
+		}
+		mainSrc, _ := os.ReadFile(filepath.Join(root, "main.go"))
+		if !strings.Contains(string(mainSrc), "routes.NewFiberRoutes(exampleController, orderController)") {
+			t.Errorf("order module is not registered in %s/main.go", root)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(cwd, "main.go")); err == nil {
+		t.Error("generator wrote main.go into the working directory")
+	}
+}
@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// idTypes lists the primary key types a module can be generated with; the
+// first is the default.
+var idTypes = []string{"uint", "int64", "uuid", "ulid"}
+
+// IDTypes returns the names accepted as a module's ID type: "uint" (auto
+// increment, the default), "int64" (snowflake IDs), "uuid" (version 7 UUIDs,
+// PostgreSQL) and "ulid".
+func IDTypes() []string {
+	return slices.Clone(idTypes)
+}
+
+// normalizeID returns the recorded form of the ID type id, which is empty
+// for the default uint.
+func normalizeID(id string) string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if id == idTypes[0] {
+		return ""
+	}
+	return id
+}
+
+// validateID returns an error when id is not one of IDTypes.
+func validateID(id string) error {
+	if id != "" && !slices.Contains(idTypes, strings.ToLower(strings.TrimSpace(id))) {
+		return fmt.Errorf("unsupported id type %q (use one of: %s)", id, strings.Join(idTypes, ", "))
+	}
+	return nil
+}
+
+// idSpecTypes maps a normalized ID type to the field type of a foreign key
+// referencing it.
+var idSpecTypes = map[string]string{
+	"":      "uint",
+	"int64": "int64",
+	"uuid":  "uuid",
+	"ulid":  "ulid",
+}
+
+// idOfSpecType returns the ID type referenced by a foreign key of field type
+// typeName; see idSpecTypes.
+func idOfSpecType(typeName string) string {
+	for id, specType := range idSpecTypes {
+		if specType == typeName {
+			return id
+		}
+	}
+	return ""
+}
+
+// idGoType returns the Go type of the ID type id.
+func idGoType(id string) string {
+	return fieldTypes[idSpecTypes[normalizeID(id)]].goType
+}
+
+// modelIDPattern matches the ID field of a generated model.
+var modelIDPattern = regexp.MustCompile("(?m)^\\s*ID\\s+(\\S+)\\s+`([^`]*)`")
+
+// detectModelID returns the ID type of the generated model of moduleName,
+// empty for uint. It reports false when the model does not exist.
+func (g *Generator) detectModelID(moduleName string) (string, bool) {
+	content, err := g.readFile(fmt.Sprintf("internal/models/%s.go", moduleName))
+	if err != nil {
+		return "", false
+	}
+	m := modelIDPattern.FindStringSubmatch(string(content))
+	switch {
+	case m == nil:
+		return "", true
+	case m[1] == "int64":
+		return "int64", true
+	case m[1] == "string" && strings.Contains(m[2], "type:uuid"):
+		return "uuid", true
+	case m[1] == "string":
+		return "ulid", true
+	}
+	return "", true
+}
+
+// withRelationIDs returns fields with the foreign keys and many2many IDs
+// typed after the ID of the module they reference: id for moduleName
+// itself, then declared (e.g. the modules of a schema), then the generated
+// model. Fields referencing none of these are kept.
+func (g *Generator) withRelationIDs(moduleName, id string, fields []Field, declared map[string]string) []Field {
+	result := slices.Clone(fields)
+	for i, f := range fields {
+		if f.Relation == "" || f.Relation == "has_many" {
+			continue
+		}
+		modelID, ok := declared[f.Model]
+		switch {
+		case f.Model == moduleName:
+			modelID, ok = id, true
+		case !ok:
+			modelID, ok = g.detectModelID(f.Model)
+		}
+		if ok {
+			result[i] = f.withModelID(modelID)
+		}
+	}
+	return result
+}
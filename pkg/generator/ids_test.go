@@ -0,0 +1,137 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateModuleWithID(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	modules := []struct {
+		name, id string
+		fields   []string
+	}{
+		{"customer", "uuid", []string{"name:string"}},
+		{"tag", "ulid", []string{"label:string"}},
+		{"order", "int64", []string{"customer:belongs_to", "tags:many2many"}},
+		{"note", "", []string{"order:belongs_to"}},
+	}
+	for _, m := range modules {
+		if err := g.GenerateModuleWithID(m.name, m.id, m.fields...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := map[string][]string{
+		"internal/models/customer.go": {
+			"ID        string         `json:\"id\" gorm:\"type:uuid;primaryKey;default:gen_random_uuid()\"`",
+			"m.ID = ids.NewUUID()",
+		},
+		"internal/models/tag.go": {
+			"ID        string         `json:\"id\" gorm:\"type:char(26);primaryKey\"`",
+			"m.ID = ids.NewULID()",
+		},
+		"internal/models/order.go": {
+			"ID         int64          `json:\"id\" gorm:\"primaryKey;autoIncrement:false\"`",
+			"CustomerID string         `json:\"customer_id\" gorm:\"type:uuid;not null;index\"`",
+			"if m.ID == 0 {\n\t\tm.ID = ids.NewSnowflake()",
+		},
+		"internal/models/note.go": {
+			"OrderID   int64          `json:\"order_id\" gorm:\"not null;index\"`",
+		},
+		"internal/requests/order_request.go": {
+			"CustomerID string   `json:\"customer_id\" validate:\"required,uuid\"`",
+			"TagIDs     []string `json:\"tag_ids\"`",
+		},
+		"internal/responses/customer_response.go": {"ID        string    `json:\"id\"`"},
+		"internal/repositories/customer_repository.go": {
+			"FindByID(id string) (*models.Customer, error)",
+			`r.db.First(&entity, "id = ?", id)`,
+			`r.db.Delete(&models.Customer{}, "id = ?", id)`,
+		},
+		"internal/repositories/order_repository.go": {
+			"r.db.Preload(clause.Associations).First(&entity, id)",
+		},
+		"internal/services/tag_service.go": {"Update(id string, req *requests.UpdateTagRequest)"},
+		"internal/controllers/customer_controller.go": {
+			`id, err := ids.ParseUUID(ctx.Params("id"))`,
+			"data, err := c.service.Get(id)",
+			`// @Param        id   path      string  true  "Customer ID"`,
+		},
+		"internal/controllers/tag_controller.go":   {`id, err := ids.ParseULID(ctx.Params("id"))`},
+		"internal/controllers/order_controller.go": {`id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)`},
+		"internal/controllers/note_controller.go":  {"c.service.Get(uint(id))"},
+		"tests/mocks/tag_repository_mock.go":       {"func (m *TagRepositoryMock) Delete(id string) error"},
+		"ids/ids.go":                               {"func NewSnowflake() int64", `"example.com/app/errs"`},
+	}
+	for name, wants := range files {
+		content := mustRead(t, mem, name)
+		for _, want := range wants {
+			if !strings.Contains(content, want) {
+				t.Errorf("%s does not contain %s:\n%s", name, want, content)
+			}
+		}
+	}
+	if model := mustRead(t, mem, "internal/models/note.go"); strings.Contains(model, "BeforeCreate") {
+		t.Errorf("uint model sets its ID:\n%s", model)
+	}
+
+	// The ID types are read back from the models.
+	if err := g.GenerateAutoServiceTests("customer", "example.com/app", true); err != nil {
+		t.Fatal(err)
+	}
+	test := mustRead(t, mem, "tests/services/customer_service_test.go")
+	if want := `repo.On("FindByID", "0190a6f2-3c4d-7e5f-8a9b-0c1d2e3f4a5b")`; !strings.Contains(test, want) {
+		t.Errorf("service test does not contain %s:\n%s", want, test)
+	}
+	fields := g.resolveModuleFields("order")
+	if got := fields[0].GoType + " " + fields[1].IDsGoName(); got != "string TagIDs" || fields[1].ModelID != "ulid" {
+		t.Errorf("resolveModuleFields() = %+v", fields)
+	}
+	result, err := g.Upgrade()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Upgraded) > 0 || len(result.Conflicts) > 0 {
+		t.Errorf("Upgrade() changed files: %+v", result)
+	}
+
+	err = g.GenerateModuleWithID("invoice", "serial")
+	if want := `unsupported id type "serial" (use one of: uint, int64, uuid, ulid)`; err == nil || err.Error() != want {
+		t.Errorf("GenerateModuleWithID() error = %v, want %q", err, want)
+	}
+}
+
+func TestApply_IDs(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	// The foreign key follows the declared ID of a module generated later.
+	schema, err := ParseSchema([]byte(`modules:
+  - name: order
+    fields:
+      - {name: customer, type: belongs_to, optional: true}
+  - name: customer
+    id: ulid
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Apply(schema); err != nil {
+		t.Fatal(err)
+	}
+	if model, want := mustRead(t, mem, "internal/models/order.go"), "CustomerID *string        `json:\"customer_id\" gorm:\"type:char(26);index\"`"; !strings.Contains(model, want) {
+		t.Errorf("order model does not contain %s:\n%s", want, model)
+	}
+	if request, want := mustRead(t, mem, "internal/requests/order_request.go"), "validate:\"omitempty,ulid\""; !strings.Contains(request, want) {
+		t.Errorf("order request does not contain %s:\n%s", want, request)
+	}
+}
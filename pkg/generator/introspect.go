@@ -0,0 +1,349 @@
+package generator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IntrospectOptions selects the tables IntrospectDatabase reads.
+type IntrospectOptions struct {
+	Tables   []string // tables to read, in this order; all tables when empty
+	DBSchema string   // PostgreSQL schema of the tables; "public" when empty
+}
+
+// sqlCatalog reads tables from the catalog of a live database.
+type sqlCatalog interface {
+	// tableNames lists the tables in name order.
+	tableNames(ctx context.Context) ([]string, error)
+	// table reads the columns and keys of the table called name.
+	table(ctx context.Context, name string) (*sqlTable, error)
+}
+
+// LoadDatabaseSchema connects to the database at dsn, a connection string
+// such as DatabaseDSN returns, with the database/sql driver the caller
+// registered under driver; see IntrospectDatabase.
+func LoadDatabaseSchema(ctx context.Context, driver, dsn string, opts IntrospectOptions) (*Schema, []string, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer db.Close()
+	if err := db.PingContext(ctx); err != nil {
+		return nil, nil, fmt.Errorf("connect to database: %w", err)
+	}
+	return IntrospectDatabase(ctx, db, driver, opts)
+}
+
+// IntrospectDatabase reads the tables of a live database and returns a
+// Schema with one module per table, like ParseSQLSchema does for DDL. Column
+// types, NOT NULL, lengths, primary keys, single-column unique constraints
+// and indexes, and foreign keys come from information_schema and the pg
+// catalogs, or from the pragmas of SQLite. driver is the database/sql driver
+// db was opened with: "pgx" or "postgres" for PostgreSQL, "sqlite" or
+// "sqlite3" for an SQLite stand-in. The warnings name what could not be
+// carried over.
+func IntrospectDatabase(ctx context.Context, db *sql.DB, driver string, opts IntrospectOptions) (*Schema, []string, error) {
+	var catalog sqlCatalog
+	switch driver {
+	case "pgx", "postgres":
+		if opts.DBSchema == "" {
+			opts.DBSchema = "public"
+		}
+		catalog = &postgresCatalog{db: db, schema: opts.DBSchema}
+	case "sqlite", "sqlite3":
+		catalog = &sqliteCatalog{db: db}
+	default:
+		return nil, nil, fmt.Errorf("unsupported database driver %q (expected pgx or sqlite)", driver)
+	}
+
+	names, err := catalog.tableNames(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list tables: %w", err)
+	}
+	if len(opts.Tables) > 0 {
+		for _, name := range opts.Tables {
+			if !slices.Contains(names, name) {
+				if opts.DBSchema != "" {
+					return nil, nil, fmt.Errorf("table %s not found in schema %s", name, opts.DBSchema)
+				}
+				return nil, nil, fmt.Errorf("table %s not found", name)
+			}
+		}
+		names = opts.Tables
+	}
+	if len(names) == 0 {
+		return nil, nil, errors.New("the database has no tables")
+	}
+
+	var tables []*sqlTable
+	var warnings []string
+	for _, name := range names {
+		table, err := catalog.table(ctx, name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("table %s: %w", name, err)
+		}
+		for _, c := range table.columns {
+			if c.typeName == "" {
+				warnings = append(warnings, fmt.Sprintf("table %s: column %s has no declared type; it is generated as text", name, c.name))
+				c.typeName = "text"
+			}
+		}
+		tables = append(tables, table)
+	}
+	schema, more, err := sqlToSchema(tables)
+	if err != nil {
+		return nil, nil, err
+	}
+	return schema, append(warnings, more...), nil
+}
+
+// DatabaseDSN returns the PostgreSQL connection string of the project, built
+// like database.PostgresConnection from the postgres section of config.yaml.
+// Environment variables override the file: postgres.host or POSTGRES_HOST,
+// and so on.
+func (g *Generator) DatabaseDSN() (string, error) {
+	var config struct {
+		Postgres map[string]any `yaml:"postgres"`
+	}
+	content, err := g.readFile("config.yaml")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return "", fmt.Errorf("config.yaml: %w", err)
+	}
+	env := func(key string) string {
+		if val, found := os.LookupEnv("postgres." + key); found {
+			return val
+		}
+		if val, found := os.LookupEnv("POSTGRES_" + strings.ToUpper(key)); found {
+			return val
+		}
+		if val, ok := config.Postgres[key]; ok && val != nil {
+			return fmt.Sprint(val)
+		}
+		return ""
+	}
+	if env("host") == "" {
+		return "", errors.New("no postgres settings in config.yaml or the environment; pass --dsn")
+	}
+	return fmt.Sprintf("host=%v user=%v password=%v dbname=%v port=%v sslmode=disable TimeZone=Asia/Bangkok",
+		env("host"),
+		env("user"),
+		env("password"),
+		env("database"),
+		env("port"),
+	), nil
+}
+
+// postgresCatalog reads tables from information_schema and the pg catalogs.
+type postgresCatalog struct {
+	db     *sql.DB
+	schema string
+}
+
+func (c *postgresCatalog) tableNames(ctx context.Context) ([]string, error) {
+	return queryStrings(ctx, c.db, `SELECT table_name FROM information_schema.tables
+WHERE table_schema = $1 AND table_type = 'BASE TABLE'
+ORDER BY table_name`, c.schema)
+}
+
+func (c *postgresCatalog) table(ctx context.Context, name string) (*sqlTable, error) {
+	table := &sqlTable{name: name}
+	// format_type spells the type as DDL does, e.g. "numeric(18,4)" or
+	// "character varying(80)", so it maps like the columns of from-sql.
+	rows, err := c.db.QueryContext(ctx, `SELECT a.attname, format_type(a.atttypid, a.atttypmod), a.attnotnull
+FROM pg_catalog.pg_attribute a
+JOIN pg_catalog.pg_class cl ON cl.oid = a.attrelid
+JOIN pg_catalog.pg_namespace ns ON ns.oid = cl.relnamespace
+WHERE ns.nspname = $1 AND cl.relname = $2 AND a.attnum > 0 AND NOT a.attisdropped
+ORDER BY a.attnum`, c.schema, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var declared string
+		column := &sqlColumn{}
+		if err := rows.Scan(&column.name, &declared, &column.notNull); err != nil {
+			return nil, err
+		}
+		if err := column.setType(declared); err != nil {
+			return nil, err
+		}
+		table.columns = append(table.columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Primary and foreign keys, with their columns in key order.
+	rows, err = c.db.QueryContext(ctx, `SELECT con.contype::text, COALESCE(ref.relname, ''),
+	(SELECT string_agg(a.attname::text, ',' ORDER BY k.ord)
+	FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+	JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum)
+FROM pg_catalog.pg_constraint con
+JOIN pg_catalog.pg_class cl ON cl.oid = con.conrelid
+JOIN pg_catalog.pg_namespace ns ON ns.oid = cl.relnamespace
+LEFT JOIN pg_catalog.pg_class ref ON ref.oid = con.confrelid
+WHERE ns.nspname = $1 AND cl.relname = $2 AND con.contype IN ('p', 'f')
+ORDER BY con.conname`, c.schema, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var kind, referenced, columns string
+		if err := rows.Scan(&kind, &referenced, &columns); err != nil {
+			return nil, err
+		}
+		names := strings.Split(columns, ",")
+		switch {
+		case kind == "p":
+			table.primaryKey = names
+		case len(names) == 1:
+			if column := table.column(names[0]); column != nil {
+				column.references = referenced
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Unique constraints and unique indexes on a single column.
+	unique, err := queryStrings(ctx, c.db, `SELECT a.attname
+FROM pg_catalog.pg_index i
+JOIN pg_catalog.pg_class cl ON cl.oid = i.indrelid
+JOIN pg_catalog.pg_namespace ns ON ns.oid = cl.relnamespace
+JOIN pg_catalog.pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = i.indkey[0]
+WHERE ns.nspname = $1 AND cl.relname = $2 AND i.indisunique AND NOT i.indisprimary
+	AND i.indnatts = 1 AND i.indpred IS NULL AND i.indexprs IS NULL`, c.schema, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range unique {
+		if column := table.column(name); column != nil {
+			column.unique = true
+		}
+	}
+	return table, nil
+}
+
+// sqliteCatalog reads tables from the sqlite_master table and the pragmas of
+// an SQLite database.
+type sqliteCatalog struct {
+	db *sql.DB
+}
+
+func (c *sqliteCatalog) tableNames(ctx context.Context) ([]string, error) {
+	return queryStrings(ctx, c.db, `SELECT name FROM sqlite_master
+WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+ORDER BY name`)
+}
+
+func (c *sqliteCatalog) table(ctx context.Context, name string) (*sqlTable, error) {
+	table := &sqlTable{name: name}
+	rows, err := c.db.QueryContext(ctx, `SELECT name, type, "notnull", pk FROM pragma_table_info(?) ORDER BY cid`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	keys := map[int]string{} // primary key columns by position
+	for rows.Next() {
+		var declared string
+		var pk int
+		column := &sqlColumn{}
+		if err := rows.Scan(&column.name, &declared, &column.notNull, &pk); err != nil {
+			return nil, err
+		}
+		// Declared types are free text, e.g. "VARCHAR(80)" or "DATETIME".
+		if err := column.setType(declared); err != nil {
+			return nil, err
+		}
+		if pk > 0 {
+			keys[pk] = column.name
+		}
+		table.columns = append(table.columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := 1; i <= len(keys); i++ {
+		table.primaryKey = append(table.primaryKey, keys[i])
+	}
+	table.requirePrimaryKey()
+
+	rows, err = c.db.QueryContext(ctx, `SELECT "from", "table" FROM pragma_foreign_key_list(?)
+WHERE id IN (SELECT id FROM pragma_foreign_key_list(?) GROUP BY id HAVING count(*) = 1)`, name, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var from, referenced string
+		if err := rows.Scan(&from, &referenced); err != nil {
+			return nil, err
+		}
+		if column := table.column(from); column != nil {
+			column.references = referenced
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Unique constraints and unique indexes on a single column; expression
+	// indexes have a NULL column name.
+	unique, err := queryStrings(ctx, c.db, `SELECT COALESCE(max(ii.name), '')
+FROM pragma_index_list(?) il JOIN pragma_index_info(il.name) ii
+WHERE il."unique" AND il.origin <> 'pk' AND NOT il.partial
+GROUP BY il.name HAVING count(*) = 1`, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range unique {
+		if column := table.column(name); column != nil {
+			column.unique = true
+		}
+	}
+	return table, nil
+}
+
+// setType sets the type of c from its declaration in the catalog, read like
+// the type of a CREATE TABLE column.
+func (c *sqlColumn) setType(declared string) error {
+	tokens, err := sqlTokens(declared)
+	if err != nil {
+		return fmt.Errorf("column %s: %w", c.name, err)
+	}
+	if len(tokens) > 0 {
+		c.parseType(&sqlParser{tokens: tokens})
+	}
+	return nil
+}
+
+// queryStrings returns the first column of the rows of query.
+func queryStrings(ctx context.Context, db *sql.DB, query string, args ...any) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,203 @@
+package generator
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// testIntrospectDDL creates the tables of TestIntrospectDatabase; it is valid
+// in both PostgreSQL and SQLite.
+const testIntrospectDDL = `CREATE TABLE categories (
+	id integer PRIMARY KEY,
+	title varchar(80) NOT NULL UNIQUE
+);
+CREATE TABLE products (
+	id bigint PRIMARY KEY,
+	category_id integer NOT NULL REFERENCES categories (id),
+	sku varchar(32) NOT NULL,
+	price numeric(12,2) NOT NULL,
+	weight numeric(10,3),
+	notes text,
+	specs jsonb,
+	released_at timestamp,
+	created_at timestamp NOT NULL
+);
+CREATE UNIQUE INDEX products_sku ON products (sku);
+CREATE TABLE order_lines (
+	order_id integer NOT NULL,
+	line integer NOT NULL,
+	quantity integer NOT NULL,
+	PRIMARY KEY (order_id, line)
+);`
+
+var testIntrospectModules = []SchemaModule{
+	{Name: "product", Fields: []SchemaField{
+		{Name: "category", Type: "belongs_to"},
+		{Name: "sku", Type: "string", Size: 32, Unique: true},
+		{Name: "price", Type: "decimal"},
+		{Name: "weight", Type: "decimal", Optional: true, ColumnType: "numeric(10,3)"},
+		{Name: "notes", Type: "text", Optional: true},
+		{Name: "specs", Type: "text", Optional: true, Validate: "omitempty,json", ColumnType: "jsonb"},
+		{Name: "released_at", Type: "time", Optional: true},
+	}},
+	{Name: "category", Table: "categories", Fields: []SchemaField{
+		{Name: "title", Type: "string", Size: 80, Unique: true},
+	}},
+}
+
+func TestIntrospectDatabase(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "legacy.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, testIntrospectDDL+`
+CREATE TABLE notes (id INTEGER PRIMARY KEY AUTOINCREMENT, body, rating REAL, seen BOOLEAN NOT NULL, edited DATETIME);`); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		tables       []string
+		want         []SchemaModule
+		wantWarnings []string
+		wantErr      string
+	}{
+		{
+			name:   "selected tables",
+			tables: []string{"products", "categories"},
+			want:   testIntrospectModules,
+		},
+		{
+			name:   "sqlite types",
+			tables: []string{"notes", "order_lines"},
+			want: []SchemaModule{
+				{Name: "note", Fields: []SchemaField{
+					{Name: "body", Type: "text", Optional: true},
+					{Name: "rating", Type: "float", Optional: true},
+					{Name: "seen", Type: "bool"},
+					{Name: "edited", Type: "time", Optional: true},
+				}},
+				{Name: "order_line", Fields: []SchemaField{
+					{Name: "order_id", Type: "int"},
+					{Name: "line", Type: "int"},
+					{Name: "quantity", Type: "int"},
+				}},
+			},
+			wantWarnings: []string{
+				"table notes: column body has no declared type; it is generated as text",
+				"table order_lines: primary key (order_id, line) is not an id column; the model uses an uint id",
+			},
+		},
+		{
+			name:    "missing table",
+			tables:  []string{"products", "orders"},
+			wantErr: "table orders not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, warnings, err := IntrospectDatabase(ctx, db, "sqlite", IntrospectOptions{Tables: tt.tables})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("IntrospectDatabase() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("IntrospectDatabase() error = %v", err)
+			}
+			if !reflect.DeepEqual(schema.Modules, tt.want) {
+				t.Errorf("IntrospectDatabase() modules =\n%+v\nwant\n%+v", schema.Modules, tt.want)
+			}
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("IntrospectDatabase() warnings =\n%q\nwant\n%q", warnings, tt.wantWarnings)
+			}
+		})
+	}
+
+	// Without --tables, every table is read in name order.
+	schema, _, err := IntrospectDatabase(ctx, db, "sqlite", IntrospectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range schema.Modules {
+		names = append(names, m.Name)
+	}
+	if want := []string{"category", "note", "order_line", "product"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("IntrospectDatabase() modules = %v, want %v", names, want)
+	}
+}
+
+// TestIntrospectDatabase_Postgres runs against the database at
+// GO_GEN_TEST_POSTGRES_DSN, e.g.
+// "host=localhost user=postgres password=postgres dbname=postgres port=5432 sslmode=disable".
+func TestIntrospectDatabase_Postgres(t *testing.T) {
+	dsn := os.Getenv("GO_GEN_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("GO_GEN_TEST_POSTGRES_DSN is not set")
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, "DROP SCHEMA IF EXISTS go_gen_introspect CASCADE; CREATE SCHEMA go_gen_introspect"); err != nil {
+		t.Fatal(err)
+	}
+	defer db.ExecContext(ctx, "DROP SCHEMA go_gen_introspect CASCADE")
+	if _, err := db.ExecContext(ctx, "SET search_path TO go_gen_introspect;\n"+testIntrospectDDL+`
+CREATE TYPE mood AS ENUM ('ok', 'great');
+CREATE TABLE visits (id serial PRIMARY KEY, tags text[], mood mood NOT NULL, at timestamptz NOT NULL);`); err != nil {
+		t.Fatal(err)
+	}
+
+	schema, warnings, err := LoadDatabaseSchema(ctx, "pgx", dsn, IntrospectOptions{
+		Tables:   []string{"products", "categories", "visits"},
+		DBSchema: "go_gen_introspect",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append(testIntrospectModules[:2:2], SchemaModule{Name: "visit", Fields: []SchemaField{
+		{Name: "tags", Type: "text", Optional: true},
+		{Name: "mood", Type: "text"},
+		{Name: "at", Type: "time"},
+	}})
+	if !reflect.DeepEqual(schema.Modules, want) {
+		t.Errorf("LoadDatabaseSchema() modules =\n%+v\nwant\n%+v", schema.Modules, want)
+	}
+	wantWarnings := []string{
+		"table visits: array column tags is generated as text",
+		"table visits: column mood has unsupported type mood; it is generated as text",
+	}
+	if !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("LoadDatabaseSchema() warnings =\n%q\nwant\n%q", warnings, wantWarnings)
+	}
+}
+
+func TestDatabaseDSN(t *testing.T) {
+	mem := NewMemFS()
+	mem.WriteFile("config.yaml", []byte("postgres:\n  host: localhost\n  port: 5432\n  user: postgres\n  password: postgres\n  database: shop\n"))
+	g := New("", Options{})
+	g.FS = mem
+	t.Setenv("POSTGRES_HOST", "db.internal")
+
+	dsn, err := g.DatabaseDSN()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "host=db.internal user=postgres password=postgres dbname=shop port=5432 sslmode=disable TimeZone=Asia/Bangkok"; dsn != want {
+		t.Errorf("DatabaseDSN() = %q, want %q", dsn, want)
+	}
+}
@@ -0,0 +1,380 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	sampleEmailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	sampleUUIDPattern  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	sampleULIDPattern  = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+)
+
+// sampleModule is a module inferred from JSON objects.
+type sampleModule struct {
+	name    string
+	fields  []*sampleField
+	byName  map[string]*sampleField
+	objects int      // objects merged into the module
+	parents []string // modules that have many of this one
+	id      string   // field spec type of the sample's id values
+}
+
+// sampleField is a field inferred from the values of one key.
+type sampleField struct {
+	name     string
+	key      string // JSON key in the sample
+	typ      string // field spec type; "" while only null was seen
+	model    string // module of a relation
+	optional bool
+	seen     int // objects that have the key
+}
+
+// sampleInference infers modules from a JSON sample.
+type sampleInference struct {
+	modules  []*sampleModule
+	byName   map[string]*sampleModule
+	flatten  bool
+	renamed  []string // "orderNumber -> order_number"
+	warnings []string
+}
+
+// LoadJSONSample reads the JSON sample at path; see ParseJSONSample.
+func LoadJSONSample(moduleName, path string, flatten bool) (*Schema, []string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	schema, warnings, err := ParseJSONSample(moduleName, content, flatten)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return schema, warnings, nil
+}
+
+// ParseJSONSample infers a Schema for moduleName from a sample JSON object,
+// or an array of them. Field types follow the values: integers, decimals
+// (at most two fraction digits), floats, booleans, and strings that look
+// like times (RFC 3339 or dates), UUIDs or emails; strings over 255
+// characters are text. An id that is a UUID, a ULID or beyond 32 bits sets
+// the module's ID type (see IDTypes). Keys that are null, missing from some objects or
+// empty strings are optional. Nested objects and arrays of objects become
+// child modules linked to the parent: an object is a belongs_to relation
+// (order.customer), an array of objects a has_many relation to a module that
+// belongs to the parent (order.items -> order_item). With flatten, nested
+// objects are inlined as prefixed fields instead (shipping_city for
+// {"shipping": {"city": ...}}). The warnings name what could not be carried
+// over.
+func ParseJSONSample(moduleName string, sample []byte, flatten bool) (*Schema, []string, error) {
+	moduleName = strings.ToLower(strings.TrimSpace(moduleName))
+	if !fieldNamePattern.MatchString(moduleName) {
+		return nil, nil, fmt.Errorf("module name %q must be snake_case (e.g. user_account)", moduleName)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(sample, &doc); err != nil {
+		return nil, nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil, errors.New("empty sample")
+	}
+	s := &sampleInference{byName: map[string]*sampleModule{}, flatten: flatten}
+	if err := s.addObjects(moduleName, moduleName, doc.Content[0]); err != nil {
+		return nil, nil, err
+	}
+
+	if len(s.renamed) > 0 {
+		s.warnf("keys are generated in snake_case, so their JSON names change: %s", strings.Join(s.renamed, ", "))
+	}
+	schema := &Schema{}
+	for _, m := range s.modules {
+		module := SchemaModule{Name: m.name}
+		switch m.id {
+		case "", "int":
+		case "int64", "uuid", "ulid":
+			module.ID = m.id
+		default:
+			s.warnf("%s.id is %s; the model uses an uint id", m.name, m.id)
+		}
+		for _, parent := range m.parents {
+			if f := m.byName[parent]; f == nil || f.typ != "belongs_to" {
+				m.fields = append(m.fields, &sampleField{name: parent, key: parent, typ: "belongs_to", seen: m.objects})
+			}
+		}
+		for _, f := range m.fields {
+			if m.foreignKey(f) {
+				continue // the belongs_to relation declares the column
+			}
+			if f.typ == "" {
+				s.warnf("%s.%s is always null; it is generated as an optional string", m.name, f.key)
+				f.typ = "string"
+			}
+			field := SchemaField{Name: f.name, Type: f.typ, Optional: f.optional || f.seen < m.objects}
+			if f.model != f.name {
+				field.Model = f.model
+			}
+			if f.typ == "has_many" {
+				field.Optional = false
+			}
+			module.Fields = append(module.Fields, field)
+		}
+		if len(module.Fields) == 0 {
+			s.warnf("%s has no fields in the sample; it gets a name field", m.name)
+		}
+		schema.Modules = append(schema.Modules, module)
+	}
+	if err := schema.validate(); err != nil {
+		return nil, nil, err
+	}
+	return schema, s.warnings, nil
+}
+
+func (s *sampleInference) warnf(format string, args ...any) {
+	s.warnings = append(s.warnings, fmt.Sprintf(format, args...))
+}
+
+// foreignKey reports whether f is a sample key, e.g. customer_id, that holds
+// the foreign key of a belongs_to relation of m.
+func (m *sampleModule) foreignKey(f *sampleField) bool {
+	name, ok := strings.CutSuffix(f.name, "_id")
+	if !ok || f.typ == "belongs_to" {
+		return false
+	}
+	relation := m.byName[name]
+	return (relation != nil && relation.typ == "belongs_to") || slices.Contains(m.parents, name)
+}
+
+// module returns the module called name, creating it.
+func (s *sampleInference) module(name string) *sampleModule {
+	m := s.byName[name]
+	if m == nil {
+		m = &sampleModule{name: name, byName: map[string]*sampleField{}}
+		s.byName[name] = m
+		s.modules = append(s.modules, m)
+	}
+	return m
+}
+
+// addObjects merges an object, or the objects of an array, into module
+// name. path names the value in warnings, e.g. "order.items".
+func (s *sampleInference) addObjects(name, path string, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.MappingNode:
+		m := s.module(name)
+		m.objects++
+		s.addObject(m, "", path, n)
+	case yaml.SequenceNode:
+		if len(n.Content) == 0 {
+			return fmt.Errorf("%s: empty array; the sample needs at least one object", path)
+		}
+		for _, item := range n.Content {
+			if item.Kind != yaml.MappingNode {
+				return fmt.Errorf("%s: expected an array of objects", path)
+			}
+			if err := s.addObjects(name, path, item); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%s: expected an object or an array of objects", path)
+	}
+	return nil
+}
+
+// addObject adds the keys of an object to m, their names prefixed with
+// prefix when flattened.
+func (s *sampleInference) addObject(m *sampleModule, prefix, path string, n *yaml.Node) {
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i].Value, n.Content[i+1]
+		name := prefix + toSnakeCase(key)
+		valuePath := path + "." + key
+		if name == "id" && prefix == "" {
+			if typ, _ := sampleType(value); typ != "" {
+				m.id, _ = mergeSampleTypes(m.id, typ)
+			}
+		}
+		if _, reserved := reservedFields[name]; reserved {
+			continue
+		}
+		if !fieldNamePattern.MatchString(name) {
+			s.warnf("%s skipped: cannot derive a snake_case field name", valuePath)
+			continue
+		}
+
+		switch value.Kind {
+		case yaml.MappingNode:
+			if s.flatten {
+				s.addObject(m, name+"_", valuePath, value)
+				continue
+			}
+			if child := s.addChild(m, name, valuePath, value); child != "" && !seen[name] {
+				seen[name] = true
+				s.addRelation(m, name, key, valuePath, "belongs_to", child)
+			}
+			continue
+		case yaml.SequenceNode:
+			if len(value.Content) > 0 && value.Content[0].Kind == yaml.MappingNode {
+				if child := s.addChild(m, name, valuePath, value); child != "" && !seen[name] {
+					seen[name] = true
+					s.addRelation(m, name, key, valuePath, "has_many", child)
+				}
+			} else {
+				s.warnOnce(valuePath + " is an array of values; it is not generated")
+			}
+			continue
+		}
+
+		if seen[name] {
+			continue
+		}
+		if snake := toSnakeCase(key); snake != key && !slices.Contains(s.renamed, key+" -> "+snake) {
+			s.renamed = append(s.renamed, key+" -> "+snake)
+		}
+		seen[name] = true
+		f := m.byName[name]
+		if f == nil {
+			f = &sampleField{name: name, key: key}
+			m.byName[name] = f
+			m.fields = append(m.fields, f)
+		}
+		f.seen++
+		typ, optional := sampleType(value)
+		f.optional = f.optional || optional
+		if typ == "" {
+			continue
+		}
+		merged, ok := mergeSampleTypes(f.typ, typ)
+		if !ok {
+			s.warnf("%s is both %s and %s; it is generated as a string", valuePath, f.typ, typ)
+		}
+		f.typ = merged
+	}
+}
+
+// addChild infers a child module from the object or the array of objects
+// under key and returns its name, or "" when it is skipped. An object is a
+// module of its own (order.customer -> customer); array elements are named
+// after the parent (order.items -> order_item) and belong to it.
+func (s *sampleInference) addChild(parent *sampleModule, key, path string, value *yaml.Node) string {
+	name := key
+	if value.Kind == yaml.SequenceNode {
+		name = toSingular(key)
+		if !strings.HasPrefix(name, parent.name+"_") {
+			name = parent.name + "_" + name
+		}
+	}
+	if err := s.addObjects(name, path, value); err != nil {
+		s.warnf("%s skipped: %v", path, err)
+		return ""
+	}
+	if child := s.byName[name]; value.Kind == yaml.SequenceNode && !slices.Contains(child.parents, parent.name) {
+		child.parents = append(child.parents, parent.name)
+	}
+	return name
+}
+
+// addRelation adds the relation name of m to the module model, e.g. a
+// belongs_to customer or a has_many items.
+func (s *sampleInference) addRelation(m *sampleModule, name, key, path, relation, model string) {
+	f := m.byName[name]
+	if f == nil {
+		f = &sampleField{name: name, key: key, typ: relation, model: model}
+		m.byName[name] = f
+		m.fields = append(m.fields, f)
+	}
+	f.seen++
+	if f.typ != relation || f.model != model {
+		s.warnf("%s is both %s and %s; it is generated as %s", path, f.typ, relation, f.typ)
+	}
+}
+
+// warnOnce adds warning unless an array element added it already.
+func (s *sampleInference) warnOnce(warning string) {
+	for _, w := range s.warnings {
+		if w == warning {
+			return
+		}
+	}
+	s.warnings = append(s.warnings, warning)
+}
+
+// sampleType returns the field spec type of a JSON scalar, and whether it
+// makes the field optional. The type is "" for null.
+func sampleType(n *yaml.Node) (typ string, optional bool) {
+	switch n.ShortTag() {
+	case "!!null":
+		return "", true
+	case "!!bool":
+		return "bool", false
+	case "!!int":
+		v, err := strconv.ParseInt(n.Value, 10, 64)
+		if err != nil || v > math.MaxInt32 || v < math.MinInt32 {
+			return "int64", false
+		}
+		return "int", false
+	case "!!float":
+		if i := strings.IndexByte(n.Value, '.'); i >= 0 && len(n.Value)-i-1 <= 2 && !strings.ContainsAny(n.Value, "eE") {
+			return "decimal", false
+		}
+		return "float", false
+	}
+	v := n.Value
+	switch {
+	case v == "":
+		return "string", true
+	case sampleUUIDPattern.MatchString(v):
+		return "uuid", false
+	case sampleULIDPattern.MatchString(v):
+		return "ulid", false
+	case sampleEmailPattern.MatchString(v):
+		return "email", false
+	case isSampleTime(v):
+		return "time", false
+	case len(v) > 255:
+		return "text", false
+	}
+	return "string", false
+}
+
+// isSampleTime reports whether v is an RFC 3339 time or a date.
+func isSampleTime(v string) bool {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+		if _, err := time.Parse(layout, v); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeSampleTypes returns the type that holds values of types a and b, and
+// false when they conflict.
+func mergeSampleTypes(a, b string) (string, bool) {
+	if a == "" || a == b {
+		return b, true
+	}
+	rank := map[string]int{"int": 1, "int64": 2, "decimal": 3, "float": 4}
+	if rank[a] > 0 && rank[b] > 0 {
+		if rank[a] > rank[b] {
+			return a, true
+		}
+		return b, true
+	}
+	textual := map[string]bool{"string": true, "text": true, "email": true, "uuid": true, "ulid": true, "time": true}
+	if textual[a] && textual[b] {
+		if a == "text" || b == "text" {
+			return "text", true
+		}
+		return "string", true
+	}
+	return "string", false
+}
@@ -0,0 +1,145 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseJSONSample(t *testing.T) {
+	tests := []struct {
+		name         string
+		sample       string
+		flatten      bool
+		want         []SchemaModule
+		wantWarnings []string
+		wantErr      string
+	}{
+		{
+			name: "scalar types",
+			sample: `{
+	"id": 7, "code": "SO-1", "total": 149.90, "rate": 1.08532, "paid": true, "count": 3,
+	"big": 9007199254740993, "placed_at": "2024-05-01T10:15:00Z", "due": "2024-05-04",
+	"ref": "1b4e28ba-2fa1-11d2-883f-0016d3cca427", "email": "ada@example.com", "memo": "", "note": null
+}`,
+			want: []SchemaModule{{Name: "order", Fields: []SchemaField{
+				{Name: "code", Type: "string"},
+				{Name: "total", Type: "decimal"},
+				{Name: "rate", Type: "float"},
+				{Name: "paid", Type: "bool"},
+				{Name: "count", Type: "int"},
+				{Name: "big", Type: "int64"},
+				{Name: "placed_at", Type: "time"},
+				{Name: "due", Type: "time"},
+				{Name: "ref", Type: "uuid"},
+				{Name: "email", Type: "email"},
+				{Name: "memo", Type: "string", Optional: true},
+				{Name: "note", Type: "string", Optional: true},
+			}}},
+			wantWarnings: []string{"order.note is always null; it is generated as an optional string"},
+		},
+		{
+			name:   "array of samples",
+			sample: `[{"sku": "A", "qty": 1, "price": 5}, {"sku": "B", "qty": 2, "price": 4.5, "gift": true}, {"sku": 3, "qty": 1, "price": 1}]`,
+			want: []SchemaModule{{Name: "order", Fields: []SchemaField{
+				{Name: "sku", Type: "string"},
+				{Name: "qty", Type: "int"},
+				{Name: "price", Type: "decimal"},
+				{Name: "gift", Type: "bool", Optional: true},
+			}}},
+			wantWarnings: []string{"order.sku is both string and int; it is generated as a string"},
+		},
+		{
+			name:   "child modules",
+			sample: `{"orderNumber": "SO-1", "customer": {"name": "Ada"}, "items": [{"sku": "A"}], "tags": ["rush"]}`,
+			want: []SchemaModule{
+				{Name: "order", Fields: []SchemaField{
+					{Name: "order_number", Type: "string"},
+					{Name: "customer", Type: "belongs_to"},
+					{Name: "items", Type: "has_many", Model: "order_item"},
+				}},
+				{Name: "customer", Fields: []SchemaField{{Name: "name", Type: "string"}}},
+				{Name: "order_item", Fields: []SchemaField{
+					{Name: "sku", Type: "string"},
+					{Name: "order", Type: "belongs_to"},
+				}},
+			},
+			wantWarnings: []string{
+				"order.tags is an array of values; it is not generated",
+				"keys are generated in snake_case, so their JSON names change: orderNumber -> order_number",
+			},
+		},
+		{
+			name:   "foreign keys in the sample",
+			sample: `[{"customer_id": 4, "customer": {"name": "Ada"}, "lines": [{"order_id": 1, "qty": 2}]}, {"lines": [{"qty": 1}]}]`,
+			want: []SchemaModule{
+				{Name: "order", Fields: []SchemaField{
+					{Name: "customer", Type: "belongs_to", Optional: true},
+					{Name: "lines", Type: "has_many", Model: "order_line"},
+				}},
+				{Name: "customer", Fields: []SchemaField{{Name: "name", Type: "string"}}},
+				{Name: "order_line", Fields: []SchemaField{
+					{Name: "qty", Type: "int"},
+					{Name: "order", Type: "belongs_to"},
+				}},
+			},
+		},
+		{
+			name:   "id types",
+			sample: `{"id": "01J1V6X8Q3ZK4N5P6R7S8T9V0W", "customer": {"id": "8a7e4c1e-2f7b-4b8e-9f43-2d2f5a1c7e10"}, "lines": [{"id": 370181480930648064}], "notes": [{"id": "n-1"}]}`,
+			want: []SchemaModule{
+				{Name: "order", ID: "ulid", Fields: []SchemaField{
+					{Name: "customer", Type: "belongs_to"},
+					{Name: "lines", Type: "has_many", Model: "order_line"},
+					{Name: "notes", Type: "has_many", Model: "order_note"},
+				}},
+				{Name: "customer", ID: "uuid", Fields: nil},
+				{Name: "order_line", ID: "int64", Fields: []SchemaField{{Name: "order", Type: "belongs_to"}}},
+				{Name: "order_note", Fields: []SchemaField{{Name: "order", Type: "belongs_to"}}},
+			},
+			wantWarnings: []string{
+				"customer has no fields in the sample; it gets a name field",
+				"order_note.id is string; the model uses an uint id",
+			},
+		},
+		{
+			name:    "flatten",
+			sample:  `{"code": "SO-1", "shipping": {"city": "Vientiane", "geo": {"lat": 17.97}}}`,
+			flatten: true,
+			want: []SchemaModule{{Name: "order", Fields: []SchemaField{
+				{Name: "code", Type: "string"},
+				{Name: "shipping_city", Type: "string"},
+				{Name: "shipping_geo_lat", Type: "decimal"},
+			}}},
+		},
+		{
+			name:    "not an object",
+			sample:  `"order"`,
+			wantErr: "order: expected an object or an array of objects",
+		},
+		{
+			name:    "empty array",
+			sample:  `[]`,
+			wantErr: "order: empty array; the sample needs at least one object",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, warnings, err := ParseJSONSample("order", []byte(tt.sample), tt.flatten)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseJSONSample() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseJSONSample() error = %v", err)
+			}
+			if !reflect.DeepEqual(schema.Modules, tt.want) {
+				t.Errorf("ParseJSONSample() modules =\n%+v\nwant\n%+v", schema.Modules, tt.want)
+			}
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("ParseJSONSample() warnings =\n%q\nwant\n%q", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,347 @@
+package generator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"reflect"
+	"runtime/debug"
+	"sort"
+)
+
+// ManifestFile is the project file that records what the generator produced.
+const ManifestFile = ".go-gen.lock"
+
+// manifestFormat is the version of the ManifestFile layout.
+const manifestFormat = 1
+
+// modulePath is the path of this module, used to find its version.
+const modulePath = "github.com/BounkhongDev/go-generator"
+
+// Version is the generator version recorded in the manifest. Release builds
+// may set it with -ldflags "-X .../pkg/generator.Version=v1.2.3"; otherwise it
+// is taken from the module build info.
+var Version = ""
+
+// Manifest lists every file the generator wrote to a project.
+type Manifest struct {
+	Format int                      `json:"format"`
+	Files  map[string]ManifestEntry `json:"files"` // keyed by slash-separated path
+}
+
+// ManifestEntry records how a file was generated.
+type ManifestEntry struct {
+	Template   string          `json:"template"`             // e.g. "model.tmpl"
+	Generator  string          `json:"generator"`            // Version that wrote the file
+	Module     string          `json:"module,omitempty"`     // module the file belongs to
+	Data       json.RawMessage `json:"data"`                 // template data
+	Hash       string          `json:"hash"`                 // "sha256:<hex>" of the content
+	Output     []byte          `json:"output,omitempty"`     // gzipped content, the base of merges with edits
+	Registered []string        `json:"registered,omitempty"` // modules wired into the file
+}
+
+// Modules returns the names of the generated modules in lexical order.
+func (m *Manifest) Modules() []string {
+	seen := map[string]bool{}
+	var modules []string
+	for _, entry := range m.Files {
+		if entry.Module != "" && !seen[entry.Module] {
+			seen[entry.Module] = true
+			modules = append(modules, entry.Module)
+		}
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+// Pristine reports whether content is what the generator last wrote to name.
+// Files missing from the manifest are never pristine.
+func (m *Manifest) Pristine(name string, content []byte) bool {
+	entry, ok := m.Files[name]
+	return ok && entry.Hash == contentHash(content)
+}
+
+// setOutput records content as what the generator last wrote to the file.
+func (e *ManifestEntry) setOutput(content []byte) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(content) // writes to a bytes.Buffer do not fail
+	zw.Close()
+	e.Hash, e.Output = contentHash(content), buf.Bytes()
+}
+
+// output returns what the generator last wrote to the file, or false when
+// the manifest predates recording it.
+func (e ManifestEntry) output() ([]byte, bool) {
+	zr, err := gzip.NewReader(bytes.NewReader(e.Output))
+	if err != nil {
+		return nil, false
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil || contentHash(content) != e.Hash {
+		return nil, false
+	}
+	return content, true
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// generatorVersion returns Version, falling back to the version of this
+// module in the build info.
+func generatorVersion() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if info.Main.Path == modulePath && info.Main.Version != "" {
+			return info.Main.Version
+		}
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				return dep.Version
+			}
+		}
+	}
+	return "(devel)"
+}
+
+// Manifest returns the project's manifest; it is empty when the project has
+// none yet.
+func (g *Generator) Manifest() (*Manifest, error) {
+	if g.manifest != nil {
+		return g.manifest, nil
+	}
+	m := &Manifest{Format: manifestFormat, Files: map[string]ManifestEntry{}}
+	content, err := g.readFile(ManifestFile)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(content, m); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", ManifestFile, err)
+		}
+		if m.Files == nil {
+			m.Files = map[string]ManifestEntry{}
+		}
+	}
+	g.manifest = m
+	return m, nil
+}
+
+// recordFile records that content was rendered from templatePath with data
+// and written to rel.
+func (g *Generator) recordFile(rel, templatePath string, data templateData, content []byte) error {
+	m, err := g.Manifest()
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	entry := ManifestEntry{
+		Template:  path.Base(templatePath),
+		Generator: generatorVersion(),
+		Module:    data.ModuleName,
+		Data:      raw,
+	}
+	entry.setOutput(content)
+	m.Files[fsName(rel)] = entry
+	g.manifestChanged = true
+	return g.saveManifestOutsideTransaction()
+}
+
+// recordRegistration records that moduleName was wired into rel, which now
+// has content. Files the generator did not create are not recorded.
+func (g *Generator) recordRegistration(rel, moduleName string, content []byte) error {
+	m, err := g.Manifest()
+	if err != nil {
+		return err
+	}
+	entry, ok := m.Files[fsName(rel)]
+	if !ok {
+		return nil
+	}
+	entry.setOutput(content)
+	entry.Registered = appendUnique(entry.Registered, moduleName)
+	m.Files[fsName(rel)] = entry
+	g.manifestChanged = true
+	return g.saveManifestOutsideTransaction()
+}
+
+// recordUnregistration records that moduleName was unwired from rel, which
+// now has content.
+func (g *Generator) recordUnregistration(rel, moduleName string, content []byte) error {
+	m, err := g.Manifest()
+	if err != nil {
+		return err
+	}
+	entry, ok := m.Files[fsName(rel)]
+	if !ok {
+		return nil
+	}
+	entry.setOutput(content)
+	var registered []string
+	for _, name := range entry.Registered {
+		if name != moduleName {
+			registered = append(registered, name)
+		}
+	}
+	entry.Registered = registered
+	if moduleName == "example" {
+		// The example module is rendered into the templates, not registered.
+		var data templateData
+		if err := json.Unmarshal(entry.Data, &data); err == nil && data.WithExample {
+			data.WithExample = false
+			if entry.Data, err = json.Marshal(data); err != nil {
+				return err
+			}
+		}
+	}
+	m.Files[fsName(rel)] = entry
+	g.manifestChanged = true
+	return g.saveManifestOutsideTransaction()
+}
+
+// recordModuleRename records that rel, a file of module oldName or one it is
+// wired into, was written to newRel with content while renaming the module to
+// newName. Files that were pristine stay pristine.
+func (g *Generator) recordModuleRename(rel, newRel, oldName, newName string, content []byte, pristine bool) error {
+	m, err := g.Manifest()
+	if err != nil {
+		return err
+	}
+	entry, ok := m.Files[fsName(rel)]
+	if !ok {
+		return nil
+	}
+	var data templateData
+	if err := json.Unmarshal(entry.Data, &data); err != nil {
+		return fmt.Errorf("invalid template data for %s in %s: %w", rel, ManifestFile, err)
+	}
+	if entry.Module == oldName {
+		entry.Module = newName
+	}
+	if data, err = data.withModuleRenamed(oldName, newName); err != nil {
+		return fmt.Errorf("invalid template data for %s in %s: %w", rel, ManifestFile, err)
+	}
+	for i, name := range entry.Registered {
+		if name == oldName {
+			entry.Registered[i] = newName
+		}
+	}
+	if oldName == "example" && data.WithExample {
+		// The example module is rendered into the templates, not registered.
+		data.WithExample = false
+		entry.Registered = appendUnique(entry.Registered, newName)
+	}
+	if entry.Data, err = json.Marshal(data); err != nil {
+		return err
+	}
+	if pristine {
+		entry.setOutput(content)
+	} else if rendered, err := g.renderRecorded(newRel, entry); err == nil {
+		entry.setOutput(rendered)
+	}
+	delete(m.Files, fsName(rel))
+	m.Files[fsName(newRel)] = entry
+	g.manifestChanged = true
+	return g.saveManifestOutsideTransaction()
+}
+
+// recordRenamedRelations records the rename of module oldName to newName in
+// the template data of the files the rename left unchanged, whose relations
+// may still name it.
+func (g *Generator) recordRenamedRelations(oldName, newName string) error {
+	m, err := g.Manifest()
+	if err != nil {
+		return err
+	}
+	for name, entry := range m.Files {
+		var data templateData
+		if err := json.Unmarshal(entry.Data, &data); err != nil {
+			return fmt.Errorf("invalid template data for %s in %s: %w", name, ManifestFile, err)
+		}
+		renamed, err := data.withModuleRenamed(oldName, newName)
+		if err != nil {
+			return fmt.Errorf("invalid template data for %s in %s: %w", name, ManifestFile, err)
+		}
+		if reflect.DeepEqual(renamed, data) {
+			continue
+		}
+		if entry.Data, err = json.Marshal(renamed); err != nil {
+			return err
+		}
+		m.Files[name] = entry
+		g.manifestChanged = true
+	}
+	return g.saveManifestOutsideTransaction()
+}
+
+// recordDeletion removes the entry of the deleted file rel.
+func (g *Generator) recordDeletion(rel string) error {
+	m, err := g.Manifest()
+	if err != nil {
+		return err
+	}
+	if _, ok := m.Files[fsName(rel)]; !ok {
+		return nil
+	}
+	delete(m.Files, fsName(rel))
+	g.manifestChanged = true
+	return g.saveManifestOutsideTransaction()
+}
+
+// saveManifestOutsideTransaction saves the manifest right away when a single
+// Create* function runs outside a transaction.
+func (g *Generator) saveManifestOutsideTransaction() error {
+	if g.txDepth > 0 {
+		return nil
+	}
+	err := g.saveManifest()
+	g.manifest, g.manifestChanged = nil, false
+	return err
+}
+
+// saveManifest writes the manifest if it changed since it was read.
+func (g *Generator) saveManifest() error {
+	if !g.manifestChanged {
+		return nil
+	}
+	content, err := json.MarshalIndent(g.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if g.DryRun {
+		// Later steps of the dry run read it, but it is not a planned change.
+		if g.overlay == nil {
+			g.overlay = map[string][]byte{}
+		}
+		g.overlay[fsName(ManifestFile)] = append(content, '\n')
+		return nil
+	}
+	action := ActionCreate
+	if g.fileExists(ManifestFile) {
+		action = ActionPatch
+	}
+	return g.writeFile(ManifestFile, append(content, '\n'), action)
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,78 @@
+package generator
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.GenerateModule("product", "name:string", "price:decimal"); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := g.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := m.Modules(), []string{"example", "product"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Modules() = %v, want %v", got, want)
+	}
+
+	// Every generated file except go.mod and the manifest itself is recorded
+	// with the content that was written.
+	for _, name := range mem.Files() {
+		content, _ := mem.ReadFile(name)
+		if name == "go.mod" || name == ManifestFile {
+			if _, ok := m.Files[name]; ok {
+				t.Errorf("%s is recorded in the manifest", name)
+			}
+			continue
+		}
+		if !m.Pristine(name, content) {
+			t.Errorf("%s is not recorded as pristine", name)
+		}
+	}
+
+	model := m.Files["internal/models/product.go"]
+	if model.Template != "model.tmpl" || model.Module != "product" || model.Generator == "" {
+		t.Errorf("model entry = %+v", model)
+	}
+	var data templateData
+	if err := json.Unmarshal(model.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.ProjectName != "" || data.ModelName != "Product" || len(data.Fields) != 2 || data.Fields[1].Type != "decimal" {
+		t.Errorf("model data = %+v", data)
+	}
+	if got := m.Files["main.go"].Registered; !reflect.DeepEqual(got, []string{"product"}) {
+		t.Errorf("main.go registered = %v, want [product]", got)
+	}
+
+	// A hand edit makes the file no longer pristine.
+	_ = mem.WriteFile("internal/models/product.go", []byte("package models\n"))
+	content, _ := mem.ReadFile("internal/models/product.go")
+	if m.Pristine("internal/models/product.go", content) {
+		t.Error("edited model is still pristine")
+	}
+}
+
+func TestManifest_NotWrittenOnFailure(t *testing.T) {
+	mem := NewMemFS()
+	_ = mem.WriteFile("go.mod", []byte("module example.com/app\n"))
+	g := New("", Options{})
+	g.FS = failingFS{MemFS: mem, fail: map[string]bool{"internal/models/product.go": true}}
+
+	if err := g.GenerateModule("product"); err == nil {
+		t.Fatal("GenerateModule succeeded although a write failed")
+	}
+	if _, err := mem.Stat(ManifestFile); err == nil {
+		t.Errorf("%s was written for a failed generation", ManifestFile)
+	}
+}
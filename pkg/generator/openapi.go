@@ -0,0 +1,770 @@
+package generator
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIDoc is an OpenAPI 3 document being converted to a Schema.
+type openAPIDoc struct {
+	root     *yaml.Node
+	basePath string            // path of the first server URL, e.g. "/api/v1"
+	modules  map[string]string // ID types of the modules being generated, by name
+	warnings []string
+}
+
+// openAPIModule collects what the paths of one resource declare.
+type openAPIModule struct {
+	module    SchemaModule
+	endpoints map[string]bool
+	entity    *yaml.Node // schema of the request body or, failing that, of the response
+	fromBody  bool
+	queries   []openAPIQuery  // query parameters of the list operation
+	paths     map[bool]string // the collection (false) and item (true) path
+}
+
+// openAPIQuery is a query parameter of a list operation.
+type openAPIQuery struct {
+	name      string
+	operation string // e.g. "GET /products"
+}
+
+// openAPIOperations maps the methods of collection ("/products") and item
+// ("/products/{id}") paths to moduleEndpoints.
+var openAPIOperations = map[bool]map[string]string{
+	false: {"get": "list", "post": "create"},
+	true:  {"get": "get", "put": "update", "patch": "update", "delete": "delete"},
+}
+
+// LoadOpenAPI reads the OpenAPI 3 document at path; see ParseOpenAPI.
+func LoadOpenAPI(path string) (*Schema, []string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	schema, warnings, err := ParseOpenAPI(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return schema, warnings, nil
+}
+
+// ParseOpenAPI reads an OpenAPI 3 document in YAML or JSON and returns a
+// Schema with one module per resource. A resource is a collection path such
+// as /products with its item path /products/{id}, or a single one of them
+// with a request body or a component schema; other paths, such as /health,
+// are skipped with a warning. Their operations select the endpoints, their
+// success status codes the response statuses, and the query parameters of
+// the list operation that name fields become filters.
+// The fields come from the component schema of the request body, or of the
+// response when there is no body, including a schema wrapped in a "data"
+// envelope. Types, formats, required, nullable, lengths, ranges and enums map
+// to field types and validate rules; properties that refer to another
+// module, by $ref or by id (customer_id, tag_ids), map to relations. The
+// warnings name what could not be carried over.
+func ParseOpenAPI(content []byte) (*Schema, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, errors.New("not an OpenAPI document")
+	}
+	d := &openAPIDoc{root: doc.Content[0]}
+	if version := d.value(d.root, "openapi"); !strings.HasPrefix(version, "3.") {
+		if d.value(d.root, "swagger") != "" {
+			return nil, nil, errors.New("swagger 2.0 documents are not supported; convert to OpenAPI 3 first")
+		}
+		return nil, nil, errors.New("not an OpenAPI 3 document (missing openapi: 3.x)")
+	}
+	if servers := d.field(d.root, "servers"); servers != nil && len(servers.Content) > 0 {
+		if u, err := url.Parse(d.value(servers.Content[0], "url")); err == nil {
+			d.basePath = strings.TrimSuffix(u.Path, "/")
+		}
+	}
+
+	var modules []*openAPIModule
+	byName := map[string]*openAPIModule{}
+	paths := d.field(d.root, "paths")
+	if paths == nil {
+		return nil, nil, errors.New("no paths")
+	}
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		path, item := paths.Content[i].Value, d.resolve(paths.Content[i+1])
+		if item == nil {
+			continue // resolve warned
+		}
+		name, isItem, ok := d.resource(path)
+		if !ok {
+			continue
+		}
+		m := byName[name]
+		if m == nil {
+			m = &openAPIModule{module: SchemaModule{Name: name}, endpoints: map[string]bool{}, paths: map[bool]string{}}
+			byName[name] = m
+			modules = append(modules, m)
+		}
+		m.paths[isItem] = path
+		d.addPath(m, path, item, isItem)
+	}
+
+	d.modules = map[string]string{}
+	for _, m := range modules {
+		if len(m.endpoints) > 0 && len(m.paths) < 2 && !m.fromBody && !d.component(m.entity) {
+			// e.g. GET /health: an operation, not a resource to store.
+			for _, path := range []string{m.paths[false], m.paths[true]} {
+				if path != "" {
+					d.warnf("path %s skipped: it is not a resource (no collection and item paths, request body or component schema)", path)
+				}
+			}
+			m.endpoints = nil
+		}
+		if path := m.paths[false]; path != "" && len(m.endpoints) > 0 {
+			if served := "/api/v1/" + toPlural(m.module.Name); d.basePath+"/"+strings.Trim(path, "/") != served {
+				d.warnf("path %s is served at %s", path, served)
+			}
+		}
+		if len(m.endpoints) > 0 {
+			d.modules[m.module.Name] = m.module.ID
+		}
+	}
+	schema := &Schema{}
+	for _, m := range modules {
+		if len(m.endpoints) == 0 {
+			continue
+		}
+		for _, endpoint := range moduleEndpoints {
+			if m.endpoints[endpoint] {
+				m.module.Endpoints = append(m.module.Endpoints, endpoint)
+			}
+		}
+		if len(m.module.Endpoints) == len(moduleEndpoints) {
+			m.module.Endpoints = nil
+		}
+		if m.entity == nil {
+			d.warnf("module %s: no request or response schema; it gets a name field", m.module.Name)
+		} else {
+			m.module.Fields = d.fields(m.module.Name, m.entity)
+		}
+		schema.Modules = append(schema.Modules, m.module)
+	}
+	if len(schema.Modules) == 0 {
+		return nil, nil, errors.New("no resource paths found (e.g. /products and /products/{id})")
+	}
+	linkChildren(schema.Modules)
+	for i, m := range slices.DeleteFunc(modules, func(m *openAPIModule) bool { return len(m.endpoints) == 0 }) {
+		m.module = schema.Modules[i]
+		d.addFilters(m)
+		schema.Modules[i] = m.module
+	}
+	if err := schema.validate(); err != nil {
+		return nil, nil, err
+	}
+	return schema, d.warnings, nil
+}
+
+func (d *openAPIDoc) warnf(format string, args ...any) {
+	d.warnings = append(d.warnings, fmt.Sprintf(format, args...))
+}
+
+// component reports whether schema is one of the component schemas.
+func (d *openAPIDoc) component(schema *yaml.Node) bool {
+	components := d.field(d.field(d.root, "components"), "schemas")
+	for i := 1; schema != nil && components != nil && i < len(components.Content); i += 2 {
+		if d.resolve(components.Content[i]) == schema {
+			return true
+		}
+	}
+	return false
+}
+
+// resource returns the module a path belongs to and whether it is an item
+// path. Paths with parameters other than a final item ID are skipped.
+func (d *openAPIDoc) resource(path string) (name string, isItem, ok bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	isItem = len(segments) > 1 && strings.HasPrefix(segments[len(segments)-1], "{")
+	if isItem {
+		segments = segments[:len(segments)-1]
+	}
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, "{") {
+			d.warnf("path %s skipped: nested resources are not generated", path)
+			return "", false, false
+		}
+	}
+	resource := segments[len(segments)-1]
+	name = toSingular(strings.ReplaceAll(strings.ToLower(resource), "-", "_"))
+	if !fieldNamePattern.MatchString(name) {
+		d.warnf("path %s skipped: cannot derive a snake_case module name", path)
+		return "", false, false
+	}
+	return name, isItem, true
+}
+
+// addPath adds the operations of a path item to m.
+func (d *openAPIDoc) addPath(m *openAPIModule, path string, item *yaml.Node, isItem bool) {
+	shared := d.field(item, "parameters")
+	for i := 0; i+1 < len(item.Content); i += 2 {
+		method := item.Content[i].Value
+		switch method {
+		case "parameters", "summary", "description", "servers", "$ref":
+			continue
+		}
+		operation := strings.ToUpper(method) + " " + path
+		endpoint, ok := openAPIOperations[isItem][method]
+		if !ok {
+			d.warnf("%s is not generated: only GET and POST on a collection and GET, PUT, PATCH and DELETE on an item map to endpoints", operation)
+			continue
+		}
+		if m.endpoints[endpoint] {
+			d.warnf("%s is not generated: %s is already mapped to the %s endpoint", operation, path, endpoint)
+			continue
+		}
+		m.endpoints[endpoint] = true
+		op := d.resolve(item.Content[i+1])
+
+		params := d.parameters(shared, d.field(op, "parameters"))
+		for _, p := range params {
+			switch d.value(p, "in") {
+			case "path":
+				schema := d.field(p, "schema")
+				typ, format := d.value(schema, "type"), d.value(schema, "format")
+				switch {
+				case typ == "string" && (format == "uuid" || format == "ulid"):
+					m.module.ID = format
+				case typ != "integer":
+					d.warnf("%s: path parameter %s is %s; the generated routes parse an unsigned integer id", operation, d.value(p, "name"), orUnknown(typ))
+				}
+			case "query":
+				if endpoint == "list" {
+					m.queries = append(m.queries, openAPIQuery{name: d.value(p, "name"), operation: operation})
+				} else {
+					d.warnf("%s: query parameter %s is not generated", operation, d.value(p, "name"))
+				}
+			}
+		}
+
+		d.addStatus(m, operation, endpoint, d.field(op, "responses"))
+		if body := d.jsonSchema(d.field(op, "requestBody")); body != nil && !m.fromBody {
+			m.entity, m.fromBody = body, true
+		} else if m.entity == nil && (endpoint == "get" || endpoint == "list") {
+			m.entity = d.responseEntity(d.field(op, "responses"), endpoint == "list")
+		}
+	}
+}
+
+// parameters merges path item and operation parameters; the operation's
+// win.
+func (d *openAPIDoc) parameters(shared, own *yaml.Node) []*yaml.Node {
+	var params []*yaml.Node
+	seen := map[string]bool{}
+	for _, list := range []*yaml.Node{own, shared} {
+		if list == nil {
+			continue
+		}
+		for _, p := range list.Content {
+			p = d.resolve(p)
+			key := d.value(p, "in") + " " + d.value(p, "name")
+			if !seen[key] {
+				seen[key] = true
+				params = append(params, p)
+			}
+		}
+	}
+	return params
+}
+
+// addStatus sets the module's status for endpoint from the operation's
+// lowest 2xx response.
+func (d *openAPIDoc) addStatus(m *openAPIModule, operation, endpoint string, responses *yaml.Node) {
+	status := 0
+	for _, code := range d.keys(responses) {
+		if n, err := strconv.Atoi(code); err == nil && n >= 200 && n < 300 && (status == 0 || n < status) {
+			status = n
+		}
+	}
+	allowed := endpointStatuses[endpoint]
+	switch {
+	case status == 0 || status == allowed[0]:
+	case slices.Contains(allowed, status):
+		if m.module.Status == nil {
+			m.module.Status = map[string]int{}
+		}
+		m.module.Status[endpoint] = status
+	default:
+		d.warnf("%s responds with %d; the generated endpoint responds with %d", operation, status, allowed[0])
+	}
+}
+
+// responseEntity returns the schema of the entity in the 200 response of a
+// get or list operation, unwrapping a "data" envelope and, for list, an
+// array.
+func (d *openAPIDoc) responseEntity(responses *yaml.Node, list bool) *yaml.Node {
+	for _, code := range []string{"200", "2XX", "default"} {
+		schema := d.jsonSchema(d.field(responses, code))
+		if schema == nil {
+			continue
+		}
+		if data := d.properties(schema).get("data"); data != nil {
+			schema = data
+		}
+		if list {
+			if d.value(schema, "type") != "array" {
+				return nil
+			}
+			schema = d.field(schema, "items")
+		}
+		return schema
+	}
+	return nil
+}
+
+// jsonSchema returns the JSON schema of a request body or response.
+func (d *openAPIDoc) jsonSchema(n *yaml.Node) *yaml.Node {
+	content := d.field(n, "content")
+	if content == nil || len(content.Content) < 2 {
+		return nil
+	}
+	media := d.field(content, "application/json")
+	if media == nil {
+		media = d.resolve(content.Content[1])
+	}
+	return d.field(media, "schema")
+}
+
+// addFilters turns the list operation's query parameters that name a field
+// into filters.
+func (d *openAPIDoc) addFilters(m *openAPIModule) {
+	fields, _ := m.module.fields()
+	if len(fields) == 0 {
+		fields = defaultFields()
+	}
+	for _, q := range m.queries {
+		i := slices.IndexFunc(fields, func(f Field) bool { return f.Name == q.name })
+		switch {
+		case i < 0:
+			d.warnf("%s: query parameter %s is not a field; it is not generated", q.operation, q.name)
+		case strings.TrimPrefix(fields[i].GoType, "*") == "time.Time":
+			d.warnf("%s: query parameter %s filters on a time; it is not generated", q.operation, q.name)
+		default:
+			m.module.Filters = append(m.module.Filters, q.name)
+		}
+	}
+}
+
+// openAPIProperties are the merged properties of an object schema.
+type openAPIProperties struct {
+	names    []string
+	schemas  map[string]*yaml.Node
+	refs     map[string]string // $ref of the property, or of its array items
+	required map[string]bool
+}
+
+func (p openAPIProperties) get(name string) *yaml.Node { return p.schemas[name] }
+
+// properties returns the properties of an object schema, merging allOf.
+func (d *openAPIDoc) properties(schema *yaml.Node) openAPIProperties {
+	props := openAPIProperties{schemas: map[string]*yaml.Node{}, refs: map[string]string{}, required: map[string]bool{}}
+	var walk func(n *yaml.Node, depth int)
+	walk = func(n *yaml.Node, depth int) {
+		n = d.resolve(n)
+		if n == nil || depth > 16 {
+			return
+		}
+		if all := d.field(n, "allOf"); all != nil {
+			for _, part := range all.Content {
+				walk(part, depth+1)
+			}
+		}
+		if properties := d.field(n, "properties"); properties != nil {
+			for i := 0; i+1 < len(properties.Content); i += 2 {
+				name := properties.Content[i].Value
+				if _, ok := props.schemas[name]; !ok {
+					props.names = append(props.names, name)
+				}
+				props.schemas[name] = d.resolve(properties.Content[i+1])
+				props.refs[name] = d.value(properties.Content[i+1], "$ref")
+				if items := d.rawField(props.schemas[name], "items"); items != nil {
+					props.refs[name] = d.value(items, "$ref")
+				}
+			}
+		}
+		if required := d.field(n, "required"); required != nil {
+			for _, name := range required.Content {
+				props.required[name.Value] = true
+			}
+		}
+	}
+	walk(schema, 0)
+	return props
+}
+
+// fields converts the properties of an entity schema to schema fields.
+// readOnly properties are left out since the server sets them.
+func (d *openAPIDoc) fields(moduleName string, schema *yaml.Node) []SchemaField {
+	props := d.properties(schema)
+	var fields []SchemaField
+	for _, name := range props.names {
+		p := props.get(name)
+		column := toSnakeCase(name)
+		if _, reserved := reservedFields[column]; reserved || d.value(p, "readOnly") == "true" {
+			continue
+		}
+		if column != name {
+			d.warnf("module %s: property %s is generated as %s", moduleName, name, column)
+		}
+		if !fieldNamePattern.MatchString(column) {
+			d.warnf("module %s: property %s skipped; cannot derive a snake_case name", moduleName, name)
+			continue
+		}
+		if field, ok := d.relationField(column, p, props.refs[name], props.required[name]); ok {
+			if !slices.ContainsFunc(fields, func(f SchemaField) bool { return f.Name == field.Name }) {
+				fields = append(fields, field)
+			}
+			continue
+		}
+		field, ok := d.schemaField(moduleName, column, p, props.required[name])
+		if ok {
+			fields = append(fields, field)
+		}
+	}
+	// A customer_id or tag_ids property next to its relation is the same data.
+	fields = slices.DeleteFunc(fields, func(f SchemaField) bool {
+		return slices.ContainsFunc(fields, func(r SchemaField) bool {
+			return r.Type == "belongs_to" && f.Name == r.Name+"_id" && holdsID(f.Type, d.modules[cmp.Or(r.Model, r.Name)])
+		})
+	})
+	if len(fields) == 0 {
+		d.warnf("module %s: the schema has no generated properties; it gets a name field", moduleName)
+	}
+	return fields
+}
+
+// relationField converts a property that refers to another module to a
+// relation: an object of the module's schema (customer: $ref Customer) or
+// its id (customer_id) belongs to it, an array of them (items: [$ref
+// OrderItem]) or of their ids (tag_ids) has many of them. Arrays are
+// many2many until linkChildren finds the child's foreign key.
+func (d *openAPIDoc) relationField(name string, p *yaml.Node, ref string, required bool) (SchemaField, bool) {
+	optional := !required || d.value(p, "nullable") == "true"
+	typ := d.value(p, "type")
+	if model := d.refModule(ref); model != "" {
+		if typ == "array" {
+			return manyField(name, model), true
+		}
+		field := SchemaField{Name: name, Type: "belongs_to", Optional: optional}
+		if model != name {
+			field.Model = model
+		}
+		return field, true
+	}
+	if model, ok := strings.CutSuffix(name, "_id"); ok && d.isIDOf(p, model) {
+		return SchemaField{Name: model, Type: "belongs_to", Optional: optional}, true
+	}
+	if model, ok := strings.CutSuffix(name, "_ids"); ok && typ == "array" && d.isIDOf(d.field(p, "items"), model) {
+		return manyField(toPlural(model), model), true
+	}
+	return SchemaField{}, false
+}
+
+// isIDOf reports whether the property p holds an ID of the generated module
+// model: an integer, or a string of the module's uuid or ulid format.
+func (d *openAPIDoc) isIDOf(p *yaml.Node, model string) bool {
+	id, ok := d.modules[model]
+	switch {
+	case !ok:
+		return false
+	case id == "":
+		return d.value(p, "type") == "integer"
+	}
+	return d.value(p, "type") == "string" && d.value(p, "format") == id
+}
+
+// holdsID reports whether a field of type typeName can hold the ID of a
+// module whose ID type is id.
+func holdsID(typeName, id string) bool {
+	if id == "" {
+		return typeName == "int" || typeName == "int64"
+	}
+	return typeName == idSpecTypes[id]
+}
+
+// manyField returns the many2many field name of model.
+func manyField(name, model string) SchemaField {
+	field := SchemaField{Name: name, Type: "many2many"}
+	if model != toSingular(name) {
+		field.Model = model
+	}
+	return field
+}
+
+// refModule returns the module generated from the component schema ref
+// refers to ("#/components/schemas/OrderItem" -> order_item), or "".
+func (d *openAPIDoc) refModule(ref string) string {
+	if ref == "" {
+		return ""
+	}
+	name := toSnakeCase(ref[strings.LastIndex(ref, "/")+1:])
+	if _, ok := d.modules[name]; !ok {
+		return ""
+	}
+	return name
+}
+
+// linkChildren turns the many2many fields of modules whose model belongs to
+// the module, or has its foreign key, into has_many relations.
+func linkChildren(modules []SchemaModule) {
+	for _, m := range modules {
+		for i, f := range m.Fields {
+			if f.Type != "many2many" {
+				continue
+			}
+			model := cmp.Or(f.Model, toSingular(f.Name))
+			c := slices.IndexFunc(modules, func(c SchemaModule) bool { return c.Name == model })
+			if c < 0 || model == m.Name {
+				continue
+			}
+			child := modules[c].Fields
+			k := slices.IndexFunc(child, func(cf SchemaField) bool {
+				return (cf.Type == "belongs_to" && cmp.Or(cf.Model, cf.Name) == m.Name) ||
+					(holdsID(cf.Type, m.ID) && cf.Name == m.Name+"_id")
+			})
+			if k < 0 {
+				continue
+			}
+			if child[k].Type != "belongs_to" {
+				child[k] = SchemaField{Name: m.Name, Type: "belongs_to", Optional: child[k].Optional}
+			}
+			m.Fields[i].Type = "has_many"
+		}
+	}
+}
+
+// schemaField converts one property to a schema field.
+func (d *openAPIDoc) schemaField(moduleName, name string, p *yaml.Node, required bool) (SchemaField, bool) {
+	field := SchemaField{Name: name, Optional: !required || d.value(p, "nullable") == "true"}
+	typ, format := d.value(p, "type"), d.value(p, "format")
+	if types := d.field(p, "type"); types != nil && types.Kind == yaml.SequenceNode {
+		// OpenAPI 3.1: type: [string, "null"]
+		typ = ""
+		for _, t := range types.Content {
+			if t.Value == "null" {
+				field.Optional = true
+			} else {
+				typ = t.Value
+			}
+		}
+	}
+	switch typ {
+	case "string":
+		switch format {
+		case "email":
+			field.Type = "email"
+		case "uuid", "ulid":
+			field.Type = format
+		case "date-time", "date":
+			field.Type = "time"
+		default:
+			field.Type = "string"
+		}
+	case "integer":
+		field.Type = "int"
+		if format == "int64" {
+			field.Type = "int64"
+		}
+	case "number":
+		// A float unless the document asks for exact values.
+		field.Type = "float"
+		if format == "decimal" || d.value(p, "multipleOf") != "" {
+			field.Type = "decimal"
+		}
+	case "boolean":
+		field.Type = "bool"
+	case "":
+		d.warnf("module %s: property %s has no type; it is not generated", moduleName, name)
+		return field, false
+	default:
+		d.warnf("module %s: property %s is an %s; it is not generated", moduleName, name, typ)
+		return field, false
+	}
+	if n, err := strconv.Atoi(d.value(p, "maxLength")); err == nil && (field.Type == "string" || field.Type == "email") {
+		field.Size = n
+	}
+	field.Validate = d.rules(moduleName, name, field, p)
+	return field, true
+}
+
+// rules returns the validate tag for a property's constraints, or "" when it
+// has none beyond its type, leaving the type's rules.
+func (d *openAPIDoc) rules(moduleName, name string, field SchemaField, p *yaml.Node) string {
+	if d.value(p, "pattern") != "" {
+		d.warnf("module %s: the pattern of property %s is not validated", moduleName, name)
+	}
+	var constraints []string
+	if v := d.value(p, "minLength"); v != "" {
+		constraints = append(constraints, "min="+v)
+	}
+	for _, bound := range []struct{ key, exclusive, inclusive, strict string }{
+		{"minimum", "exclusiveMinimum", "gte", "gt"},
+		{"maximum", "exclusiveMaximum", "lte", "lt"},
+	} {
+		v, exclusive := d.value(p, bound.key), d.value(p, bound.exclusive)
+		switch {
+		case v != "" && exclusive == "true": // OpenAPI 3.0: a flag on the bound
+			constraints = append(constraints, bound.strict+"="+v)
+		case v != "":
+			constraints = append(constraints, bound.inclusive+"="+v)
+		}
+		if exclusive != "" && exclusive != "true" && exclusive != "false" { // OpenAPI 3.1: the bound itself
+			constraints = append(constraints, bound.strict+"="+exclusive)
+		}
+	}
+	if enum := d.field(p, "enum"); enum != nil {
+		var values []string
+		for _, v := range enum.Content {
+			if v.Value == "" || strings.ContainsAny(v.Value, " ,|") {
+				d.warnf("module %s: enum of property %s has values validate cannot list; it is not validated", moduleName, name)
+				values = nil
+				break
+			}
+			values = append(values, v.Value)
+		}
+		if len(values) > 0 {
+			constraints = append(constraints, "oneof="+strings.Join(values, " "))
+		}
+	}
+	if len(constraints) == 0 {
+		if fieldTypes[field.Type].validate == "gte=0" {
+			return "-" // the bounds are the document's, not the type's
+		}
+		return ""
+	}
+
+	var rules []string
+	if field.Optional {
+		rules = append(rules, "omitempty")
+	} else if strings.HasPrefix(fieldTypes[field.Type].validate, "required") {
+		rules = append(rules, "required")
+	}
+	if field.Type == "email" || field.Type == "uuid" || field.Type == "ulid" {
+		rules = append(rules, field.Type)
+	}
+	rules = append(rules, constraints...)
+	if field.Type == "string" || field.Type == "email" {
+		size := field.Size
+		if size == 0 {
+			size = 255 // the column's default size
+		}
+		rules = append(rules, fmt.Sprintf("max=%d", size))
+	}
+	return strings.Join(rules, ",")
+}
+
+// resolve follows $ref to components of the document.
+func (d *openAPIDoc) resolve(n *yaml.Node) *yaml.Node {
+	for i := 0; n != nil && i < 32; i++ {
+		ref := d.value(n, "$ref")
+		if ref == "" {
+			return n
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			d.warnf("external reference %s is not followed", ref)
+			return nil
+		}
+		target := d.root
+		for _, key := range strings.Split(ref[2:], "/") {
+			key = strings.ReplaceAll(strings.ReplaceAll(key, "~1", "/"), "~0", "~")
+			if target = d.field(target, key); target == nil {
+				d.warnf("reference %s not found", ref)
+				return nil
+			}
+		}
+		n = target
+	}
+	return n
+}
+
+// rawField returns the value of key in a mapping node as written, without
+// following its $ref.
+func (d *openAPIDoc) rawField(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// field returns the value of key in a mapping node, following $ref.
+func (d *openAPIDoc) field(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			if key == "$ref" {
+				return n.Content[i+1]
+			}
+			return d.resolve(n.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// value returns the scalar value of key in a mapping node.
+func (d *openAPIDoc) value(n *yaml.Node, key string) string {
+	v := d.field(n, key)
+	if v == nil || v.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return v.Value
+}
+
+// keys returns the keys of a mapping node.
+func (d *openAPIDoc) keys(n *yaml.Node) []string {
+	var keys []string
+	if n != nil && n.Kind == yaml.MappingNode {
+		for i := 0; i < len(n.Content); i += 2 {
+			keys = append(keys, n.Content[i].Value)
+		}
+	}
+	return keys
+}
+
+// toSnakeCase converts a camelCase or kebab-case property name to snake_case
+// ("unitPrice" -> "unit_price").
+func toSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '-':
+			b.WriteByte('_')
+		case r >= 'A' && r <= 'Z':
+			if i > 0 && !isUpper(s[i-1]) && s[i-1] != '_' && s[i-1] != '-' {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r + 'a' - 'A')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "untyped"
+	}
+	return s
+}
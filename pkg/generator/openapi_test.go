@@ -0,0 +1,367 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testOpenAPI = `openapi: 3.0.3
+info: {title: Shop, version: "1.0"}
+servers:
+  - url: https://shop.example.com/api/v1
+paths:
+  /products:
+    get:
+      parameters:
+        - {name: status, in: query, schema: {type: string}}
+        - {name: page, in: query, schema: {type: integer}}
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  data: {type: array, items: {$ref: "#/components/schemas/Product"}}
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/ProductInput"}
+      responses:
+        "200": {description: created}
+  /products/{productId}:
+    parameters:
+      - {name: productId, in: path, required: true, schema: {type: integer}}
+    get:
+      responses: {"200": {description: ok}}
+    delete:
+      responses: {"204": {description: deleted}}
+  /customers/{id}/orders:
+    get:
+      responses: {"200": {description: ok}}
+  /order-notes:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  required: [body]
+                  properties:
+                    id: {type: integer, readOnly: true}
+                    body: {type: string, minLength: 1}
+                    authorEmail: {type: string, format: email, nullable: true}
+  /order-notes/{id}:
+    get:
+      responses: {"200": {description: ok}}
+  /health:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema: {type: object, properties: {status: {type: string}}}
+components:
+  schemas:
+    ProductInput:
+      allOf:
+        - $ref: "#/components/schemas/ProductBase"
+        - type: object
+          required: [price]
+          properties:
+            price: {type: number, multipleOf: 0.01, minimum: 0, exclusiveMinimum: true}
+            weight: {type: number}
+            discount: {type: number, format: decimal}
+            stock: {type: integer, format: int64, maximum: 1000}
+            tags: {type: array, items: {type: string}}
+    ProductBase:
+      type: object
+      required: [name, status]
+      properties:
+        name: {type: string, maxLength: 80}
+        status: {type: string, enum: [draft, active]}
+        sku: {type: string, format: uuid}
+        batch: {type: string, format: ulid, minLength: 26}
+        released_at: {type: string, format: date-time}
+    Product:
+      allOf:
+        - $ref: "#/components/schemas/ProductInput"
+        - properties:
+            id: {type: integer, readOnly: true}
+`
+
+func TestParseOpenAPI(t *testing.T) {
+	schema, warnings, err := ParseOpenAPI([]byte(testOpenAPI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SchemaModule{
+		{
+			Name: "product",
+			Fields: []SchemaField{
+				{Name: "name", Type: "string", Size: 80},
+				{Name: "status", Type: "string", Validate: "required,oneof=draft active,max=255"},
+				{Name: "sku", Type: "uuid", Optional: true},
+				{Name: "batch", Type: "ulid", Optional: true, Validate: "omitempty,ulid,min=26"},
+				{Name: "released_at", Type: "time", Optional: true},
+				{Name: "price", Type: "decimal", Validate: "gt=0"},
+				{Name: "weight", Type: "float", Optional: true},
+				{Name: "discount", Type: "decimal", Optional: true, Validate: "-"},
+				{Name: "stock", Type: "int64", Optional: true, Validate: "omitempty,lte=1000"},
+			},
+			Endpoints: []string{"list", "get", "create", "delete"},
+			Filters:   []string{"status"},
+			Status:    map[string]int{"create": 200},
+		},
+		{
+			Name: "order_note",
+			Fields: []SchemaField{
+				{Name: "body", Type: "string", Validate: "required,min=1,max=255"},
+				{Name: "author_email", Type: "email", Optional: true},
+			},
+			Endpoints: []string{"list", "get"},
+		},
+	}
+	if !reflect.DeepEqual(schema.Modules, want) {
+		t.Errorf("ParseOpenAPI() modules =\n%+v\nwant\n%+v", schema.Modules, want)
+	}
+	wantWarnings := []string{
+		"path /customers/{id}/orders skipped: nested resources are not generated",
+		"path /order-notes is served at /api/v1/order_notes",
+		"path /health skipped: it is not a resource (no collection and item paths, request body or component schema)",
+		"module product: property tags is an array; it is not generated",
+		"module order_note: property authorEmail is generated as author_email",
+		"GET /products: query parameter page is not a field; it is not generated",
+	}
+	if !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("ParseOpenAPI() warnings =\n%q\nwant\n%q", warnings, wantWarnings)
+	}
+}
+
+func TestParseOpenAPI_Relations(t *testing.T) {
+	doc := `openapi: 3.0.3
+servers: [{url: /api/v1}]
+paths:
+  /customers: {post: {requestBody: {content: {application/json: {schema: {$ref: "#/components/schemas/Customer"}}}}}}
+  /tags: {post: {requestBody: {content: {application/json: {schema: {$ref: "#/components/schemas/Tag"}}}}}}
+  /orders:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [customer_id]
+              properties:
+                customer_id: {type: integer}
+                tag_ids: {type: array, items: {type: integer}}
+                items: {type: array, items: {$ref: "#/components/schemas/OrderItem"}}
+                labels: {type: array, items: {$ref: "#/components/schemas/Tag"}}
+  /order_items: {post: {requestBody: {content: {application/json: {schema: {$ref: "#/components/schemas/OrderItem"}}}}}}
+components:
+  schemas:
+    Customer:
+      type: object
+      properties:
+        name: {type: string}
+        referrer: {$ref: "#/components/schemas/Customer"}
+    Tag:
+      type: object
+      properties:
+        label: {type: string}
+    OrderItem:
+      type: object
+      required: [order_id, qty]
+      properties:
+        order_id: {type: integer}
+        qty: {type: integer}
+`
+	schema, warnings, err := ParseOpenAPI([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SchemaModule{
+		{Name: "customer", Endpoints: []string{"create"}, Fields: []SchemaField{
+			{Name: "name", Type: "string", Optional: true},
+			{Name: "referrer", Type: "belongs_to", Optional: true, Model: "customer"},
+		}},
+		{Name: "tag", Endpoints: []string{"create"}, Fields: []SchemaField{{Name: "label", Type: "string", Optional: true}}},
+		{Name: "order", Endpoints: []string{"create"}, Fields: []SchemaField{
+			{Name: "customer", Type: "belongs_to"},
+			{Name: "tags", Type: "many2many"},
+			{Name: "items", Type: "has_many", Model: "order_item"},
+			{Name: "labels", Type: "many2many", Model: "tag"},
+		}},
+		{Name: "order_item", Endpoints: []string{"create"}, Fields: []SchemaField{
+			{Name: "order", Type: "belongs_to"},
+			{Name: "qty", Type: "int"},
+		}},
+	}
+	if !reflect.DeepEqual(schema.Modules, want) {
+		t.Errorf("ParseOpenAPI() modules =\n%+v\nwant\n%+v", schema.Modules, want)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("ParseOpenAPI() warnings = %q", warnings)
+	}
+}
+
+func TestParseOpenAPI_IDs(t *testing.T) {
+	doc := `openapi: 3.0.3
+servers: [{url: /api/v1}]
+paths:
+  /accounts: {post: {requestBody: {content: {application/json: {schema: {$ref: "#/components/schemas/Account"}}}}}}
+  /accounts/{id}:
+    parameters: [{name: id, in: path, required: true, schema: {type: string, format: uuid}}]
+    get: {responses: {"200": {description: ok}}}
+  /sessions: {post: {requestBody: {content: {application/json: {schema: {$ref: "#/components/schemas/Session"}}}}}}
+  /sessions/{id}:
+    delete: {parameters: [{name: id, in: path, required: true, schema: {type: string, format: ulid}}]}
+  /notes: {post: {requestBody: {content: {application/json: {schema: {$ref: "#/components/schemas/Note"}}}}}}
+components:
+  schemas:
+    Account:
+      type: object
+      properties:
+        email: {type: string}
+    Session:
+      type: object
+      required: [account_id]
+      properties:
+        account_id: {type: string, format: uuid}
+    Note:
+      type: object
+      properties:
+        account_id: {type: integer}
+        session_ids: {type: array, items: {type: string, format: ulid}}
+`
+	schema, warnings, err := ParseOpenAPI([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SchemaModule{
+		{Name: "account", ID: "uuid", Endpoints: []string{"get", "create"}, Fields: []SchemaField{{Name: "email", Type: "string", Optional: true}}},
+		{Name: "session", ID: "ulid", Endpoints: []string{"create", "delete"}, Fields: []SchemaField{{Name: "account", Type: "belongs_to"}}},
+		// An integer cannot hold the uuid of an account.
+		{Name: "note", Endpoints: []string{"create"}, Fields: []SchemaField{
+			{Name: "account_id", Type: "int", Optional: true},
+			{Name: "sessions", Type: "many2many"},
+		}},
+	}
+	if !reflect.DeepEqual(schema.Modules, want) {
+		t.Errorf("ParseOpenAPI() modules =\n%+v\nwant\n%+v", schema.Modules, want)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("ParseOpenAPI() warnings = %q", warnings)
+	}
+}
+
+func TestParseOpenAPI_UnresolvedPathItems(t *testing.T) {
+	doc := `openapi: 3.0.3
+paths:
+  /products: {$ref: "other.yaml#/paths/products"}
+  /customers: {$ref: "#/components/pathItems/Customers"}
+  /tags:
+    get: {}
+  /tags/{id}:
+    get: {}
+`
+	schema, warnings, err := ParseOpenAPI([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Modules) != 1 || schema.Modules[0].Name != "tag" {
+		t.Errorf("ParseOpenAPI() modules = %+v, want only tag", schema.Modules)
+	}
+	wantWarnings := []string{
+		"external reference other.yaml#/paths/products is not followed",
+		"reference #/components/pathItems/Customers not found",
+		"path /tags is served at /api/v1/tags",
+		"module tag: no request or response schema; it gets a name field",
+	}
+	if !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("ParseOpenAPI() warnings =\n%q\nwant\n%q", warnings, wantWarnings)
+	}
+}
+
+func TestParseOpenAPI_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{"swagger 2", "swagger: \"2.0\"\npaths: {}\n", "swagger 2.0 documents are not supported; convert to OpenAPI 3 first"},
+		{"not openapi", "modules: []\n", "not an OpenAPI 3 document (missing openapi: 3.x)"},
+		{"no resources", "openapi: 3.1.0\npaths:\n  /users/{id}/orders:\n    get: {}\n", "no resource paths found (e.g. /products and /products/{id})"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseOpenAPI([]byte(tt.doc))
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ParseOpenAPI() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApply_OpenAPI(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	schema, _, err := ParseOpenAPI([]byte(testOpenAPI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Apply(schema); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]string{
+		"internal/requests/product_request.go": {
+			"type ListProductRequest struct {\n\tStatus *string `query:\"status\"`\n}",
+		},
+		"internal/controllers/product_controller.go": {
+			`// @Param        status  query  string  false  "Filter by status"`,
+			"data, err := c.service.List(page, &req)",
+			`return responses.NewSuccessResponse(ctx, "Product created successfully", data)`,
+			"return responses.NewNoContentResponse(ctx)",
+		},
+		"internal/services/product_service.go": {
+			"List(page paginates.PaginateRequest, req *requests.ListProductRequest) (*paginates.PaginatedResponse, error)",
+			"conditions[\"status\"] = *req.Status",
+		},
+		"internal/repositories/product_repository.go": {
+			"query := r.db.Model(&models.Product{}).Where(conditions)",
+		},
+		"tests/mocks/product_repository_mock.go": {
+			"FindAll(page paginates.PaginateRequest, conditions map[string]interface{}) (*paginates.PaginatedResponse, error)",
+		},
+	}
+	for name, wants := range files {
+		content := mustRead(t, mem, name)
+		for _, want := range wants {
+			if !strings.Contains(content, want) {
+				t.Errorf("%s does not contain %s:\n%s", name, want, content)
+			}
+		}
+	}
+
+	// auto-test finds the filters in the requests file.
+	if err := g.GenerateAutoServiceTests("product", "example.com/app", true); err != nil {
+		t.Fatal(err)
+	}
+	if test := mustRead(t, mem, "tests/services/product_service_test.go"); !strings.Contains(test, "svc.List(paginates.PaginateRequest{}, &requests.ListProductRequest{})") ||
+		!strings.Contains(test, `repo.On("FindAll", mock.Anything, mock.Anything)`) {
+		t.Errorf("auto-test does not call the filtered List:\n%s", test)
+	}
+}
@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// paginationModes lists how a module's list endpoint can paginate; the first
+// is the default.
+var paginationModes = []string{"offset", "cursor"}
+
+// PaginationModes returns the names accepted as a module's pagination:
+// "offset" (page and limit, with the total count; the default) and "cursor"
+// (after and before cursors, without counting, for large tables).
+func PaginationModes() []string {
+	return slices.Clone(paginationModes)
+}
+
+// normalizePagination returns the recorded form of the pagination mode,
+// which is empty for the default offset.
+func normalizePagination(mode string) string {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == paginationModes[0] {
+		return ""
+	}
+	return mode
+}
+
+// validatePagination returns an error when mode is not one of
+// PaginationModes.
+func validatePagination(mode string) error {
+	if mode != "" && !slices.Contains(paginationModes, strings.ToLower(strings.TrimSpace(mode))) {
+		return fmt.Errorf("unsupported pagination %q (use one of: %s)", mode, strings.Join(paginationModes, ", "))
+	}
+	return nil
+}
+
+// detectPagination returns the pagination mode of the generated repository
+// of moduleName, empty for offset or when it does not exist.
+func (g *Generator) detectPagination(moduleName string) string {
+	content, err := g.readFile(fmt.Sprintf("internal/repositories/%s_repository.go", moduleName))
+	if err == nil && strings.Contains(string(content), "paginates.PaginateCursor(") {
+		return "cursor"
+	}
+	return ""
+}
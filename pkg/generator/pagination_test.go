@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateModuleWithOptions_Pagination(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	opts := ModuleOptions{ID: "int64", Pagination: "cursor"}
+	if err := g.GenerateModuleWithOptions("audit_log", opts, "action:string", "at:time"); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]string{
+		"internal/repositories/audit_log_repository.go": {
+			"FindAll(page paginates.PaginateRequest) (*paginates.CursorPaginatedResponse, error)",
+			"return paginates.PaginateCursor(query, page, auditLogColumns, &entities)",
+		},
+		"internal/services/audit_log_service.go": {
+			"List(page paginates.PaginateRequest) (*paginates.CursorPaginatedResponse, error)",
+		},
+		"internal/controllers/audit_log_controller.go": {
+			`// @Param        after       query  string  false  "next_cursor of the previous page"`,
+			"// @Success      200  {object}  paginates.CursorPaginatedResponse",
+		},
+		"tests/mocks/audit_log_repository_mock.go": {
+			"args.Get(0).(*paginates.CursorPaginatedResponse)",
+		},
+		"paginates/pagination.go": {
+			"func PaginateCursor(db *gorm.DB, paginate PaginateRequest, columns Columns, resultModel interface{}) (*CursorPaginatedResponse, error)",
+		},
+	}
+	for name, wants := range files {
+		content := mustRead(t, mem, name)
+		for _, want := range wants {
+			if !strings.Contains(content, want) {
+				t.Errorf("%s does not contain %s:\n%s", name, want, content)
+			}
+		}
+	}
+	if controller := mustRead(t, mem, "internal/controllers/audit_log_controller.go"); strings.Contains(controller, "Page number") {
+		t.Errorf("cursor controller documents page:\n%s", controller)
+	}
+
+	// The pagination is read back from the repository.
+	if err := g.GenerateAutoServiceTests("audit_log", "example.com/app", true); err != nil {
+		t.Fatal(err)
+	}
+	if test, want := mustRead(t, mem, "tests/services/audit_log_service_test.go"), "Return(&paginates.CursorPaginatedResponse{"; !strings.Contains(test, want) {
+		t.Errorf("service test does not contain %s:\n%s", want, test)
+	}
+	result, err := g.Upgrade()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Upgraded) > 0 || len(result.Conflicts) > 0 {
+		t.Errorf("Upgrade() changed files: %+v", result)
+	}
+
+	err = g.GenerateModuleWithOptions("invoice", ModuleOptions{Pagination: "keyset"})
+	if want := `unsupported pagination "keyset" (use one of: offset, cursor)`; err == nil || err.Error() != want {
+		t.Errorf("GenerateModuleWithOptions() error = %v, want %q", err, want)
+	}
+}
+
+func TestApply_Pagination(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	// The project default applies to modules that do not override it.
+	schema, err := ParseSchema([]byte(`pagination: cursor
+modules:
+  - name: event
+    fields:
+      - {name: kind, type: string}
+      - {name: note, type: string, optional: true}
+  - name: product
+    pagination: offset
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Apply(schema); err != nil {
+		t.Fatal(err)
+	}
+	// Cursors cannot point past NULLs, so optional columns do not sort.
+	event := mustRead(t, mem, "internal/repositories/event_repository.go")
+	for _, want := range []string{"paginates.PaginateCursor(", `Sort:   []string{"id", "kind", "created_at", "updated_at"},`} {
+		if !strings.Contains(event, want) {
+			t.Errorf("event repository does not contain %s:\n%s", want, event)
+		}
+	}
+	if product := mustRead(t, mem, "internal/repositories/product_repository.go"); !strings.Contains(product, "paginates.Paginate(query, page, &entities)") {
+		t.Errorf("product repository does not paginate by offset:\n%s", product)
+	}
+
+	_, err = ParseSchema([]byte("pagination: pages\nmodules:\n  - name: event\n    pagination: keyset\n"))
+	want := "unsupported pagination \"pages\" (use one of: offset, cursor)\n" +
+		"module event: unsupported pagination \"keyset\" (use one of: offset, cursor)"
+	if err == nil || err.Error() != want {
+		t.Errorf("ParseSchema() error = %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,204 @@
+package generator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// Change actions reported in a dry-run plan.
+const (
+	ActionCreate    = "create"
+	ActionOverwrite = "overwrite"
+	ActionSkip      = "skip"
+	ActionPatch     = "patch"
+	ActionRun       = "run"
+	ActionDelete    = "delete"
+)
+
+// PlannedChange is a single step recorded during a dry run.
+type PlannedChange struct {
+	Action string // one of the Action* constants
+	Path   string // file or directory, or the command for ActionRun
+}
+
+// TakePlan returns the changes recorded since the last call and forgets the
+// in-memory files of the dry run.
+func (g *Generator) TakePlan() []PlannedChange {
+	plan := g.plan
+	g.plan = nil
+	g.overlay = nil
+	return plan
+}
+
+// recordChange adds a change to the plan and prints it. A path recorded twice
+// keeps its latest action, except that a file created earlier in the same run
+// stays a create.
+func (g *Generator) recordChange(action, path string) {
+	for i, c := range g.plan {
+		if c.Path != path || c.Action == ActionRun {
+			continue
+		}
+		if c.Action != ActionCreate && c.Action != action {
+			g.plan[i].Action = action
+			fmt.Printf("[dry-run] %-9s %s\n", action, path)
+		}
+		return
+	}
+	g.plan = append(g.plan, PlannedChange{Action: action, Path: path})
+	fmt.Printf("[dry-run] %-9s %s\n", action, path)
+}
+
+// fsys returns the FS the generator works on.
+func (g *Generator) fsys() FS {
+	if g.FS != nil {
+		return g.FS
+	}
+	return DirFS(g.Root)
+}
+
+// onDisk reports whether the generator writes to a directory on disk, where
+// commands such as go mod init can run.
+func (g *Generator) onDisk() bool {
+	_, ok := g.fsys().(dirFS)
+	return ok
+}
+
+// fsName converts a project-relative path to an FS name.
+func fsName(rel string) string {
+	return filepath.ToSlash(filepath.Clean(rel))
+}
+
+// readFile reads the project file rel, preferring the in-memory copy during a
+// dry run.
+func (g *Generator) readFile(rel string) ([]byte, error) {
+	content, ok := g.overlay[fsName(rel)]
+	if !g.DryRun || !ok {
+		if g.staged != nil {
+			content, ok = g.staged.files[fsName(rel)]
+		}
+	}
+	if !ok {
+		return g.fsys().ReadFile(fsName(rel))
+	}
+	if content == nil {
+		return nil, &fs.PathError{Op: "read", Path: rel, Err: fs.ErrNotExist}
+	}
+	return content, nil
+}
+
+// fileExists reports whether the project file rel exists in the FS or in the
+// dry-run overlay.
+func (g *Generator) fileExists(rel string) bool {
+	_, err := g.readFile(rel)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+	if err == nil {
+		return true
+	}
+	_, err = g.fsys().Stat(fsName(rel))
+	return err == nil
+}
+
+// writeFile writes content to the project file rel, creating parent
+// directories. During a dry run it only records action and keeps the content
+// in memory; patches that leave the file unchanged are recorded as skips.
+// Inside a transaction the content is staged until the transaction commits.
+func (g *Generator) writeFile(rel string, content []byte, action string) error {
+	if g.DryRun {
+		if action == ActionPatch {
+			if current, err := g.readFile(rel); err == nil && bytes.Equal(current, content) {
+				action = ActionSkip
+			}
+		}
+		if g.overlay == nil {
+			g.overlay = map[string][]byte{}
+		}
+		g.overlay[fsName(rel)] = content
+		g.recordChange(action, rel)
+		return nil
+	}
+	if g.staged != nil {
+		g.staged.write(fsName(rel), content)
+		return nil
+	}
+	if err := g.fsys().WriteFile(fsName(rel), content); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", rel, err)
+	}
+	return nil
+}
+
+// removeFile deletes the project file rel. During a dry run or inside a
+// transaction the deletion is only recorded.
+func (g *Generator) removeFile(rel string) error {
+	if g.DryRun {
+		if g.overlay == nil {
+			g.overlay = map[string][]byte{}
+		}
+		g.overlay[fsName(rel)] = nil
+		g.recordChange(ActionDelete, rel)
+		return nil
+	}
+	if g.staged != nil {
+		g.staged.write(fsName(rel), nil)
+		return nil
+	}
+	if err := g.fsys().Remove(fsName(rel)); err != nil {
+		return fmt.Errorf("failed to delete file %s: %w", rel, err)
+	}
+	return nil
+}
+
+// mkdir creates the project directory rel if it does not exist yet.
+func (g *Generator) mkdir(rel string) error {
+	if _, err := g.fsys().Stat(fsName(rel)); !errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if g.DryRun {
+		g.recordChange(ActionCreate, rel+"/")
+		return nil
+	}
+	if g.staged != nil {
+		g.staged.dirs = append(g.staged.dirs, fsName(rel))
+		return nil
+	}
+	return g.fsys().MkdirAll(fsName(rel))
+}
+
+// confirmOverwrite shows the diff of path against content, asks
+// ConfirmOverwrite and writes the backup. It reports whether path may be
+// overwritten.
+func (g *Generator) confirmOverwrite(path string, content []byte) (bool, error) {
+	current, err := g.readFile(path)
+	if err != nil {
+		return false, err
+	}
+	diff := unifiedDiff(path, string(current), string(content))
+	if diff == "" {
+		return true, nil
+	}
+	if g.ShowDiff {
+		fmt.Print(diff)
+		if g.ConfirmOverwrite != nil && !g.DryRun && !g.ConfirmOverwrite(path) {
+			fmt.Println("Kept file:", path)
+			return false, nil
+		}
+	}
+	if g.Backup {
+		backupPath := path + ".orig"
+		action := ActionCreate
+		if g.fileExists(backupPath) {
+			action = ActionOverwrite
+		}
+		if err := g.writeFile(backupPath, current, action); err != nil {
+			return false, err
+		}
+		if !g.DryRun {
+			fmt.Println("Backed up file:", backupPath)
+		}
+	}
+	return true, nil
+}
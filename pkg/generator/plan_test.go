@@ -0,0 +1,222 @@
+package generator
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDryRun_Init(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	DryRun = true
+	defer func() { DryRun = false; TakePlan() }()
+
+	if err := Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	plan := TakePlan()
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("dry run wrote %d entries to disk", len(entries))
+	}
+
+	actions := map[string]string{}
+	for _, c := range plan {
+		if c.Action != ActionRun {
+			actions[c.Path] = c.Action
+		}
+	}
+	tests := []struct {
+		path   string
+		action string
+	}{
+		{"go.mod", ActionCreate},
+		{"main.go", ActionCreate},
+		{"routes/fiber_routes.go", ActionCreate},
+		{"migrations/migrations.go", ActionCreate},
+		{"internal/models/example.go", ActionCreate},
+		{"tests/services/example_service_test.go", ActionCreate},
+	}
+	for _, tt := range tests {
+		if got := actions[tt.path]; got != tt.action {
+			t.Errorf("plan for %s = %q, want %q", tt.path, got, tt.action)
+		}
+	}
+	if action, ok := actions[ManifestFile]; ok {
+		t.Errorf("plan for %s = %q, want none", ManifestFile, action)
+	}
+	if plan[0] != (PlannedChange{Action: ActionCreate, Path: "go.mod"}) {
+		t.Errorf("first planned change = %+v, want go.mod to be created", plan[0])
+	}
+}
+
+func TestDryRun_Module(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("go.mod", []byte("module example.com/app\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenerateInitialStructure("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	before := snapshotDir(t, ".")
+
+	DryRun = true
+	defer func() { DryRun = false; TakePlan() }()
+
+	if err := GenerateModule("example"); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenerateModule("order", "total:decimal"); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenerateTestFilesForce("example", "example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+
+	actions := map[string]string{}
+	for _, c := range TakePlan() {
+		actions[c.Path] = c.Action
+	}
+	tests := []struct {
+		path   string
+		action string
+	}{
+		{"internal/models/example.go", ActionSkip},
+		{"internal/models/order.go", ActionCreate},
+		{"tests/fixtures/example_fixture.go", ActionOverwrite},
+		{"migrations/migrations.go", ActionPatch},
+		{"main.go", ActionPatch},
+		{"routes/fiber_routes.go", ActionPatch},
+	}
+	for _, tt := range tests {
+		if got := actions[tt.path]; got != tt.action {
+			t.Errorf("plan for %s = %q, want %q", tt.path, got, tt.action)
+		}
+	}
+
+	if action, ok := actions[ManifestFile]; ok {
+		t.Errorf("plan for %s = %q, want none", ManifestFile, action)
+	}
+
+	after := snapshotDir(t, ".")
+	if len(after) != len(before) {
+		t.Errorf("dry run changed the number of files from %d to %d", len(before), len(after))
+	}
+	for path, content := range before {
+		if after[path] != content {
+			t.Errorf("dry run modified %s", path)
+		}
+	}
+}
+
+// snapshotDir returns the content of every file below dir.
+func snapshotDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		files[path] = string(content)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestForceOverwrite_ConfirmAndBackup(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenerateTestFiles("order", "example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	testPath := filepath.Join("tests", "services", "order_service_test.go")
+	fixturePath := filepath.Join("tests", "fixtures", "order_fixture.go")
+	custom, _ := os.ReadFile(testPath)
+	custom = append(custom, "\n// hand-written case\n"...)
+	if err := os.WriteFile(testPath, custom, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var asked []string
+	ShowDiff, Backup = true, true
+	ConfirmOverwrite = func(path string) bool {
+		asked = append(asked, path)
+		return false
+	}
+	defer func() { ShowDiff, Backup, ConfirmOverwrite = false, false, nil }()
+
+	// Declining keeps the customized file and writes no backup; unchanged
+	// files are not asked about.
+	if err := GenerateTestFilesForce("order", "example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	if len(asked) != 1 || asked[0] != testPath {
+		t.Errorf("asked about %v, want only %s", asked, testPath)
+	}
+	if got, _ := os.ReadFile(testPath); string(got) != string(custom) {
+		t.Error("declined overwrite modified the test file")
+	}
+	if _, err := os.Stat(fixturePath + ".orig"); err == nil {
+		t.Error("unchanged fixture was backed up")
+	}
+	if _, err := os.Stat(testPath + ".orig"); err == nil {
+		t.Error("declined overwrite wrote a backup")
+	}
+
+	ConfirmOverwrite = func(string) bool { return true }
+	if err := GenerateTestFilesForce("order", "example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(testPath + ".orig"); string(got) != string(custom) {
+		t.Error("backup does not hold the customized test file")
+	}
+	if got, _ := os.ReadFile(testPath); string(got) == string(custom) {
+		t.Error("confirmed overwrite kept the customized test file")
+	}
+}
+
+func TestRenderTestFiles_Counts(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{ShowDiff: true})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	files := append([]testFile{{"templates/service_test.tmpl", "tests/services/order_service_test.go"}}, testSupportFiles("order")...)
+	data := moduleOptions{}.moduleData("example.com/app", "order", defaultFields())
+	created, kept, err := g.renderTestFiles(files, data, false)
+	if err != nil || created != 3 || kept != 0 {
+		t.Fatalf("renderTestFiles() = %d, %d, %v, want 3 created", created, kept, err)
+	}
+
+	_ = mem.WriteFile("tests/services/order_service_test.go", []byte(mustRead(t, mem, "tests/services/order_service_test.go")+"\n// hand-written case\n"))
+	g.ConfirmOverwrite = func(string) bool { return false }
+	// Declined and unchanged files are not counted as created.
+	created, kept, err = g.renderTestFiles(files, data, true)
+	if err != nil || created != 0 || kept != 1 {
+		t.Errorf("renderTestFiles() after declining = %d created, %d kept, %v; want 0 created, 1 kept", created, kept, err)
+	}
+}
@@ -0,0 +1,559 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	mainFile        = "main.go"
+	fiberRoutesFile = "routes/fiber_routes.go"
+	migrationsFile  = "migrations/migrations.go"
+)
+
+// errUnrecognized is returned when main.go or routes/fiber_routes.go no longer
+// have the shape produced by the generator.
+var errUnrecognized = errors.New("file was restructured beyond recognition")
+
+// moduleWiring holds the identifiers used to wire a module into main.go and
+// routes/fiber_routes.go.
+type moduleWiring struct {
+	ModuleName    string // "user_account"
+	ModelName     string // "UserAccount"
+	RepoVar       string // "userAccountRepo"
+	ServiceVar    string // "userAccountService"
+	ControllerVar string // "userAccountController"
+}
+
+func newModuleWiring(moduleName string) moduleWiring {
+	upper := toPascal(moduleName)
+	lower := strings.ToLower(upper[:1]) + upper[1:]
+	return moduleWiring{
+		ModuleName:    moduleName,
+		ModelName:     upper,
+		RepoVar:       lower + "Repo",
+		ServiceVar:    lower + "Service",
+		ControllerVar: lower + "Controller",
+	}
+}
+
+// edit replaces remove bytes at a byte offset of a source file with text.
+type edit struct {
+	offset int
+	text   string
+	remove int
+}
+
+// applyEdits applies all edits to src and formats the result with formatGo.
+// It fails when the edited source does not parse, leaving the caller free to
+// abort.
+func applyEdits(src []byte, edits []edit, projectName string) ([]byte, error) {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].offset > edits[j].offset })
+	out := append([]byte(nil), src...)
+	for _, e := range edits {
+		out = append(out[:e.offset], append([]byte(e.text), out[e.offset+e.remove:]...)...)
+	}
+	return formatGo(out, projectName)
+}
+
+// registrationTargets are the files RegisterModule rewrites, in write order,
+// with the rewrites that wire a module in and out. Both return nil when the
+// file needs no change.
+var registrationTargets = []struct {
+	path       string
+	rewrite    func(src []byte, w moduleWiring, projectName string) ([]byte, error)
+	unregister func(src []byte, w moduleWiring, projectName string) ([]byte, error)
+}{
+	{mainFile, registerInMain, unregisterInMain},
+	{fiberRoutesFile, registerInRoutes, unregisterInRoutes},
+}
+
+// RegisterModule wires a generated module into main.go (repository, service
+// and controller construction) and routes/fiber_routes.go (field, constructor
+// parameter and RegisterRoutes call). It is idempotent: parts that are already
+// present are left alone. Files that do not exist are skipped; files that no
+// longer look like the generated ones are left untouched and an error is
+// returned.
+func (g *Generator) RegisterModule(moduleName, projectName string) error {
+	moduleName = strings.ToLower(strings.TrimSpace(moduleName))
+	if moduleName == "" {
+		return errors.New("module name must not be empty")
+	}
+	wiring := newModuleWiring(moduleName)
+
+	updates := map[string][]byte{}
+	for _, target := range registrationTargets {
+		src, err := g.readFile(target.path)
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("%s not found, skipping registration of module %s\n", target.path, moduleName)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		out, err := target.rewrite(src, wiring, projectName)
+		if err != nil {
+			return fmt.Errorf("could not register module %s in %s: %w; register it manually", moduleName, target.path, err)
+		}
+		if out != nil {
+			updates[target.path] = out
+		}
+	}
+
+	// Write only after both files were rewritten successfully.
+	return g.transaction(func() error {
+		for _, path := range []string{mainFile, fiberRoutesFile} {
+			out, ok := updates[path]
+			if !ok {
+				continue
+			}
+			if err := g.writeFile(path, out, ActionPatch); err != nil {
+				return err
+			}
+			if err := g.recordRegistration(path, moduleName, out); err != nil {
+				return err
+			}
+			if g.DryRun {
+				continue
+			}
+			fmt.Printf("%s updated with module: %s\n", path, moduleName)
+		}
+		return nil
+	})
+}
+
+// registerInMain returns the rewritten main.go, or nil when the module is
+// already wired.
+func registerInMain(src []byte, w moduleWiring, projectName string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, mainFile, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+
+	mainFunc := findFunc(file, "", "main")
+	if mainFunc == nil || mainFunc.Body == nil {
+		return nil, fmt.Errorf("%w: func main not found", errUnrecognized)
+	}
+
+	var (
+		routesCall     *ast.CallExpr
+		routesStmt     ast.Stmt
+		lastController ast.Stmt
+		defined        = map[string]bool{}
+	)
+	for _, stmt := range mainFunc.Body.List {
+		if assign, ok := stmt.(*ast.AssignStmt); ok {
+			for _, lhs := range assign.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok {
+					defined[ident.Name] = true
+				}
+			}
+			if len(assign.Rhs) == 1 && isSelectorCall(assign.Rhs[0], "controllers", "") {
+				lastController = stmt
+			}
+		}
+		ast.Inspect(stmt, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok && isSelectorCall(call, "routes", "NewFiberRoutes") && routesCall == nil {
+				routesCall = call
+				routesStmt = stmt
+			}
+			return true
+		})
+	}
+	if routesCall == nil {
+		return nil, fmt.Errorf("%w: routes.NewFiberRoutes call not found in main", errUnrecognized)
+	}
+	if !defined["db"] {
+		return nil, fmt.Errorf("%w: db variable not found in main", errUnrecognized)
+	}
+
+	var edits []edit
+	if !defined[w.ControllerVar] {
+		wiring := fmt.Sprintf("// Initialize %s module\n"+
+			"\t%s := repositories.New%sRepository(db)\n"+
+			"\t%s := services.New%sService(%s)\n"+
+			"\t%s := controllers.New%sController(%s)",
+			w.ModuleName,
+			w.RepoVar, w.ModelName,
+			w.ServiceVar, w.ModelName, w.RepoVar,
+			w.ControllerVar, w.ModelName, w.ServiceVar)
+		if lastController != nil {
+			edits = append(edits, edit{offset: offset(lastController.End()), text: "\n\n\t" + wiring})
+		} else {
+			edits = append(edits, edit{offset: offset(leadingComment(fset, file, routesStmt)), text: wiring + "\n\n\t"})
+		}
+
+		importEdits, err := missingImports(file, offset, projectName,
+			"internal/repositories", "internal/services", "internal/controllers")
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, importEdits...)
+	}
+
+	if !hasIdentArg(routesCall.Args, w.ControllerVar) {
+		if n := len(routesCall.Args); n > 0 {
+			edits = append(edits, edit{offset: offset(routesCall.Args[n-1].End()), text: ", " + w.ControllerVar})
+		} else {
+			edits = append(edits, edit{offset: offset(routesCall.Rparen), text: w.ControllerVar})
+		}
+	}
+
+	if len(edits) == 0 {
+		return nil, nil
+	}
+	return applyEdits(src, edits, projectName)
+}
+
+// registerInRoutes returns the rewritten routes/fiber_routes.go, or nil when
+// the module is already wired.
+func registerInRoutes(src []byte, w moduleWiring, projectName string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fiberRoutesFile, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+	line := func(p token.Pos) int { return fset.Position(p).Line }
+	fieldType := "*controllers." + w.ModelName + "Controller"
+
+	structType := findStruct(file, "FiberRoutes")
+	if structType == nil {
+		return nil, fmt.Errorf("%w: type FiberRoutes struct not found", errUnrecognized)
+	}
+	constructor := findFunc(file, "", "NewFiberRoutes")
+	if constructor == nil || constructor.Body == nil {
+		return nil, fmt.Errorf("%w: func NewFiberRoutes not found", errUnrecognized)
+	}
+	literal := findReturnedLiteral(constructor, "FiberRoutes")
+	if literal == nil {
+		return nil, fmt.Errorf("%w: NewFiberRoutes does not return &FiberRoutes{...}", errUnrecognized)
+	}
+	install := findFunc(file, "FiberRoutes", "Install")
+	if install == nil || install.Body == nil || install.Recv.List[0].Names == nil {
+		return nil, fmt.Errorf("%w: method (*FiberRoutes).Install not found", errUnrecognized)
+	}
+	receiver := install.Recv.List[0].Names[0].Name
+
+	var edits []edit
+
+	if !hasFieldNamed(structType.Fields, w.ControllerVar) {
+		field := "\t" + w.ControllerVar + " " + fieldType + "\n"
+		last := structType.Fields.Opening
+		if n := len(structType.Fields.List); n > 0 {
+			last = structType.Fields.List[n-1].End()
+		}
+		if line(structType.Fields.Closing) == line(last) {
+			field = "\n" + field
+		}
+		edits = append(edits, edit{offset: offset(structType.Fields.Closing), text: field})
+	}
+
+	if !hasFieldNamed(structType.Fields, w.ControllerVar) || !hasFieldNamed(constructor.Type.Params, w.ControllerVar) {
+		importEdits, err := missingImports(file, offset, projectName, "internal/controllers")
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, importEdits...)
+	}
+
+	params := constructor.Type.Params
+	if !hasFieldNamed(params, w.ControllerVar) {
+		param := w.ControllerVar + " " + fieldType
+		if n := len(params.List); n > 0 {
+			edits = append(edits, edit{offset: offset(params.List[n-1].End()), text: ", " + param})
+		} else {
+			edits = append(edits, edit{offset: offset(params.Closing), text: param})
+		}
+	}
+
+	if !hasKey(literal, w.ControllerVar) {
+		element := w.ControllerVar + ": " + w.ControllerVar
+		switch n := len(literal.Elts); {
+		case line(literal.Rbrace) > line(literal.Lbrace):
+			edits = append(edits, edit{offset: offset(literal.Rbrace), text: "\t" + element + ",\n"})
+		case n > 0:
+			edits = append(edits, edit{offset: offset(literal.Elts[n-1].End()), text: ", " + element})
+		default:
+			edits = append(edits, edit{offset: offset(literal.Rbrace), text: element})
+		}
+	}
+
+	if !callsRegisterRoutes(install.Body, receiver, w.ControllerVar) {
+		lastRegister, router := lastRegisterRoutes(install.Body)
+		if lastRegister != nil {
+			call := fmt.Sprintf("%s.%s.RegisterRoutes(%s)", receiver, w.ControllerVar, router)
+			edits = append(edits, edit{offset: offset(lastRegister.End()), text: "\n\t" + call})
+		} else {
+			group, router := findGroupAssign(install.Body)
+			if group == nil {
+				return nil, fmt.Errorf("%w: no router group found in Install", errUnrecognized)
+			}
+			call := fmt.Sprintf("%s.%s.RegisterRoutes(%s)", receiver, w.ControllerVar, router)
+			edits = append(edits, edit{offset: offset(group.End()), text: "\n\n\t// Register module routes\n\t" + call})
+			// Drop the "_ = v1" placeholder of projects created without a module.
+			if placeholder := findBlankUse(install.Body, router); placeholder != nil {
+				start, end := offset(placeholder.Pos()), offset(placeholder.End())
+				for end < len(src) && src[end] != '\n' {
+					end++
+				}
+				edits = append(edits, edit{offset: start, remove: end - start})
+			}
+		}
+	}
+
+	if len(edits) == 0 {
+		return nil, nil
+	}
+	return applyEdits(src, edits, projectName)
+}
+
+// leadingComment returns the start of the comment directly above stmt, or of
+// stmt itself when it has none.
+func leadingComment(fset *token.FileSet, file *ast.File, stmt ast.Stmt) token.Pos {
+	stmtLine := fset.Position(stmt.Pos()).Line
+	for _, group := range file.Comments {
+		if fset.Position(group.End()).Line == stmtLine-1 {
+			return group.Pos()
+		}
+	}
+	return stmt.Pos()
+}
+
+// findFunc returns the function (recv == "") or method on recv named name.
+func findFunc(file *ast.File, recv, name string) *ast.FuncDecl {
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != name {
+			continue
+		}
+		if recv == "" && fn.Recv == nil {
+			return fn
+		}
+		if recv != "" && fn.Recv != nil && len(fn.Recv.List) == 1 && receiverType(fn.Recv.List[0].Type) == recv {
+			return fn
+		}
+	}
+	return nil
+}
+
+func receiverType(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// findStruct returns the struct type declared with the given name.
+func findStruct(file *ast.File, name string) *ast.StructType {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if st, ok := ts.Type.(*ast.StructType); ok && ts.Name.Name == name {
+				return st
+			}
+		}
+	}
+	return nil
+}
+
+// findReturnedLiteral returns the &typeName{...} literal returned by fn.
+func findReturnedLiteral(fn *ast.FuncDecl, typeName string) *ast.CompositeLit {
+	for _, stmt := range fn.Body.List {
+		ret, ok := stmt.(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != 1 {
+			continue
+		}
+		unary, ok := ret.Results[0].(*ast.UnaryExpr)
+		if !ok || unary.Op != token.AND {
+			continue
+		}
+		lit, ok := unary.X.(*ast.CompositeLit)
+		if ok && exprString(lit.Type) == typeName {
+			return lit
+		}
+	}
+	return nil
+}
+
+// isSelectorCall reports whether expr is a call pkg.fn(...); an empty fn
+// matches any function of pkg.
+func isSelectorCall(expr ast.Expr, pkg, fn string) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	x, ok := sel.X.(*ast.Ident)
+	return ok && x.Name == pkg && (fn == "" || sel.Sel.Name == fn)
+}
+
+func hasIdentArg(args []ast.Expr, name string) bool {
+	for _, arg := range args {
+		if ident, ok := arg.(*ast.Ident); ok && ident.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func hasFieldNamed(list *ast.FieldList, name string) bool {
+	for _, field := range list.List {
+		for _, ident := range field.Names {
+			if ident.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasKey(lit *ast.CompositeLit, key string) bool {
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if ident, ok := kv.Key.(*ast.Ident); ok && ident.Name == key {
+			return true
+		}
+	}
+	return false
+}
+
+// registerRoutesCall returns the controller field and router argument of a
+// statement of the form recv.field.RegisterRoutes(router).
+func registerRoutesCall(stmt ast.Stmt) (recv, field, router string, ok bool) {
+	expr, isExpr := stmt.(*ast.ExprStmt)
+	if !isExpr {
+		return "", "", "", false
+	}
+	call, isCall := expr.X.(*ast.CallExpr)
+	if !isCall || len(call.Args) != 1 {
+		return "", "", "", false
+	}
+	sel, isSel := call.Fun.(*ast.SelectorExpr)
+	if !isSel || sel.Sel.Name != "RegisterRoutes" {
+		return "", "", "", false
+	}
+	inner, isSel := sel.X.(*ast.SelectorExpr)
+	if !isSel {
+		return "", "", "", false
+	}
+	recvIdent, isIdent := inner.X.(*ast.Ident)
+	routerIdent, isRouter := call.Args[0].(*ast.Ident)
+	if !isIdent || !isRouter {
+		return "", "", "", false
+	}
+	return recvIdent.Name, inner.Sel.Name, routerIdent.Name, true
+}
+
+func callsRegisterRoutes(body *ast.BlockStmt, receiver, field string) bool {
+	for _, stmt := range body.List {
+		if recv, f, _, ok := registerRoutesCall(stmt); ok && recv == receiver && f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func lastRegisterRoutes(body *ast.BlockStmt) (ast.Stmt, string) {
+	var (
+		last   ast.Stmt
+		router string
+	)
+	for _, stmt := range body.List {
+		if _, _, r, ok := registerRoutesCall(stmt); ok {
+			last, router = stmt, r
+		}
+	}
+	return last, router
+}
+
+// findGroupAssign returns the first "x := app.Group(...)" statement and x.
+func findGroupAssign(body *ast.BlockStmt) (ast.Stmt, string) {
+	for _, stmt := range body.List {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			continue
+		}
+		call, ok := assign.Rhs[0].(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Group" {
+			if ident, ok := assign.Lhs[0].(*ast.Ident); ok {
+				return stmt, ident.Name
+			}
+		}
+	}
+	return nil, ""
+}
+
+// findBlankUse returns the "_ = name" statement in body, if any.
+func findBlankUse(body *ast.BlockStmt, name string) ast.Stmt {
+	for _, stmt := range body.List {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+			continue
+		}
+		lhs, _ := assign.Lhs[0].(*ast.Ident)
+		rhs, _ := assign.Rhs[0].(*ast.Ident)
+		if lhs != nil && rhs != nil && lhs.Name == "_" && rhs.Name == name {
+			return stmt
+		}
+	}
+	return nil
+}
+
+// missingImports returns edits adding the given project packages to the
+// parenthesized import block when they are not imported yet.
+func missingImports(file *ast.File, offset func(token.Pos) int, projectName string, packages ...string) ([]edit, error) {
+	imported := map[string]bool{}
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		imported[path] = true
+	}
+
+	var missing []string
+	for _, pkg := range packages {
+		if path := projectName + "/" + pkg; !imported[path] {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT || !gen.Lparen.IsValid() {
+			continue
+		}
+		var text strings.Builder
+		for _, path := range missing {
+			fmt.Fprintf(&text, "\t%q\n", path)
+		}
+		return []edit{{offset: offset(gen.Rparen), text: text.String()}}, nil
+	}
+	return nil, fmt.Errorf("%w: no import block found", errUnrecognized)
+}
@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRegisterModule(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	CreateMainGo("example.com/proj")
+	CreateFiberRoutes("example.com/proj")
+
+	for i := 0; i < 2; i++ {
+		if err := RegisterModule("user_account", "example.com/proj"); err != nil {
+			t.Fatalf("RegisterModule run %d = %v", i+1, err)
+		}
+	}
+
+	mainSrc, _ := os.ReadFile("main.go")
+	routesSrc, _ := os.ReadFile("routes/fiber_routes.go")
+	checks := []struct {
+		src     []byte
+		snippet string
+	}{
+		{mainSrc, "userAccountRepo := repositories.NewUserAccountRepository(db)"},
+		{mainSrc, "userAccountController := controllers.NewUserAccountController(userAccountService)"},
+		{mainSrc, "routes.NewFiberRoutes(exampleController, userAccountController)"},
+		{routesSrc, "userAccountController *controllers.UserAccountController\n"},
+		{routesSrc, "userAccountController *controllers.UserAccountController) *FiberRoutes"},
+		{routesSrc, "userAccountController: userAccountController,"},
+		{routesSrc, "r.userAccountController.RegisterRoutes(v1)"},
+	}
+	for _, c := range checks {
+		if n := strings.Count(string(c.src), c.snippet); n != 1 {
+			t.Errorf("found %q %d times, want exactly once", c.snippet, n)
+		}
+	}
+}
+
+func TestRegisterModule_Unrecognized(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	CreateFiberRoutes("example.com/proj")
+	mainSrc := "package main\n\nfunc main() {\n\tstartServer()\n}\n"
+	if err := os.WriteFile("main.go", []byte(mainSrc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	routesBefore, _ := os.ReadFile("routes/fiber_routes.go")
+
+	err := RegisterModule("product", "example.com/proj")
+	if !errors.Is(err, errUnrecognized) {
+		t.Fatalf("RegisterModule error = %v, want errUnrecognized", err)
+	}
+
+	// Neither file may be touched when one of them cannot be rewritten.
+	if got, _ := os.ReadFile("main.go"); string(got) != mainSrc {
+		t.Error("main.go was modified")
+	}
+	if got, _ := os.ReadFile("routes/fiber_routes.go"); string(got) != string(routesBefore) {
+		t.Error("routes/fiber_routes.go was modified")
+	}
+}
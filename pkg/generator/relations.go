@@ -0,0 +1,213 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// migrationModel matches a model entry of migrations.go, e.g.
+// "&models.OrderItem{}".
+var migrationModel = regexp.MustCompile(`^&models\.(\w+)\{\}$`)
+
+// orderMigrations orders the model entries of migrations.go so that the
+// tables a model references migrate first: belongs_to and many2many models
+// before the model, the model before its has_many children. Models without
+// such a dependency keep the order of entries; cycles are left as they are.
+func (g *Generator) orderMigrations(entries []string) []string {
+	after := map[string][]string{} // entry -> entries that must migrate first
+	byModel := map[string]string{}
+	for _, entry := range entries {
+		if m := migrationModel.FindStringSubmatch(entry); m != nil {
+			byModel[m[1]] = entry
+		}
+	}
+	for model, entry := range byModel {
+		fields, err := g.detectModelFields(toSnakeCase(model))
+		if err != nil {
+			continue
+		}
+		for _, f := range fields {
+			other, ok := byModel[f.ModelName()]
+			if !ok || other == entry {
+				continue
+			}
+			switch f.Relation {
+			case "belongs_to", "many2many":
+				after[entry] = append(after[entry], other)
+			case "has_many":
+				after[other] = append(after[other], entry)
+			}
+		}
+	}
+
+	ordered := make([]string, 0, len(entries))
+	done := map[string]bool{}
+	for len(ordered) < len(entries) {
+		next := ""
+		for _, entry := range entries {
+			if !done[entry] && !slices.ContainsFunc(after[entry], func(e string) bool { return !done[e] }) {
+				next = entry
+				break
+			}
+		}
+		if next == "" { // a cycle; GORM resolves it or reports it
+			for _, entry := range entries {
+				if !done[entry] {
+					next = entry
+					break
+				}
+			}
+		}
+		done[next] = true
+		ordered = append(ordered, next)
+	}
+	return ordered
+}
+
+// relationProblems describes the relations of a module's fields that would
+// not build or migrate: models that do not exist, and has_many children
+// without a foreign key to the module. declared holds the fields of modules
+// about to be generated alongside, e.g. by Apply.
+func (g *Generator) relationProblems(moduleName string, fields []Field, declared map[string][]Field) []string {
+	var problems []string
+	for _, f := range fields {
+		if f.Relation == "" {
+			continue
+		}
+		target, ok := declared[f.Model]
+		if !ok && f.Model != moduleName {
+			if !g.fileExists(filepath.Join(WORKDIR+"models", f.Model+".go")) {
+				problems = append(problems, fmt.Sprintf("field %s: module %s does not exist", f.Association(), f.Model))
+				continue
+			}
+			target, _ = g.detectModelFields(f.Model)
+		}
+		if f.Model == moduleName {
+			target = fields
+		}
+		foreignKey := f.childForeignKey(moduleName)
+		if f.Relation == "has_many" && !slices.ContainsFunc(target, func(child Field) bool { return child.Name == foreignKey }) {
+			problems = append(problems, fmt.Sprintf("field %s: %s has no %s foreign key (add %s:belongs_to to it)", f.Association(), f.Model, foreignKey, strings.TrimSuffix(foreignKey, "_id")))
+		}
+	}
+	return problems
+}
+
+// childForeignKey returns the column of the child model that holds the ID of
+// moduleName for the has_many field f: <module>_id unless the association
+// names another key.
+func (f Field) childForeignKey(moduleName string) string {
+	if f.ForeignKey != "" {
+		return toSnakeCase(f.ForeignKey)
+	}
+	return moduleName + "_id"
+}
+
+// parentKey returns the belongs_to field of the child model that the has_many
+// field f of moduleName, whose ID is of type id, needs.
+func (f Field) parentKey(moduleName, id string) (Field, error) {
+	key, err := newRelationField(strings.TrimSuffix(f.childForeignKey(moduleName), "_id"), "belongs_to", moduleName)
+	if err != nil {
+		return Field{}, err
+	}
+	return key.withModelID(id), nil
+}
+
+// parentKeys returns the belongs_to fields moduleName lacks for the has_many
+// fields of existing models that name it, e.g. order:belongs_to for the
+// items:has_many:order_item of order when generating order_item.
+func (g *Generator) parentKeys(moduleName string, fields []Field) []Field {
+	entries, err := g.fsys().ReadDir(fsName(WORKDIR + "models"))
+	if err != nil {
+		return nil
+	}
+	var keys []Field
+	for _, entry := range entries {
+		parent, ok := strings.CutSuffix(entry.Name(), ".go")
+		if !ok || parent == moduleName {
+			continue
+		}
+		parentFields, err := g.detectModelFields(parent)
+		if err != nil {
+			continue
+		}
+		id, _ := g.detectModelID(parent)
+		for _, f := range parentFields {
+			if f.Relation != "has_many" || f.Model != moduleName {
+				continue
+			}
+			key, err := f.parentKey(parent, id)
+			if err != nil || slices.ContainsFunc(slices.Concat(fields, keys), func(c Field) bool { return c.Name == key.Name }) {
+				continue
+			}
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// addParentKey regenerates the existing module childName with the belongs_to
+// field key added, merging the output into edited files as Apply does. The
+// key is optional, so rows the table already holds keep a NULL parent.
+func (g *Generator) addParentKey(childName, projectName string, key Field) error {
+	m, err := g.Manifest()
+	if err != nil {
+		return err
+	}
+	var fields []Field
+	var opts moduleOptions
+	if entry, ok := m.Files[fsName(WORKDIR+"models/"+childName+".go")]; ok {
+		var data templateData
+		if err := json.Unmarshal(entry.Data, &data); err != nil {
+			return fmt.Errorf("invalid template data for the %s model in %s: %w", childName, ManifestFile, err)
+		}
+		if data, err = data.rederived(); err != nil {
+			return fmt.Errorf("invalid template data for the %s model in %s: %w", childName, ManifestFile, err)
+		}
+		fields = append(data.Fields, data.Associations...)
+		opts = moduleOptions{Endpoints: data.Endpoints, Table: data.Table, Filters: data.Filters, Status: data.Status, ID: data.ID, Pagination: data.Pagination}
+	} else {
+		fields = g.resolveModuleFields(childName)
+		opts.ID, _ = g.detectModelID(childName)
+	}
+	fields = append(fields, key.withOptions(fieldOptions{Optional: true}))
+
+	g.regenerate = true
+	defer func() { g.regenerate = false }()
+	return g.createModule(childName, projectName, fields, opts)
+}
+
+// hasManyChildren returns the has_many fields of moduleName whose child
+// module exists without the foreign key they need, with that key, and the
+// fields declared by the children, existing or not, once they have it; see
+// relationProblems. Children that do not exist yet get their key from
+// parentKeys when they are generated.
+func (g *Generator) hasManyChildren(moduleName, id string, fields []Field) (map[string]Field, map[string][]Field) {
+	missing := map[string]Field{}
+	declared := map[string][]Field{}
+	for _, f := range fields {
+		if f.Relation != "has_many" || f.Model == moduleName {
+			continue
+		}
+		key, err := f.parentKey(moduleName, id)
+		if err != nil {
+			continue
+		}
+		childFields, err := g.detectModelFields(f.Model)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			declared[f.Model] = []Field{key}
+		case err != nil:
+		case !slices.ContainsFunc(childFields, func(c Field) bool { return c.Name == key.Name }):
+			missing[f.Model] = key
+			declared[f.Model] = append(childFields, key)
+		}
+	}
+	return missing, declared
+}
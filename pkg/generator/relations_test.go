@@ -0,0 +1,258 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testRelationsSchema = `modules:
+  - name: order
+    fields:
+      - {name: note, type: string}
+      - {name: customer, type: belongs_to}
+      - {name: tags, type: many2many}
+      - {name: items, type: has_many, model: order_item}
+  - name: order_item
+    fields:
+      - {name: order, type: belongs_to}
+      - {name: qty, type: int}
+  - name: customer
+  - name: tag
+    fields:
+      - {name: label, type: string}
+`
+
+func TestApply_Relations(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := ParseSchema([]byte(testRelationsSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Apply(schema); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]string{
+		"internal/models/order.go": {
+			"CustomerID uint           `json:\"customer_id\" gorm:\"not null;index\"`",
+			"Customer   *Customer      `json:\"customer,omitempty\" gorm:\"foreignKey:CustomerID\"`",
+			"Tags       []Tag          `json:\"tags,omitempty\" gorm:\"many2many:order_tags\"`",
+			"Items      []OrderItem    `json:\"items,omitempty\" gorm:\"foreignKey:OrderID\"`",
+		},
+		"internal/requests/order_request.go": {
+			"CustomerID uint   `json:\"customer_id\" validate:\"required\"`",
+			"TagIDs     []uint `json:\"tag_ids\"`",
+		},
+		"internal/services/order_service.go": {
+			"entity.Tags = append(entity.Tags, models.Tag{ID: id})",
+			"entity.Tags = make([]models.Tag, 0, len(req.TagIDs))",
+		},
+		"internal/repositories/order_repository.go": {
+			"r.db.Preload(clause.Associations).First(&entity, id)",
+			`tx.Omit("Tags.*").Create(entity)`,
+			"tx.Omit(clause.Associations).Save(entity)",
+			`tx.Model(entity).Omit("Tags.*").Association("Tags").Replace(entity.Tags)`,
+			// Foreign keys sort the list; associations do not.
+			`Sort:   []string{"id", "note", "customer_id", "created_at", "updated_at"},`,
+		},
+		"internal/repositories/order_item_repository.go": {
+			"tx.Create(entity)",
+		},
+		// Referenced tables migrate first.
+		"migrations/migrations.go": {
+			"&models.Customer{},\n\t\t&models.Example{},\n\t\t&models.Tag{},\n\t\t&models.Order{},\n\t\t&models.OrderItem{},",
+		},
+	}
+	for name, wants := range files {
+		content := mustRead(t, mem, name)
+		for _, want := range wants {
+			if !strings.Contains(content, want) {
+				t.Errorf("%s does not contain %s:\n%s", name, want, content)
+			}
+		}
+	}
+	if response := mustRead(t, mem, "internal/responses/order_response.go"); strings.Contains(response, "Tags") {
+		t.Errorf("response has the association:\n%s", response)
+	}
+
+	// The relations are read back from the model.
+	fields, err := g.detectModelFields("order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var specs []string
+	for _, f := range fields {
+		specs = append(specs, f.spec())
+	}
+	if want := []string{"note:string", "customer:belongs_to:customer", "tags:many2many:tag", "items:has_many:order_item"}; !reflect.DeepEqual(specs, want) {
+		t.Errorf("detectModelFields() = %v, want %v", specs, want)
+	}
+	if err := g.GenerateAutoServiceTests("order", "example.com/app", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// The recorded template data renders the same files again.
+	result, err := g.Upgrade()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Upgraded) > 0 || len(result.Conflicts) > 0 {
+		t.Errorf("Upgrade() changed files: %+v", result)
+	}
+}
+
+func TestApply_RelationProblems(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := ParseSchema([]byte(`modules:
+  - name: order
+    fields:
+      - {name: buyer, type: belongs_to, model: customer}
+      - {name: items, type: has_many, model: order_item}
+      - {name: notes, type: has_many, model: example}
+  - name: order_item
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = g.Apply(schema)
+	want := "module order: field buyer: module customer does not exist\n" +
+		"module order: field items: order_item has no order_id foreign key (add order:belongs_to to it)\n" +
+		"module order: field notes: example has no order_id foreign key (add order:belongs_to to it)"
+	if err == nil || err.Error() != want {
+		t.Fatalf("Apply() error = %v, want %q", err, want)
+	}
+	if g.fileExists("internal/models/order.go") {
+		t.Error("Apply() generated modules despite the errors")
+	}
+}
+
+func TestGenerateModule_HasManyForeignKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules [][]string // GenerateModule arguments, in order
+		want    string
+	}{
+		{
+			name: "child generated after the parent",
+			modules: [][]string{
+				{"order", "customer:string", "items:has_many:order_item"},
+				{"order_item", "qty:int"},
+			},
+			want: "OrderID   uint           `json:\"order_id\" gorm:\"not null;index\"`",
+		},
+		{
+			// The rows of the existing table have no parent.
+			name: "child generated before the parent",
+			modules: [][]string{
+				{"order_item", "qty:int"},
+				{"order", "customer:string", "items:has_many:order_item"},
+			},
+			want: "OrderID   *uint          `json:\"order_id\" gorm:\"index\"`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := NewMemFS()
+			g := New("", Options{})
+			g.FS = mem
+			if err := g.Init("example.com/app"); err != nil {
+				t.Fatal(err)
+			}
+			for _, args := range tt.modules {
+				if err := g.GenerateModule(args[0], args[1:]...); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if model := mustRead(t, mem, "internal/models/order_item.go"); !strings.Contains(model, tt.want) {
+				t.Errorf("order_item model does not contain %s:\n%s", tt.want, model)
+			}
+			if problems := g.relationProblems("order", mustDetectFields(t, g, "order"), nil); len(problems) > 0 {
+				t.Errorf("relationProblems() = %q", problems)
+			}
+			migrateModels(t, mem)
+		})
+	}
+}
+
+func mustDetectFields(t *testing.T, g *Generator, moduleName string) []Field {
+	t.Helper()
+	fields, err := g.detectModelFields(moduleName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fields
+}
+
+// migrateModels runs MigrateAll of the project in mem, with its models, on an
+// SQLite database. It is skipped in short mode and when GORM cannot be
+// downloaded.
+func migrateModels(t *testing.T, mem *MemFS) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("migrating needs the go command and GORM")
+	}
+	dir := t.TempDir()
+	for _, name := range mem.Files() {
+		if strings.HasPrefix(name, "internal/models/") || name == "migrations/migrations.go" {
+			writeTestFile(t, filepath.Join(dir, name), mustRead(t, mem, name))
+		}
+	}
+	writeTestFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\ngo 1.23\n\n"+
+		"require (\n\tgorm.io/driver/sqlite v1.6.0\n\tgorm.io/gorm v1.31.2\n)\n")
+	writeTestFile(t, filepath.Join(dir, "migrate", "main.go"), `package main
+
+import (
+	"log"
+
+	"example.com/app/migrations"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func main() {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := migrations.MigrateAll(db); err != nil {
+		log.Fatal(err)
+	}
+}
+`)
+	goCmd := func(args ...string) *exec.Cmd {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+		return cmd
+	}
+	if out, err := goCmd("mod", "download", "gorm.io/gorm", "gorm.io/driver/sqlite").CombinedOutput(); err != nil {
+		t.Skipf("cannot download GORM: %v\n%s", err, out)
+	}
+	if out, err := goCmd("run", "./migrate").CombinedOutput(); err != nil {
+		t.Fatalf("MigrateAll failed: %v\n%s", err, out)
+	}
+}
+
+func writeTestFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,582 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ModuleRemoval describes what RemoveModule deletes and what deserves a
+// warning first.
+type ModuleRemoval struct {
+	Files []string // module files that will be deleted
+	// Edited lists files changed since they were generated. Files missing from
+	// the manifest are included, since changes cannot be ruled out.
+	Edited []string
+	// References lists uses of the module's identifiers outside its files,
+	// e.g. "internal/services/order_service.go:14: models.Product".
+	References []string
+}
+
+// moduleFiles returns the files GenerateModule creates for a module.
+func moduleFiles(moduleName string) []string {
+	return []string{
+		WORKDIR + "requests/" + moduleName + "_request.go",
+		WORKDIR + "responses/" + moduleName + "_response.go",
+		WORKDIR + "models/" + moduleName + ".go",
+		WORKDIR + "repositories/" + moduleName + "_repository.go",
+		WORKDIR + "services/" + moduleName + "_service.go",
+		WORKDIR + "controllers/" + moduleName + "_controller.go",
+		"tests/services/" + moduleName + "_service_test.go",
+		"tests/mocks/" + moduleName + "_repository_mock.go",
+		"tests/fixtures/" + moduleName + "_fixture.go",
+	}
+}
+
+// existingModuleFiles returns the files of moduleName that exist: those
+// GenerateModule creates and those the manifest records for the module.
+func (g *Generator) existingModuleFiles(moduleName string) ([]string, error) {
+	m, err := g.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	candidates := moduleFiles(moduleName)
+	for name, entry := range m.Files {
+		if entry.Module == moduleName {
+			candidates = append(candidates, name)
+		}
+	}
+	var files []string
+	seen := map[string]bool{}
+	for _, name := range candidates {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if _, err := g.readFile(name); errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// PlanRemoval returns the files RemoveModule would delete for moduleName,
+// the ones among them that were edited, and references to the module from
+// the rest of the project.
+func (g *Generator) PlanRemoval(moduleName string) (ModuleRemoval, error) {
+	moduleName = strings.ToLower(strings.TrimSpace(moduleName))
+	if moduleName == "" {
+		return ModuleRemoval{}, errors.New("module name must not be empty")
+	}
+	m, err := g.Manifest()
+	if err != nil {
+		return ModuleRemoval{}, err
+	}
+	projectName, err := g.getProjectName()
+	if err != nil {
+		return ModuleRemoval{}, fmt.Errorf("could not determine project name: %w", err)
+	}
+
+	var plan ModuleRemoval
+	plan.Files, err = g.existingModuleFiles(moduleName)
+	if err != nil {
+		return ModuleRemoval{}, err
+	}
+	if len(plan.Files) == 0 {
+		return ModuleRemoval{}, fmt.Errorf("module %s not found", moduleName)
+	}
+	for _, name := range plan.Files {
+		content, err := g.readFile(name)
+		if err != nil {
+			return ModuleRemoval{}, err
+		}
+		if !m.Pristine(name, content) {
+			plan.Edited = append(plan.Edited, name)
+		}
+	}
+
+	plan.References, err = g.findReferences(projectName, plan.Files)
+	if err != nil {
+		return ModuleRemoval{}, err
+	}
+	return plan, nil
+}
+
+// RemoveModule is the inverse of GenerateModule: it deletes the module's
+// files, removes its model from migrations/migrations.go and unwires it from
+// main.go and routes/fiber_routes.go, all or nothing. It does not ask; check
+// PlanRemoval for edited files and references first.
+func (g *Generator) RemoveModule(moduleName string) error {
+	moduleName = strings.ToLower(strings.TrimSpace(moduleName))
+	plan, err := g.PlanRemoval(moduleName)
+	if err != nil {
+		return err
+	}
+	projectName, err := g.getProjectName()
+	if err != nil {
+		return fmt.Errorf("could not determine project name: %w", err)
+	}
+
+	return g.transaction(func() error {
+		for _, name := range plan.Files {
+			if err := g.removeFile(name); err != nil {
+				return err
+			}
+			if err := g.recordDeletion(name); err != nil {
+				return err
+			}
+			if !g.DryRun {
+				fmt.Println("Deleted file:", name)
+			}
+		}
+		if err := g.removeMigration(moduleName, projectName); err != nil {
+			return err
+		}
+		return g.unregisterModule(moduleName, projectName)
+	})
+}
+
+// removeMigration removes the module's model from migrations.go.
+func (g *Generator) removeMigration(moduleName, projectName string) error {
+	migrations, exists := g.readMigrations()
+	modelLine := fmt.Sprintf("&models.%s{}", toPascal(moduleName))
+	if _, ok := migrations.models[modelLine]; !exists || !ok {
+		return nil
+	}
+	delete(migrations.models, modelLine)
+	if len(migrations.models) == 0 {
+		delete(migrations.imports, projectName+"/internal/models")
+	}
+	if err := g.writeMigrations(projectName, migrations, true); err != nil {
+		return err
+	}
+	if !g.DryRun {
+		fmt.Println("migrations/migrations.go updated, removed model:", modelLine)
+	}
+	return nil
+}
+
+// unregisterModule unwires the module from main.go and routes/fiber_routes.go.
+func (g *Generator) unregisterModule(moduleName, projectName string) error {
+	wiring := newModuleWiring(moduleName)
+	for _, target := range registrationTargets {
+		src, err := g.readFile(target.path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		out, err := target.unregister(src, wiring, projectName)
+		if err != nil {
+			return fmt.Errorf("could not unregister module %s from %s: %w; remove it manually", moduleName, target.path, err)
+		}
+		if out == nil {
+			continue
+		}
+		if err := g.writeFile(target.path, out, ActionPatch); err != nil {
+			return err
+		}
+		if err := g.recordUnregistration(target.path, moduleName, out); err != nil {
+			return err
+		}
+		if !g.DryRun {
+			fmt.Printf("%s updated, removed module: %s\n", target.path, moduleName)
+		}
+	}
+	return nil
+}
+
+// unregisterInMain returns main.go without the module's repository, service
+// and controller construction, or nil when the module is not wired.
+func unregisterInMain(src []byte, w moduleWiring, projectName string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, mainFile, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+	mainFunc := findFunc(file, "", "main")
+	if mainFunc == nil || mainFunc.Body == nil {
+		return nil, nil
+	}
+
+	vars := map[string]bool{w.RepoVar: true, w.ServiceVar: true, w.ControllerVar: true}
+	var edits []edit
+	first := true
+	for _, stmt := range mainFunc.Body.List {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 1 {
+			continue
+		}
+		if ident, ok := assign.Lhs[0].(*ast.Ident); !ok || !vars[ident.Name] {
+			continue
+		}
+		start := stmt.Pos()
+		if first {
+			start = commentAbove(fset, file, stmt, "Initialize "+w.ModuleName+" module")
+			first = false
+		}
+		edits = append(edits, removeLines(src, offset(start), offset(stmt.End())))
+	}
+
+	ast.Inspect(mainFunc.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || !isSelectorCall(call, "routes", "NewFiberRoutes") {
+			return true
+		}
+		for i, arg := range call.Args {
+			if ident, ok := arg.(*ast.Ident); ok && ident.Name == w.ControllerVar {
+				edits = append(edits, removeListElem(offset, exprNodes(call.Args), i))
+			}
+		}
+		return false
+	})
+
+	if len(edits) == 0 {
+		return nil, nil
+	}
+	out, err := applyEdits(src, edits, projectName)
+	if err != nil {
+		return nil, err
+	}
+	return dropUnusedImports(out, projectName, "internal/repositories", "internal/services", "internal/controllers")
+}
+
+// unregisterInRoutes returns routes/fiber_routes.go without the module's
+// controller field, constructor parameter and RegisterRoutes call, or nil
+// when the module is not wired.
+func unregisterInRoutes(src []byte, w moduleWiring, projectName string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fiberRoutesFile, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+	line := func(p token.Pos) int { return fset.Position(p).Line }
+
+	var edits []edit
+	if structType := findStruct(file, "FiberRoutes"); structType != nil {
+		for _, field := range structType.Fields.List {
+			if len(field.Names) == 1 && field.Names[0].Name == w.ControllerVar {
+				edits = append(edits, removeLines(src, offset(field.Pos()), offset(field.End())))
+			}
+		}
+	}
+
+	if constructor := findFunc(file, "", "NewFiberRoutes"); constructor != nil && constructor.Body != nil {
+		params := constructor.Type.Params.List
+		for i, param := range params {
+			if len(param.Names) == 1 && param.Names[0].Name == w.ControllerVar {
+				nodes := make([]ast.Node, len(params))
+				for j, p := range params {
+					nodes[j] = p
+				}
+				edits = append(edits, removeListElem(offset, nodes, i))
+			}
+		}
+		if literal := findReturnedLiteral(constructor, "FiberRoutes"); literal != nil {
+			for i, elt := range literal.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				if key, ok := kv.Key.(*ast.Ident); !ok || key.Name != w.ControllerVar {
+					continue
+				}
+				if line(literal.Rbrace) > line(literal.Lbrace) {
+					end := offset(elt.End())
+					if end < len(src) && src[end] == ',' {
+						end++
+					}
+					edits = append(edits, removeLines(src, offset(elt.Pos()), end))
+				} else {
+					edits = append(edits, removeListElem(offset, exprNodes(literal.Elts), i))
+				}
+			}
+		}
+	}
+
+	if install := findFunc(file, "FiberRoutes", "Install"); install != nil && install.Body != nil && install.Recv.List[0].Names != nil {
+		receiver := install.Recv.List[0].Names[0].Name
+		remaining := 0
+		var removed ast.Stmt
+		for _, stmt := range install.Body.List {
+			recv, field, _, ok := registerRoutesCall(stmt)
+			switch {
+			case !ok:
+			case recv == receiver && field == w.ControllerVar:
+				removed = stmt
+			default:
+				remaining++
+			}
+		}
+		if removed != nil {
+			start := removed.Pos()
+			if remaining == 0 {
+				start = commentAbove(fset, file, removed, "Register module routes")
+				// Keep the router group used, as in projects created without a module.
+				if group, router := findGroupAssign(install.Body); group != nil && findBlankUse(install.Body, router) == nil {
+					edits = append(edits, edit{offset: offset(group.End()), text: "\n\t_ = " + router + " // used once a module registers its routes"})
+				}
+			}
+			edits = append(edits, removeLines(src, offset(start), offset(removed.End())))
+		}
+	}
+
+	if len(edits) == 0 {
+		return nil, nil
+	}
+	out, err := applyEdits(src, edits, projectName)
+	if err != nil {
+		return nil, err
+	}
+	return dropUnusedImports(out, projectName, "internal/controllers")
+}
+
+// commentAbove returns the start of the comment directly above stmt when its
+// text is text, or the start of stmt otherwise.
+func commentAbove(fset *token.FileSet, file *ast.File, stmt ast.Stmt, text string) token.Pos {
+	stmtLine := fset.Position(stmt.Pos()).Line
+	for _, group := range file.Comments {
+		if fset.Position(group.End()).Line == stmtLine-1 && strings.TrimSpace(group.Text()) == text {
+			return group.Pos()
+		}
+	}
+	return stmt.Pos()
+}
+
+// removeLines returns an edit removing src[start:end]. When nothing but
+// blanks and a trailing comment share its lines, the whole lines go.
+func removeLines(src []byte, start, end int) edit {
+	lineStart := start
+	for lineStart > 0 && (src[lineStart-1] == ' ' || src[lineStart-1] == '\t') {
+		lineStart--
+	}
+	if lineStart > 0 && src[lineStart-1] != '\n' {
+		return edit{offset: start, remove: end - start}
+	}
+	lineEnd := end
+	for lineEnd < len(src) && (src[lineEnd] == ' ' || src[lineEnd] == '\t') {
+		lineEnd++
+	}
+	if strings.HasPrefix(string(src[lineEnd:]), "//") {
+		for lineEnd < len(src) && src[lineEnd] != '\n' {
+			lineEnd++
+		}
+	}
+	if lineEnd < len(src) && src[lineEnd] == '\n' {
+		return edit{offset: lineStart, remove: lineEnd + 1 - lineStart}
+	}
+	return edit{offset: start, remove: end - start}
+}
+
+// removeListElem returns an edit removing elems[i] of a comma-separated list
+// together with one adjacent comma.
+func removeListElem(offset func(token.Pos) int, elems []ast.Node, i int) edit {
+	start, end := offset(elems[i].Pos()), offset(elems[i].End())
+	switch {
+	case i > 0:
+		start = offset(elems[i-1].End())
+	case len(elems) > 1:
+		end = offset(elems[i+1].Pos())
+	}
+	return edit{offset: start, remove: end - start}
+}
+
+func exprNodes(exprs []ast.Expr) []ast.Node {
+	nodes := make([]ast.Node, len(exprs))
+	for i, expr := range exprs {
+		nodes[i] = expr
+	}
+	return nodes
+}
+
+// dropUnusedImports removes the given project packages from the imports of
+// src when nothing in src refers to them any more.
+func dropUnusedImports(src []byte, projectName string, packages ...string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok {
+				used[x.Name] = true
+			}
+		}
+		return true
+	})
+
+	var edits []edit
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		for _, pkg := range packages {
+			if importPath != projectName+"/"+pkg || used[importName(spec)] {
+				continue
+			}
+			edits = append(edits, removeLines(src, fset.Position(spec.Pos()).Offset, fset.Position(spec.End()).Offset))
+		}
+	}
+	if len(edits) == 0 {
+		return src, nil
+	}
+	return applyEdits(src, edits, projectName)
+}
+
+// importName returns the name an import is referred to by.
+func importName(spec *ast.ImportSpec) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	importPath, _ := strconv.Unquote(spec.Path.Value)
+	return path.Base(importPath)
+}
+
+// findReferences returns uses of the top-level identifiers declared in files
+// by the other Go files of the project. main.go, routes/fiber_routes.go and
+// migrations/migrations.go are skipped, as RemoveModule rewrites them.
+func (g *Generator) findReferences(projectName string, files []string) ([]string, error) {
+	skip := map[string]bool{mainFile: true, fiberRoutesFile: true, migrationsFile: true}
+	declared := map[string]map[string]bool{} // package directory -> names
+	for _, name := range files {
+		skip[name] = true
+		src, err := g.readFile(name)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), name, src, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		dir := path.Dir(name)
+		if declared[dir] == nil {
+			declared[dir] = map[string]bool{}
+		}
+		for _, decl := range file.Decls {
+			for _, ident := range declaredNames(decl) {
+				declared[dir][ident] = true
+			}
+		}
+	}
+
+	var refs []string
+	err := g.walkGoFiles(".", func(name string) error {
+		if skip[name] {
+			return nil
+		}
+		src, err := g.readFile(name)
+		if err != nil {
+			return err
+		}
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, name, src, parser.SkipObjectResolution)
+		if err != nil {
+			return nil
+		}
+		imported := map[string]string{} // import name -> package directory
+		for _, spec := range file.Imports {
+			importPath, _ := strconv.Unquote(spec.Path.Value)
+			if dir, ok := strings.CutPrefix(importPath, projectName+"/"); ok {
+				imported[importName(spec)] = dir
+			}
+		}
+		samePackage := declared[path.Dir(name)]
+
+		found := map[string]bool{}
+		report := func(pos token.Pos, ref string) {
+			if !found[ref] {
+				found[ref] = true
+				refs = append(refs, fmt.Sprintf("%s:%d: %s", name, fset.Position(pos).Line, ref))
+			}
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.SelectorExpr:
+				if x, ok := n.X.(*ast.Ident); ok {
+					if dir, ok := imported[x.Name]; ok && declared[dir][n.Sel.Name] {
+						report(n.Pos(), x.Name+"."+n.Sel.Name)
+					}
+				}
+				// The selected name is a field or method, not a package-level identifier.
+				ast.Inspect(n.X, func(inner ast.Node) bool {
+					if ident, ok := inner.(*ast.Ident); ok && samePackage[ident.Name] {
+						report(ident.Pos(), ident.Name)
+					}
+					return true
+				})
+				return false
+			case *ast.Ident:
+				if samePackage[n.Name] {
+					report(n.Pos(), n.Name)
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	return refs, err
+}
+
+// declaredNames returns the package-level names declared by decl; methods
+// are not package-level.
+func declaredNames(decl ast.Decl) []string {
+	var names []string
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		if decl.Recv == nil {
+			names = append(names, decl.Name.Name)
+		}
+	case *ast.GenDecl:
+		for _, spec := range decl.Specs {
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, spec.Name.Name)
+			case *ast.ValueSpec:
+				for _, ident := range spec.Names {
+					names = append(names, ident.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// walkGoFiles calls fn with the name of every .go file below dir, skipping
+// hidden directories and vendor.
+func (g *Generator) walkGoFiles(dir string, fn func(name string) error) error {
+	entries, err := g.fsys().ReadDir(fsName(dir))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := path.Join(dir, entry.Name())
+		switch {
+		case entry.IsDir():
+			if strings.HasPrefix(entry.Name(), ".") || entry.Name() == "vendor" {
+				continue
+			}
+			if err := g.walkGoFiles(name, fn); err != nil {
+				return err
+			}
+		case strings.HasSuffix(name, ".go"):
+			if err := fn(name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRemoveModule(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	for _, module := range []string{"product", "order"} {
+		if err := g.GenerateModule(module, "name:string"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := map[string]string{}
+	for _, name := range []string{mainFile, fiberRoutesFile, migrationsFile} {
+		before[name] = mustRead(t, mem, name)
+	}
+
+	files := moduleFiles("order")
+	sort.Strings(files)
+	plan, err := g.PlanRemoval("order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(plan.Files, files) || plan.Edited != nil || plan.References != nil {
+		t.Errorf("PlanRemoval(order) = %+v, want the module files without warnings", plan)
+	}
+	if err := g.RemoveModule("order"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range moduleFiles("order") {
+		if _, err := mem.ReadFile(name); err == nil {
+			t.Errorf("%s still exists", name)
+		}
+	}
+	for _, name := range []string{mainFile, fiberRoutesFile, migrationsFile} {
+		content := mustRead(t, mem, name)
+		if strings.Contains(strings.ToLower(content), "order") {
+			t.Errorf("%s still mentions the order module:\n%s", name, content)
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), name, content, 0); err != nil {
+			t.Errorf("%s does not parse: %v", name, err)
+		}
+	}
+	m, err := g.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modules := m.Modules(); !reflect.DeepEqual(modules, []string{"example", "product"}) {
+		t.Errorf("manifest modules = %v, want [example product]", modules)
+	}
+	for _, name := range []string{mainFile, fiberRoutesFile} {
+		if !m.Pristine(name, []byte(mustRead(t, mem, name))) {
+			t.Errorf("%s is not pristine after the removal", name)
+		}
+	}
+
+	// Generating the module again restores the files it wired into.
+	if err := g.GenerateModule("order", "name:string"); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range before {
+		if got := mustRead(t, mem, name); got != want {
+			t.Errorf("%s after remove and regenerate:\n%s\nwant:\n%s", name, got, want)
+		}
+	}
+}
+
+func TestRemoveModule_LastModule(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.InitWithOptions(InitOptions{ProjectName: "example.com/app", SkipExample: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{}
+	for _, name := range []string{mainFile, fiberRoutesFile, migrationsFile} {
+		want[name] = mustRead(t, mem, name)
+	}
+	if err := g.GenerateModule("product", "name:string"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.RemoveModule("product"); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range want {
+		if got := mustRead(t, mem, name); got != want {
+			t.Errorf("%s after removing the only module:\n%s\nwant:\n%s", name, got, want)
+		}
+	}
+}
+
+func TestPlanRemoval_Warnings(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	for _, module := range []string{"product", "order"} {
+		if err := g.GenerateModule(module, "name:string"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	edited := mustRead(t, mem, "internal/models/product.go") + "\n// Sku is unique.\n"
+	_ = mem.WriteFile("internal/models/product.go", []byte(edited))
+	_ = mem.WriteFile("internal/services/order_total.go", []byte(`package services
+
+import "example.com/app/internal/models"
+
+func orderTotal(items []models.Product) int {
+	_ = NewProductService
+	return len(items)
+}
+`))
+
+	plan, err := g.PlanRemoval("product")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"internal/models/product.go"}; !reflect.DeepEqual(plan.Edited, want) {
+		t.Errorf("Edited = %v, want %v", plan.Edited, want)
+	}
+	want := []string{
+		"internal/services/order_total.go:5: models.Product",
+		"internal/services/order_total.go:6: NewProductService",
+	}
+	if !reflect.DeepEqual(plan.References, want) {
+		t.Errorf("References = %v, want %v", plan.References, want)
+	}
+
+	if _, err := g.PlanRemoval("customer"); err == nil {
+		t.Error("PlanRemoval(customer) succeeded for a module that does not exist")
+	}
+}
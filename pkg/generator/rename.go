@@ -0,0 +1,593 @@
+package generator
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// moduleNames holds the forms of a module name used by generated code.
+type moduleNames struct {
+	snake  string // "user_account": files, test cases and sample values
+	pascal string // "UserAccount": exported identifiers
+	camel  string // "userAccount": unexported identifiers
+	plural string // "user_accounts": route group and swagger tag
+	table  string // "user_accounts": TableName
+}
+
+func newModuleNames(moduleName string) moduleNames {
+	d := newModuleData("", moduleName, nil)
+	return moduleNames{
+		snake:  moduleName,
+		pascal: d.ModelName,
+		camel:  d.VarName,
+		plural: d.PluralName,
+		table:  moduleName + "s",
+	}
+}
+
+// moduleRename rewrites the name forms of one module to those of another.
+type moduleRename struct {
+	from, to moduleNames
+	idents   map[string]string // renamed declarations of the module's files
+}
+
+// namePair replaces from with to where the bytes around it pass before and
+// after.
+type namePair struct {
+	from, to      string
+	before, after func(byte) bool
+}
+
+// ident renames an identifier declared by the module's files, e.g.
+// "CreateUserAccountRequest" or "userAccountColumns".
+func (r moduleRename) ident(name string) string {
+	return replaceNames(name, []namePair{
+		{r.from.pascal, r.to.pascal, anyByte, notLowerOrDigit},
+		{r.from.camel, r.to.camel, notLetterOrDigit, notLowerOrDigit},
+	})
+}
+
+// testCase matches the names of generated test cases; the second group is
+// the module name.
+var testCase = regexp.MustCompile(`^((?:success|error)_(?:list|get|create|update|delete)_)(\w+?)(_not_found|_from_repository)?$`)
+
+// text renames the module in comments and string literals word by word, e.g.
+// "GET /user_accounts/:id" or "success_create_user_account". Words are the
+// name forms, test case names and renamed identifiers; others that contain
+// the name, such as the column user_account_id, are kept.
+func (r moduleRename) text(s string) string {
+	var b strings.Builder
+	for s != "" {
+		i := strings.IndexFunc(s, isWordRune)
+		if i < 0 {
+			i = len(s)
+		}
+		b.WriteString(s[:i])
+		s = s[i:]
+		j := strings.IndexFunc(s, func(c rune) bool { return !isWordRune(c) })
+		if j < 0 {
+			j = len(s)
+		}
+		b.WriteString(r.word(s[:j]))
+		s = s[j:]
+	}
+	return b.String()
+}
+
+func (r moduleRename) word(w string) string {
+	if renamed, ok := r.idents[w]; ok {
+		return renamed
+	}
+	switch w {
+	case r.from.snake:
+		return r.to.snake
+	case r.from.pascal:
+		return r.to.pascal
+	case r.from.camel:
+		return r.to.camel
+	case r.from.plural:
+		return r.to.plural
+	}
+	if m := testCase.FindStringSubmatch(w); m != nil && m[2] == r.from.snake {
+		return m[1] + r.to.snake + m[3]
+	}
+	return w
+}
+
+func isWordRune(c rune) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// literal renames the module in a quoted string literal. The table name is
+// matched as a whole since it is not always the plural.
+func (r moduleRename) literal(lit string) string {
+	if lit == strconv.Quote(r.from.table) {
+		return strconv.Quote(r.to.table)
+	}
+	return r.text(lit)
+}
+
+// fileName renames a file of the module, e.g. "user_account_service.go".
+func (r moduleRename) fileName(name string) string {
+	if rest, ok := strings.CutPrefix(name, r.from.snake); ok && (rest == ".go" || strings.HasPrefix(rest, "_")) {
+		return r.to.snake + rest
+	}
+	return name
+}
+
+// replaceNames replaces every match of the pairs in s, trying them in order
+// at each position.
+func replaceNames(s string, pairs []namePair) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		matched := false
+		for _, p := range pairs {
+			end := i + len(p.from)
+			if !strings.HasPrefix(s[i:], p.from) ||
+				(i > 0 && !p.before(s[i-1])) ||
+				(end < len(s) && !p.after(s[end])) {
+				continue
+			}
+			b.WriteString(p.to)
+			i = end
+			matched = true
+			break
+		}
+		if !matched {
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+func anyByte(byte) bool { return true }
+
+func isUpper(b byte) bool { return 'A' <= b && b <= 'Z' }
+
+func notLowerOrDigit(b byte) bool {
+	return !('a' <= b && b <= 'z') && !('0' <= b && b <= '9')
+}
+
+func notLetterOrDigit(b byte) bool { return notLowerOrDigit(b) && !isUpper(b) }
+
+// projectFile is a parsed Go file of the project.
+type projectFile struct {
+	name string
+	src  []byte
+	fset *token.FileSet
+	file *ast.File
+}
+
+// RenameResult describes the table of a module RenameModule renamed.
+type RenameResult struct {
+	OldTable string // table of the model before the rename
+	NewTable string // table of the renamed model
+}
+
+// RenameModule renames module oldName to newName across all layers: it moves
+// the module's files, renames the identifiers declared in them together with
+// every reference in the project, and updates the wiring in main.go and
+// routes/fiber_routes.go, the route group, TableName and the migration entry.
+// Fields keep their names, and the keys and join tables of associations keep
+// the columns they use. With renameTable, migrations.go also renames the
+// module's table so existing rows are kept.
+func (g *Generator) RenameModule(oldName, newName string, renameTable bool) (RenameResult, error) {
+	oldName = strings.ToLower(strings.TrimSpace(oldName))
+	newName = strings.ToLower(strings.TrimSpace(newName))
+	if oldName == "" || newName == "" {
+		return RenameResult{}, errors.New("module names must not be empty")
+	}
+	if oldName == newName {
+		return RenameResult{}, fmt.Errorf("module %s already has that name", oldName)
+	}
+	files, err := g.existingModuleFiles(oldName)
+	if err != nil {
+		return RenameResult{}, err
+	}
+	if len(files) == 0 {
+		return RenameResult{}, fmt.Errorf("module %s not found", oldName)
+	}
+	if existing, err := g.existingModuleFiles(newName); err != nil {
+		return RenameResult{}, err
+	} else if len(existing) > 0 {
+		return RenameResult{}, fmt.Errorf("module %s already exists", newName)
+	}
+	projectName, err := g.getProjectName()
+	if err != nil {
+		return RenameResult{}, fmt.Errorf("could not determine project name: %w", err)
+	}
+	r := moduleRename{from: newModuleNames(oldName), to: newModuleNames(newName)}
+	if r.from.table, err = g.moduleTable(oldName); err != nil {
+		return RenameResult{}, err
+	}
+	result := RenameResult{OldTable: r.from.table, NewTable: r.to.table}
+
+	return result, g.transaction(func() error {
+		m, err := g.Manifest()
+		if err != nil {
+			return err
+		}
+		parsed, err := g.parseProject()
+		if err != nil {
+			return err
+		}
+		moduleSet := map[string]bool{}
+		for _, name := range files {
+			moduleSet[name] = true
+		}
+		for _, pf := range parsed {
+			delete(moduleSet, pf.name)
+		}
+		for name := range moduleSet {
+			return fmt.Errorf("cannot rename module %s: %s is not valid Go", oldName, name)
+		}
+		for _, name := range files {
+			moduleSet[name] = true
+		}
+		renames, err := r.declaredRenames(parsed, moduleSet)
+		if err != nil {
+			return err
+		}
+		r.idents = map[string]string{}
+		for _, names := range renames {
+			for old, renamed := range names {
+				if old != renamed {
+					r.idents[old] = renamed
+				}
+			}
+		}
+
+		for _, pf := range parsed {
+			isModule := moduleSet[pf.name]
+			edits := r.fileEdits(pf, projectName, renames, isModule)
+			out := pf.src
+			if len(edits) > 0 {
+				if out, err = applyEdits(pf.src, edits, projectName); err != nil {
+					return fmt.Errorf("could not rename module %s in %s: %w", oldName, pf.name, err)
+				}
+			}
+			target := pf.name
+			if isModule {
+				target = path.Join(path.Dir(pf.name), r.fileName(path.Base(pf.name)))
+			}
+			if target == pf.name && len(edits) == 0 {
+				continue
+			}
+			pristine := m.Pristine(pf.name, pf.src)
+
+			if target != pf.name {
+				if err := g.writeFile(target, out, ActionCreate); err != nil {
+					return err
+				}
+				if err := g.removeFile(pf.name); err != nil {
+					return err
+				}
+			} else if err := g.writeFile(target, out, ActionPatch); err != nil {
+				return err
+			}
+			if err := g.recordModuleRename(pf.name, target, oldName, newName, out, pristine); err != nil {
+				return err
+			}
+			if g.DryRun {
+				continue
+			}
+			switch {
+			case target != pf.name:
+				fmt.Printf("Renamed file: %s -> %s\n", pf.name, target)
+			case pf.name == mainFile || pf.name == fiberRoutesFile:
+				fmt.Printf("%s updated, renamed module: %s -> %s\n", pf.name, oldName, newName)
+			default:
+				fmt.Println("Updated references in file:", pf.name)
+			}
+		}
+		if err := g.recordRenamedRelations(oldName, newName); err != nil {
+			return err
+		}
+		return g.renameMigration(r, projectName, renameTable)
+	})
+}
+
+// moduleTable returns the table of a module as recorded in the manifest for
+// its model, which a schema may have set (see SchemaModule.Table).
+func (g *Generator) moduleTable(moduleName string) (string, error) {
+	m, err := g.Manifest()
+	if err != nil {
+		return "", err
+	}
+	data := templateData{ModuleName: moduleName}
+	if entry, ok := m.Files[fsName(WORKDIR+"models/"+moduleName+".go")]; ok {
+		if err := json.Unmarshal(entry.Data, &data); err != nil {
+			return "", fmt.Errorf("invalid template data for the %s model in %s: %w", moduleName, ManifestFile, err)
+		}
+	}
+	return data.TableName(), nil
+}
+
+// parseProject parses the Go files of the project. migrations.go is left out
+// since it is rendered from its entries, as are files that do not parse.
+func (g *Generator) parseProject() ([]*projectFile, error) {
+	var parsed []*projectFile
+	err := g.walkGoFiles(".", func(name string) error {
+		if name == migrationsFile {
+			return nil
+		}
+		src, err := g.readFile(name)
+		if err != nil {
+			return err
+		}
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, name, src, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil
+		}
+		parsed = append(parsed, &projectFile{name: name, src: src, fset: fset, file: file})
+		return nil
+	})
+	return parsed, err
+}
+
+// declaredRenames returns, per package directory, the new names of the
+// package-level identifiers declared in the module's files, and the other
+// package-level identifiers mapped to themselves. It fails when a new name is
+// already declared elsewhere in the package.
+func (r moduleRename) declaredRenames(parsed []*projectFile, moduleSet map[string]bool) (map[string]map[string]string, error) {
+	renames := map[string]map[string]string{}
+	others := map[string]map[string]bool{} // names declared outside the module's files
+	for _, pf := range parsed {
+		dir := path.Dir(pf.name)
+		if renames[dir] == nil {
+			renames[dir] = map[string]string{}
+			others[dir] = map[string]bool{}
+		}
+		for _, decl := range pf.file.Decls {
+			for _, name := range declaredNames(decl) {
+				if !moduleSet[pf.name] {
+					others[dir][name] = true
+				} else if renamed := r.ident(name); renamed != name {
+					renames[dir][name] = renamed
+				}
+			}
+		}
+	}
+	for dir, names := range renames {
+		for old, renamed := range names {
+			if others[dir][renamed] {
+				return nil, fmt.Errorf("cannot rename %s to %s: %s is already declared in %s", old, renamed, renamed, dir)
+			}
+		}
+		// Names declared by other files keep their name even when they
+		// contain the module's, e.g. UserAccountRole of another module.
+		for name := range others[dir] {
+			names[name] = name
+		}
+	}
+	return renames, nil
+}
+
+// fileEdits returns the edits renaming the module in pf. References to the
+// renamed declarations are rewritten everywhere; main.go and
+// routes/fiber_routes.go also get their wiring variables renamed, and the
+// module's own files the comments and string literals naming it. Fields and
+// methods keep their names, e.g. a dependent's Order field or the column
+// OrderNumber of the module.
+func (r moduleRename) fileEdits(pf *projectFile, projectName string, renames map[string]map[string]string, isModule bool) []edit {
+	dir := path.Dir(pf.name)
+	offset := func(p token.Pos) int { return pf.fset.Position(p).Offset }
+
+	imported := map[string]string{} // import name -> project package directory, "" outside the project
+	for _, spec := range pf.file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		pkgDir, _ := strings.CutPrefix(importPath, projectName+"/")
+		if pkgDir == importPath {
+			pkgDir = ""
+		}
+		imported[importName(spec)] = pkgDir
+	}
+	wiring := map[string]string{}
+	if pf.name == mainFile || pf.name == fiberRoutesFile {
+		from, to := newModuleWiring(r.from.snake), newModuleWiring(r.to.snake)
+		wiring[from.RepoVar] = to.RepoVar
+		wiring[from.ServiceVar] = to.ServiceVar
+		wiring[from.ControllerVar] = to.ControllerVar
+	}
+	// member renames the wiring fields and variables, which are not declared
+	// at package level.
+	member := func(name string) string {
+		if renamed, ok := wiring[name]; ok {
+			return renamed
+		}
+		return name
+	}
+
+	var edits []edit
+	replace := func(pos token.Pos, old, renamed string) {
+		if old != renamed {
+			edits = append(edits, edit{offset: offset(pos), text: renamed, remove: len(old)})
+		}
+	}
+	var visit func(n ast.Node) bool
+	inspect := func(n ast.Node) { ast.Inspect(n, visit) }
+	visit = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ImportSpec:
+			return false
+		case *ast.Field:
+			for _, name := range n.Names {
+				replace(name.Pos(), name.Name, member(name.Name))
+			}
+			if n.Tag != nil && dir == WORKDIR+"models" {
+				replace(n.Tag.Pos(), n.Tag.Value, r.joinTag(n, isModule))
+			}
+			inspect(n.Type)
+			return false
+		case *ast.FuncDecl:
+			if n.Recv == nil {
+				return true
+			}
+			inspect(n.Recv)
+			replace(n.Name.Pos(), n.Name.Name, member(n.Name.Name))
+			inspect(n.Type)
+			if n.Body != nil {
+				inspect(n.Body)
+			}
+			return false
+		case *ast.CompositeLit:
+			if _, ok := n.Type.(*ast.MapType); ok {
+				return true
+			}
+			// The keys of struct literals are fields.
+			if n.Type != nil {
+				inspect(n.Type)
+			}
+			for _, elt := range n.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					if key, ok := kv.Key.(*ast.Ident); ok {
+						replace(key.Pos(), key.Name, member(key.Name))
+						inspect(kv.Value)
+						continue
+					}
+				}
+				inspect(elt)
+			}
+			return false
+		case *ast.SelectorExpr:
+			if x, ok := n.X.(*ast.Ident); ok {
+				if pkgDir, ok := imported[x.Name]; ok {
+					if renamed, ok := renames[pkgDir][n.Sel.Name]; ok {
+						replace(n.Sel.Pos(), n.Sel.Name, renamed)
+					}
+					return false
+				}
+			}
+			inspect(n.X)
+			replace(n.Sel.Pos(), n.Sel.Name, member(n.Sel.Name))
+			return false
+		case *ast.Ident:
+			if renamed, ok := renames[dir][n.Name]; ok {
+				replace(n.Pos(), n.Name, renamed)
+			} else {
+				replace(n.Pos(), n.Name, member(n.Name))
+			}
+		case *ast.BasicLit:
+			if isModule && n.Kind == token.STRING {
+				replace(n.Pos(), n.Value, r.literal(n.Value))
+			}
+		}
+		return true
+	}
+	ast.Inspect(pf.file, visit)
+
+	for _, group := range pf.file.Comments {
+		for _, c := range group.List {
+			switch {
+			case isModule:
+				replace(c.Pos(), c.Text, r.text(c.Text))
+			case len(wiring) > 0 && strings.TrimSpace(strings.TrimPrefix(c.Text, "//")) == "Initialize "+r.from.snake+" module":
+				replace(c.Pos(), c.Text, "// Initialize "+r.to.snake+" module")
+			}
+		}
+	}
+	return edits
+}
+
+// joinTag returns the tag of the model field f with the join columns of a
+// many2many association pinned to the old model name, as GORM derives them
+// from the model names: the module's own column in its model, and the
+// renamed model's column in the models of other modules.
+func (r moduleRename) joinTag(f *ast.Field, isModule bool) string {
+	tag, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return f.Tag.Value
+	}
+	gorm, ok := reflect.StructTag(tag).Lookup("gorm")
+	if !ok || !strings.Contains(gorm, "many2many:") {
+		return f.Tag.Value
+	}
+	key := r.from.pascal + "ID"
+	pinned := gorm
+	if isModule && !strings.Contains(gorm, "joinForeignKey:") {
+		pinned += ";joinForeignKey:" + key
+	}
+	if elem, ok := f.Type.(*ast.ArrayType); ok && !isModule && !strings.Contains(gorm, "joinReferences:") {
+		if model, ok := elem.Elt.(*ast.Ident); ok && model.Name == r.from.pascal {
+			pinned += ";joinReferences:" + key
+		}
+	}
+	return strings.Replace(f.Tag.Value, `gorm:"`+gorm+`"`, `gorm:"`+pinned+`"`, 1)
+}
+
+// withModuleRenamed returns the template data d of a file recorded before
+// module oldName was renamed to newName: the module's own data follows the
+// new name, and relations with it point to the new model. The keys and join
+// tables named after the old model are pinned (see relationKeys) as the
+// rename keeps them in the files.
+func (d templateData) withModuleRenamed(oldName, newName string) (templateData, error) {
+	key := toPascal(oldName) + "ID"
+	rename := func(fields []Field) []Field {
+		fields = slices.Clone(fields)
+		for i, f := range fields {
+			if d.ModuleName == oldName {
+				switch f.Relation {
+				case "has_many":
+					f.ForeignKey = cmp.Or(f.ForeignKey, key)
+				case "many2many":
+					f.JoinTable = cmp.Or(f.JoinTable, oldName+"_"+f.Name)
+					f.JoinForeignKey = cmp.Or(f.JoinForeignKey, key)
+				}
+			}
+			if f.Relation != "" && f.Model == oldName {
+				f.Model = newName
+				if f.Relation != "belongs_to" {
+					f.GoType = "[]" + toPascal(newName)
+				}
+				if f.Relation == "many2many" && d.ModuleName != oldName {
+					f.JoinReferences = cmp.Or(f.JoinReferences, key)
+				}
+			}
+			fields[i] = f
+		}
+		return fields
+	}
+	d.Fields, d.Associations = rename(d.Fields), rename(d.Associations)
+	if d.ModuleName != oldName {
+		return d, nil
+	}
+	d.ModuleName = newName
+	d.Table = "" // the table follows the new name
+	return d.rederived()
+}
+
+// renameMigration renames the module's model in migrations.go and, with
+// renameTable, adds a rename of its table.
+func (g *Generator) renameMigration(r moduleRename, projectName string, renameTable bool) error {
+	migrations, exists := g.readMigrations()
+	oldLine := fmt.Sprintf("&models.%s{}", r.from.pascal)
+	newLine := fmt.Sprintf("&models.%s{}", r.to.pascal)
+	if _, ok := migrations.models[oldLine]; !exists || !ok {
+		return nil
+	}
+	delete(migrations.models, oldLine)
+	migrations.models[newLine] = struct{}{}
+	if renameTable {
+		migrations.renames = append(migrations.renames, tableRename{From: r.from.table, To: r.to.table})
+	}
+	if err := g.writeMigrations(projectName, migrations, true); err != nil {
+		return err
+	}
+	if !g.DryRun {
+		fmt.Printf("migrations/migrations.go updated, renamed model: %s -> %s\n", oldLine, newLine)
+	}
+	return nil
+}
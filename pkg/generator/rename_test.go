@@ -0,0 +1,283 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenameModule(t *testing.T) {
+	fields := []string{"display_name:string", "email:email", "active:bool"}
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	for _, module := range []string{"user_account", "order"} {
+		if err := g.GenerateModule(module, fields...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = mem.WriteFile("internal/services/order_owner.go", []byte(`package services
+
+import "example.com/app/internal/models"
+
+// orderOwner returns the user_account that placed an order.
+func orderOwner(owners map[uint]models.UserAccount, id uint) models.UserAccount {
+	_ = NewUserAccountService
+	return owners[id]
+}
+`))
+
+	if _, err := g.RenameModule("user_account", "account", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// The renamed module is what generating it under the new name produces.
+	fresh := NewMemFS()
+	f := New("", Options{})
+	f.FS = fresh
+	if err := f.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.GenerateModule("account", fields...); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range moduleFiles("account") {
+		if got, want := mustRead(t, mem, name), mustRead(t, fresh, name); got != want {
+			t.Errorf("renamed %s:\n%s\nwant:\n%s", name, got, want)
+		}
+	}
+	for _, name := range moduleFiles("user_account") {
+		if _, err := mem.ReadFile(name); err == nil {
+			t.Errorf("%s still exists", name)
+		}
+	}
+
+	for _, name := range []string{mainFile, fiberRoutesFile, "internal/services/order_owner.go"} {
+		content := mustRead(t, mem, name)
+		if strings.Contains(content, "UserAccount") || strings.Contains(content, "userAccount") {
+			t.Errorf("%s still refers to the old module:\n%s", name, content)
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), name, content, 0); err != nil {
+			t.Errorf("%s does not parse: %v", name, err)
+		}
+	}
+	if owner := mustRead(t, mem, "internal/services/order_owner.go"); !strings.Contains(owner, "owners map[uint]models.Account, id uint) models.Account {") ||
+		!strings.Contains(owner, "_ = NewAccountService") || !strings.Contains(owner, "the user_account that") {
+		t.Errorf("references were not renamed, or the comment was:\n%s", owner)
+	}
+	main := mustRead(t, mem, mainFile)
+	if !strings.Contains(main, "// Initialize account module") || !strings.Contains(main, "accountController := controllers.NewAccountController(accountService)") {
+		t.Errorf("main.go wiring was not renamed:\n%s", main)
+	}
+
+	migrations := mustRead(t, mem, migrationsFile)
+	for _, want := range []string{`RenameTable("user_accounts", "accounts")`, "&models.Account{}", "&models.Order{}"} {
+		if !strings.Contains(migrations, want) {
+			t.Errorf("migrations.go does not contain %s:\n%s", want, migrations)
+		}
+	}
+
+	m, err := g.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if modules := m.Modules(); !reflect.DeepEqual(modules, []string{"account", "example", "order"}) {
+		t.Errorf("manifest modules = %v, want [account example order]", modules)
+	}
+	for _, name := range append(moduleFiles("account"), mainFile, fiberRoutesFile) {
+		if !m.Pristine(name, []byte(mustRead(t, mem, name))) {
+			t.Errorf("%s is not pristine after the rename", name)
+		}
+	}
+
+	// Later generations keep the table rename.
+	if err := g.GenerateModule("invoice"); err != nil {
+		t.Fatal(err)
+	}
+	if migrations := mustRead(t, mem, migrationsFile); !strings.Contains(migrations, `RenameTable("user_accounts", "accounts")`) {
+		t.Errorf("generating a module dropped the table rename:\n%s", migrations)
+	}
+}
+
+func TestRenameModule_FieldsNamedAfterModule(t *testing.T) {
+	fields := []string{"order_number:string", "order_by:string", "reorder_level:int"}
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.GenerateModule("order", fields...); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.GenerateModule("shipment", "order:belongs_to"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := g.RenameModule("order", "purchase", false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fields, columns, query parameters and samples keep their names.
+	fresh := NewMemFS()
+	f := New("", Options{})
+	f.FS = fresh
+	if err := f.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.GenerateModule("purchase", fields...); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range moduleFiles("purchase") {
+		if got, want := mustRead(t, mem, name), mustRead(t, fresh, name); got != want {
+			t.Errorf("renamed %s:\n%s\nwant:\n%s", name, got, want)
+		}
+	}
+
+	// A dependent keeps its field, foreign key and JSON name.
+	shipment := mustRead(t, mem, "internal/models/shipment.go")
+	for _, want := range []string{
+		"OrderID   uint           `json:\"order_id\" gorm:\"not null;index\"`",
+		"Order     *Purchase      `json:\"order,omitempty\" gorm:\"foreignKey:OrderID\"`",
+	} {
+		if !strings.Contains(shipment, want) {
+			t.Errorf("shipment model does not contain %s:\n%s", want, shipment)
+		}
+	}
+}
+
+func TestRenameModule_Upgrade(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	modules := []struct {
+		name   string
+		fields []string
+	}{
+		{"customer", nil},
+		{"tag", nil},
+		{"order", []string{"customer:belongs_to", "tags:many2many", "items:has_many:order_item", "order_number:string"}},
+		{"order_item", []string{"order:belongs_to", "qty:int"}},
+		{"shipment", []string{"order:belongs_to"}},
+		{"coupon", []string{"orders:many2many:order"}},
+	}
+	for _, module := range modules {
+		if err := g.GenerateModule(module.name, module.fields...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := g.RenameModule("order", "purchase", false); err != nil {
+		t.Fatal(err)
+	}
+
+	// The keys and join tables named after the old model are kept.
+	files := map[string][]string{
+		"internal/models/purchase.go": {
+			"`json:\"tags,omitempty\" gorm:\"many2many:order_tags;joinForeignKey:OrderID\"`",
+			"`json:\"items,omitempty\" gorm:\"foreignKey:OrderID\"`",
+		},
+		"internal/models/shipment.go": {"Order     *Purchase"},
+		"internal/models/coupon.go": {
+			"Orders    []Purchase     `json:\"orders,omitempty\" gorm:\"many2many:coupon_orders;joinReferences:OrderID\"`",
+		},
+	}
+	for name, wants := range files {
+		content := mustRead(t, mem, name)
+		for _, want := range wants {
+			if !strings.Contains(content, want) {
+				t.Errorf("%s does not contain %s:\n%s", name, want, content)
+			}
+		}
+	}
+
+	// The recorded data renders what the rename wrote.
+	m, err := g.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name := range m.Files {
+		if !m.Pristine(name, []byte(mustRead(t, mem, name))) {
+			t.Errorf("%s is not pristine after the rename", name)
+		}
+	}
+	result, err := g.Upgrade()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Upgraded) > 0 || len(result.Conflicts) > 0 {
+		t.Errorf("Upgrade() after the rename changed files: %+v", result)
+	}
+	if problems := g.relationProblems("purchase", g.resolveModuleFields("purchase"), nil); len(problems) > 0 {
+		t.Errorf("relationProblems() = %q, want none", problems)
+	}
+}
+
+func TestRenameModule_Errors(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.GenerateModule("product"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		oldName, newName string
+		wantErr          string
+	}{
+		{"customer", "client", "module customer not found"},
+		{"product", "example", "module example already exists"},
+		{"product", "product", "module product already has that name"},
+	}
+	for _, tt := range tests {
+		_, err := g.RenameModule(tt.oldName, tt.newName, false)
+		if err == nil || err.Error() != tt.wantErr {
+			t.Errorf("RenameModule(%q, %q) error = %v, want %q", tt.oldName, tt.newName, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRenameModule_SchemaTable(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Apply(&Schema{Modules: []SchemaModule{{Name: "category", Table: "categories"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.RenameModule("category", "genre", true); err != nil {
+		t.Fatal(err)
+	}
+	model := mustRead(t, mem, "internal/models/genre.go")
+	if !strings.Contains(model, `return "genres"`) {
+		t.Errorf("table name was not renamed:\n%s", model)
+	}
+	if migrations := mustRead(t, mem, migrationsFile); !strings.Contains(migrations, `RenameTable("categories", "genres")`) {
+		t.Errorf("migrations.go does not rename the schema's table:\n%s", migrations)
+	}
+	m, err := g.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Pristine("internal/models/genre.go", []byte(model)) {
+		t.Error("renamed model is not pristine")
+	}
+	if _, err := g.Upgrade(); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustRead(t, mem, "internal/models/genre.go"); got != model {
+		t.Errorf("upgrade changed the renamed model:\n%s", got)
+	}
+}
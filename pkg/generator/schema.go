@@ -0,0 +1,303 @@
+package generator
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// moduleEndpoints are the controller endpoints a module can expose.
+var moduleEndpoints = []string{"list", "get", "create", "update", "delete"}
+
+// Schema describes the modules of a project declaratively; see Apply. It is
+// read from YAML or JSON:
+//
+//	modules:
+//	  - name: product
+//	    fields:
+//	      - {name: name, type: string, unique: true}
+//	      - {name: price, type: decimal, validate: "required,gt=0"}
+//	      - {name: description, type: text, optional: true}
+//	      - {name: category, type: belongs_to}
+//	      - {name: tags, type: many2many}
+//	    endpoints: [list, get, create]
+//	    filters: [name]
+//	    status: {create: 200}
+//	  - name: category
+//	    id: uuid
+//	    table: categories
+//	  - name: tag
+//	    pagination: cursor
+type Schema struct {
+	// Pagination is how the list endpoints of the modules paginate, one of
+	// PaginationModes; offset when empty. Modules can override it.
+	Pagination string         `yaml:"pagination"`
+	Modules    []SchemaModule `yaml:"modules"`
+}
+
+// SchemaModule describes one module of a Schema.
+type SchemaModule struct {
+	Name      string        `yaml:"name"`      // snake_case module name
+	Table     string        `yaml:"table"`     // database table; the name with "s" appended when empty
+	Fields    []SchemaField `yaml:"fields"`    // name:string when empty
+	Endpoints []string      `yaml:"endpoints"` // moduleEndpoints to expose; all when empty
+	// Filters names fields the list endpoint filters on by equality, read from
+	// query parameters of the same name.
+	Filters []string `yaml:"filters"`
+	// Status overrides the success status of endpoints: 200 for create or
+	// delete (instead of 201 and 204).
+	Status map[string]int `yaml:"status"`
+	// ID is the primary key type, one of IDTypes; uint when empty. Foreign
+	// keys referencing the module take its type.
+	ID string `yaml:"id"`
+	// Pagination overrides Schema.Pagination for the module.
+	Pagination string `yaml:"pagination"`
+}
+
+// SchemaField describes one field of a SchemaModule.
+type SchemaField struct {
+	Name     string `yaml:"name"`     // snake_case field name
+	Type     string `yaml:"type"`     // a field spec type, e.g. "decimal"
+	Optional bool   `yaml:"optional"` // nullable column, not required by validation
+	Unique   bool   `yaml:"unique"`   // unique index on the column
+	Size     int    `yaml:"size"`     // maximum length of string and email fields
+	Validate string `yaml:"validate"` // validate tag replacing the type's; "-" for none
+	// ColumnType is the database type of the column replacing the type's,
+	// e.g. "numeric(18,4)" to keep the column of an existing table.
+	ColumnType string `yaml:"column_type"`
+	// Model is the related module of a belongs_to, has_many or many2many
+	// field; see ParseFields for the default.
+	Model string `yaml:"model"`
+}
+
+// ApplyResult lists what Apply did.
+type ApplyResult struct {
+	Created   []string // modules generated for the first time
+	Updated   []string // modules that existed and were regenerated
+	Conflicts []string // edited files merged with conflict markers
+	Unlisted  []string // generated modules missing from the schema; left alone
+}
+
+// LoadSchema reads and validates the YAML or JSON schema file at path.
+func LoadSchema(path string) (*Schema, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := ParseSchema(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", path, err)
+	}
+	return schema, nil
+}
+
+// ParseSchema parses and validates a YAML or JSON schema. Unknown keys are
+// errors, so typos do not silently change the generated code.
+func ParseSchema(content []byte) (*Schema, error) {
+	var schema Schema
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&schema); err != nil {
+		return nil, err
+	}
+	if err := schema.validate(); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// validate reports every problem of the schema.
+func (s *Schema) validate() error {
+	if len(s.Modules) == 0 {
+		return errors.New("no modules")
+	}
+	var errs []error
+	if err := validatePagination(s.Pagination); err != nil {
+		errs = append(errs, err)
+	}
+	seen := map[string]bool{}
+	for i, module := range s.Modules {
+		if !fieldNamePattern.MatchString(module.Name) {
+			errs = append(errs, fmt.Errorf("module %d: name %q must be snake_case (e.g. user_account)", i+1, module.Name))
+			continue
+		}
+		if seen[module.Name] {
+			errs = append(errs, fmt.Errorf("module %s: defined twice", module.Name))
+		}
+		seen[module.Name] = true
+		fields, err := module.fields()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("module %s: %w", module.Name, err))
+		}
+		if err := errors.Join(validateID(module.ID), validatePagination(module.Pagination)); err != nil {
+			errs = append(errs, fmt.Errorf("module %s: %w", module.Name, err))
+		}
+		for _, endpoint := range module.Endpoints {
+			if !slices.Contains(moduleEndpoints, endpoint) {
+				errs = append(errs, fmt.Errorf("module %s: unknown endpoint %q (expected one of %s)", module.Name, endpoint, strings.Join(moduleEndpoints, ", ")))
+			}
+		}
+		if err == nil {
+			errs = append(errs, module.validateFilters(fields))
+		}
+		for _, endpoint := range slices.Sorted(maps.Keys(module.Status)) {
+			statuses, ok := endpointStatuses[endpoint]
+			switch {
+			case !ok:
+				errs = append(errs, fmt.Errorf("module %s: status for unknown endpoint %q", module.Name, endpoint))
+			case !slices.Contains(statuses, module.Status[endpoint]):
+				errs = append(errs, fmt.Errorf("module %s: %s cannot respond with status %d (expected one of %v)", module.Name, endpoint, module.Status[endpoint], statuses))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateFilters reports filters that do not name a field that can be read
+// from a query parameter.
+func (m SchemaModule) validateFilters(fields []Field) error {
+	if len(m.Filters) == 0 {
+		return nil
+	}
+	if len(m.Endpoints) > 0 && !slices.Contains(m.Endpoints, "list") {
+		return fmt.Errorf("module %s: filters need the list endpoint", m.Name)
+	}
+	if len(fields) == 0 {
+		fields = defaultFields()
+	}
+	var errs []error
+	for _, name := range m.Filters {
+		i := slices.IndexFunc(fields, func(f Field) bool { return f.Name == name })
+		switch {
+		case i < 0:
+			errs = append(errs, fmt.Errorf("module %s: filter %q is not a field", m.Name, name))
+		case strings.TrimPrefix(fields[i].GoType, "*") == "time.Time":
+			errs = append(errs, fmt.Errorf("module %s: cannot filter on time field %s", m.Name, name))
+		case !fields[i].IsColumn():
+			errs = append(errs, fmt.Errorf("module %s: cannot filter on %s field %s", m.Name, fields[i].Relation, name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fields returns the module's fields with their schema options applied.
+func (m SchemaModule) fields() ([]Field, error) {
+	var fields []Field
+	seen := map[string]bool{}
+	for _, sf := range m.Fields {
+		spec := sf.Name + ":" + sf.Type
+		if sf.Model != "" {
+			spec += ":" + sf.Model
+		}
+		field, err := parseField(spec)
+		if err != nil {
+			return nil, err
+		}
+		if seen[field.Name] {
+			return nil, fmt.Errorf("duplicate field %q", field.Name)
+		}
+		seen[field.Name] = true
+		if sf.Size < 0 || (sf.Size > 0 && field.Type != "string" && field.Type != "email") {
+			return nil, fmt.Errorf("field %s: size needs a positive value and type string or email", field.Name)
+		}
+		if sf.ColumnType != "" && (field.Relation != "" || !columnTypePattern.MatchString(sf.ColumnType)) {
+			return nil, fmt.Errorf("field %s: column_type needs a column type such as numeric(18,4) and a field that is not a relation", field.Name)
+		}
+		fields = append(fields, field.withOptions(fieldOptions{
+			Optional:   sf.Optional,
+			Unique:     sf.Unique,
+			Size:       sf.Size,
+			Rules:      sf.Validate,
+			ColumnType: sf.ColumnType,
+		}))
+	}
+	return fields, nil
+}
+
+// Apply generates the modules of schema that do not exist yet and regenerates
+// those that do, so the code follows edits to the schema. Files nobody edited
+// are overwritten; edited files are merged with the new output, as with
+// Upgrade. Modules missing from the schema are left alone and reported. All
+// files are written or none are.
+func (g *Generator) Apply(schema *Schema) (ApplyResult, error) {
+	var result ApplyResult
+	if err := schema.validate(); err != nil {
+		return result, err
+	}
+	projectName, err := g.getProjectName()
+	if err != nil {
+		return result, fmt.Errorf("could not determine project name: %w", err)
+	}
+
+	declared := map[string][]Field{}
+	ids := map[string]string{}
+	for _, module := range schema.Modules {
+		declared[module.Name], _ = module.fields()
+		ids[module.Name] = normalizeID(module.ID)
+	}
+	var problems []error
+	for _, module := range schema.Modules {
+		for _, problem := range g.relationProblems(module.Name, declared[module.Name], declared) {
+			problems = append(problems, fmt.Errorf("module %s: %s", module.Name, problem))
+		}
+	}
+	if len(problems) > 0 {
+		return result, errors.Join(problems...)
+	}
+
+	listed := map[string]bool{}
+	for _, module := range schema.Modules {
+		listed[module.Name] = true
+		files, err := g.existingModuleFiles(module.Name)
+		if err != nil {
+			return result, err
+		}
+		if len(files) == 0 {
+			result.Created = append(result.Created, module.Name)
+		} else {
+			result.Updated = append(result.Updated, module.Name)
+		}
+	}
+	m, err := g.Manifest()
+	if err != nil {
+		return result, err
+	}
+	for _, module := range m.Modules() {
+		if !listed[module] {
+			result.Unlisted = append(result.Unlisted, module)
+		}
+	}
+
+	g.regenerate, g.conflicts = true, nil
+	err = g.transaction(func() error {
+		var errs []error
+		for _, module := range schema.Modules {
+			fields := g.withRelationIDs(module.Name, ids[module.Name], declared[module.Name], ids)
+			opts := moduleOptions{Endpoints: module.Endpoints, Table: module.Table, Filters: module.Filters, Status: module.Status, ID: module.ID,
+				Pagination: cmp.Or(module.Pagination, schema.Pagination)}
+			if err := g.createModule(module.Name, projectName, fields, opts); err != nil {
+				errs = append(errs, fmt.Errorf("module %s: %w", module.Name, err))
+			}
+		}
+		return errors.Join(errs...)
+	})
+	result.Conflicts = g.conflicts
+	g.regenerate, g.conflicts = false, nil
+	if err != nil {
+		return result, err
+	}
+
+	// Registered after the files, as GenerateModule does.
+	var errs []error
+	for _, module := range schema.Modules {
+		errs = append(errs, g.RegisterModule(module.Name, projectName))
+	}
+	return result, errors.Join(errs...)
+}
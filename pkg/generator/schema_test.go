@@ -0,0 +1,184 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string
+	}{
+		{
+			name: "yaml",
+			schema: `modules:
+  - name: product
+    fields:
+      - {name: name, type: string}
+    endpoints: [list, get]
+`,
+		},
+		{
+			name:   "json",
+			schema: `{"modules": [{"name": "product", "fields": [{"name": "price", "type": "decimal", "optional": true}]}]}`,
+		},
+		{
+			name:    "unknown key",
+			schema:  "modules:\n  - name: product\n    feilds: []\n",
+			wantErr: "field feilds not found",
+		},
+		{
+			name:    "no modules",
+			schema:  "modules: []\n",
+			wantErr: "no modules",
+		},
+		{
+			name: "filters and status",
+			schema: `modules:
+  - name: product
+    fields:
+      - {name: status, type: string}
+      - {name: released_at, type: time}
+    filters: [status, colour, released_at]
+    status: {create: 202, delete: 200, patch: 200}
+`,
+			wantErr: `module product: filter "colour" is not a field
+module product: cannot filter on time field released_at
+module product: create cannot respond with status 202 (expected one of [201 200])
+module product: status for unknown endpoint "patch"`,
+		},
+		{
+			name:    "id type",
+			schema:  "modules:\n  - name: customer\n    id: uuid\n  - name: order\n    id: serial\n",
+			wantErr: `module order: unsupported id type "serial" (use one of: uint, int64, uuid, ulid)`,
+		},
+		{
+			name:    "filters without list",
+			schema:  "modules:\n  - name: product\n    endpoints: [get]\n    filters: [name]\n",
+			wantErr: "module product: filters need the list endpoint",
+		},
+		{
+			name:    "bad column type",
+			schema:  "modules:\n  - name: product\n    fields:\n      - {name: price, type: decimal, column_type: \"numeric; drop\"}\n",
+			wantErr: "field price: column_type needs a column type such as numeric(18,4)",
+		},
+		{
+			name: "every problem is reported",
+			schema: `modules:
+  - name: product
+    fields:
+      - {name: price, type: money_bag}
+    endpoints: [list, patch]
+  - name: product
+  - name: Order
+`,
+			wantErr: `module product: unsupported type "money_bag" for field price
+module product: unknown endpoint "patch" (expected one of list, get, create, update, delete)
+module product: defined twice
+module 3: name "Order" must be snake_case (e.g. user_account)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSchema([]byte(tt.schema))
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("ParseSchema() error = %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("ParseSchema() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := ParseSchema([]byte(`modules:
+  - name: product
+    fields:
+      - {name: name, type: string, unique: true}
+      - {name: price, type: decimal, validate: "required,gt=0"}
+      - {name: description, type: text, optional: true}
+    endpoints: [list, get, create]
+  - name: category
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := g.Apply(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ApplyResult{Created: []string{"product", "category"}, Unlisted: []string{"example"}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Apply() = %+v, want %+v", result, want)
+	}
+
+	model := mustRead(t, mem, "internal/models/product.go")
+	for _, want := range []string{
+		"Name        string         `json:\"name\" gorm:\"size:255;not null;uniqueIndex\"`",
+		"Description *string        `json:\"description\" gorm:\"type:text\"`",
+	} {
+		if !strings.Contains(model, want) {
+			t.Errorf("model does not contain %s:\n%s", want, model)
+		}
+	}
+	request := mustRead(t, mem, "internal/requests/product_request.go")
+	for _, want := range []string{`validate:"required,gt=0"`, "Description *string `json:\"description\"`\n"} {
+		if !strings.Contains(request, want) {
+			t.Errorf("request does not contain %s:\n%s", want, request)
+		}
+	}
+	controller := mustRead(t, mem, "internal/controllers/product_controller.go")
+	if strings.Contains(controller, "Update") || strings.Contains(controller, "Delete") || !strings.Contains(controller, "c.Create") {
+		t.Errorf("controller does not expose exactly list, get and create:\n%s", controller)
+	}
+	if !strings.Contains(mustRead(t, mem, mainFile), "categoryController") {
+		t.Error("category was not registered in main.go")
+	}
+
+	// Edit the schema and a generated file, and apply again.
+	editedService := strings.Replace(mustRead(t, mem, "internal/services/product_service.go"),
+		"func (s *productService) List(", "// List returns a page of products.\nfunc (s *productService) List(", 1)
+	_ = mem.WriteFile("internal/services/product_service.go", []byte(editedService))
+	schema.Modules[0].Fields = append(schema.Modules[0].Fields, SchemaField{Name: "stock", Type: "int"})
+
+	result, err = g.Apply(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = ApplyResult{
+		Updated:  []string{"product", "category"},
+		Unlisted: []string{"example"},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("second Apply() = %+v, want %+v", result, want)
+	}
+	for _, name := range []string{"internal/models/product.go", "internal/requests/product_request.go", "tests/fixtures/product_fixture.go"} {
+		if content := mustRead(t, mem, name); !strings.Contains(content, "Stock") {
+			t.Errorf("%s was not updated with the new field:\n%s", name, content)
+		}
+	}
+	// The edit and the new field are merged.
+	if service := mustRead(t, mem, "internal/services/product_service.go"); !strings.Contains(service, "// List returns a page of products.") ||
+		!strings.Contains(service, "Stock:") || strings.Contains(service, conflictStart) {
+		t.Errorf("edited service does not merge the edit with the new field:\n%s", service)
+	}
+
+	// Applying an unchanged schema changes nothing.
+	before := mustRead(t, mem, "internal/models/product.go")
+	if result, err = g.Apply(schema); err != nil {
+		t.Fatal(err)
+	}
+	if result.Conflicts != nil || mustRead(t, mem, "internal/models/product.go") != before {
+		t.Errorf("applying the same schema again changed files: %+v", result)
+	}
+}
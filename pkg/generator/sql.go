@@ -0,0 +1,686 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// sqlTable is a table read from PostgreSQL DDL or a live database.
+type sqlTable struct {
+	name       string
+	columns    []*sqlColumn
+	primaryKey []string
+}
+
+// sqlColumn is a column of a sqlTable.
+type sqlColumn struct {
+	name       string
+	typeName   string // lower case, e.g. "character varying"
+	args       []int  // type arguments, e.g. [12 2] for numeric(12,2)
+	array      bool
+	notNull    bool
+	unique     bool
+	references string // table referenced by a foreign key
+}
+
+func (t *sqlTable) column(name string) *sqlColumn {
+	for _, c := range t.columns {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// requirePrimaryKey marks the primary key columns NOT NULL, as PostgreSQL
+// does.
+func (t *sqlTable) requirePrimaryKey() {
+	for _, name := range t.primaryKey {
+		if c := t.column(name); c != nil {
+			c.notNull = true
+		}
+	}
+}
+
+// sqlFieldTypes maps PostgreSQL column types, and the SQLite names read by
+// IntrospectDatabase, to field spec types.
+var sqlFieldTypes = map[string]string{
+	"character varying": "string", "varchar": "string", "character": "string", "char": "string", "bpchar": "string",
+	"text": "text", "citext": "text", "json": "text", "jsonb": "text", "xml": "text",
+	"smallint": "int", "int2": "int", "integer": "int", "int": "int", "int4": "int",
+	"smallserial": "int", "serial": "int", "serial2": "int", "serial4": "int",
+	"bigint": "int64", "int8": "int64", "bigserial": "int64", "serial8": "int64",
+	"numeric": "decimal", "decimal": "decimal", "money": "decimal",
+	"real": "float", "float4": "float", "double precision": "float", "double": "float", "float8": "float", "float": "float",
+	"boolean": "bool", "bool": "bool",
+	"date": "time", "datetime": "time", "timestamp": "time", "timestamptz": "time",
+	"timestamp with time zone": "time", "timestamp without time zone": "time",
+	"time": "time", "timetz": "time", "time with time zone": "time", "time without time zone": "time",
+	"uuid": "uuid",
+}
+
+// LoadSQLSchema reads the PostgreSQL DDL file at path; see ParseSQLSchema.
+func LoadSQLSchema(path string) (*Schema, []string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	schema, warnings, err := ParseSQLSchema(string(content))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return schema, warnings, nil
+}
+
+// ParseSQLSchema reads the CREATE TABLE statements of PostgreSQL DDL, such as
+// pg_dump --schema-only output, and returns a Schema with one module per
+// table. Primary keys, unique constraints and foreign keys added later with
+// ALTER TABLE are taken into account; other statements are ignored. Column
+// types map to field types, NOT NULL and lengths to the validate and GORM
+// tags. The warnings name what could not be carried over.
+func ParseSQLSchema(ddl string) (*Schema, []string, error) {
+	tokens, err := sqlTokens(ddl)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &sqlParser{tokens: tokens}
+	var tables []*sqlTable
+	byName := map[string]*sqlTable{}
+	for !p.done() {
+		stmt := p.statement()
+		switch {
+		case stmt.keyword("create"):
+			table, err := parseCreateTable(stmt)
+			if err != nil {
+				return nil, nil, err
+			}
+			if table == nil {
+				continue
+			}
+			if byName[table.name] != nil {
+				return nil, nil, fmt.Errorf("table %s is created twice", table.name)
+			}
+			byName[table.name] = table
+			tables = append(tables, table)
+		case stmt.keyword("alter"):
+			if err := parseAlterTable(stmt, byName); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if len(tables) == 0 {
+		return nil, nil, errors.New("no CREATE TABLE statements found")
+	}
+	return sqlToSchema(tables)
+}
+
+// sqlToSchema converts the parsed tables to a schema.
+func sqlToSchema(tables []*sqlTable) (*Schema, []string, error) {
+	var warnings []string
+	warn := func(format string, args ...any) { warnings = append(warnings, fmt.Sprintf(format, args...)) }
+	schema := &Schema{}
+	modules := map[string]string{} // module name -> table
+	byName := map[string]*sqlTable{}
+	for _, table := range tables {
+		byName[table.name] = table
+	}
+	for _, table := range tables {
+		name := toSingular(table.name)
+		if !fieldNamePattern.MatchString(name) {
+			return nil, nil, fmt.Errorf("table %s: cannot derive a snake_case module name", table.name)
+		}
+		if other, ok := modules[name]; ok {
+			return nil, nil, fmt.Errorf("tables %s and %s both map to module %s", other, table.name, name)
+		}
+		modules[name] = table.name
+		module := SchemaModule{Name: name}
+		if table.name != name+"s" {
+			module.Table = table.name
+		}
+
+		if id, ok := tableID(table); ok {
+			module.ID = id
+		} else if c := table.column("id"); c == nil || len(table.primaryKey) != 1 || table.primaryKey[0] != "id" {
+			warn("table %s: primary key (%s) is not an id column; the model uses an uint id", table.name, strings.Join(table.primaryKey, ", "))
+		} else {
+			warn("table %s: id is %s; the model uses an uint id", table.name, c.typeName)
+		}
+
+		for _, c := range table.columns {
+			if _, reserved := reservedFields[c.name]; reserved {
+				continue
+			}
+			if !fieldNamePattern.MatchString(c.name) {
+				warn("table %s: column %q skipped; its name is not snake_case", table.name, c.name)
+				continue
+			}
+			field := SchemaField{Name: c.name, Optional: !c.notNull, Unique: c.unique}
+			fieldType, ok := sqlFieldTypes[c.typeName]
+			switch {
+			case c.array:
+				warn("table %s: array column %s is generated as text", table.name, c.name)
+				fieldType = "text"
+			case !ok:
+				warn("table %s: column %s has unsupported type %s; it is generated as text", table.name, c.name, c.typeName)
+				fieldType = "text"
+			case fieldType == "string" && len(c.args) == 1:
+				field.Size = c.args[0]
+			case fieldType == "string":
+				fieldType = "text" // unbounded varchar
+			case fieldType == "decimal":
+				field.ColumnType = numericType(c)
+			case c.typeName == "json" || c.typeName == "jsonb":
+				// A string holding the document, so the column keeps its
+				// operators and indexes.
+				field.ColumnType, field.Validate = c.typeName, "required,json"
+				if field.Optional {
+					field.Validate = "omitempty,json"
+				}
+			}
+			field.Type = fieldType
+			if c.references != "" {
+				if relation, ok := belongsTo(table, c, field, byName[c.references]); ok {
+					field = relation
+				} else {
+					warn("table %s: foreign key %s references %s; it is generated as a plain column", table.name, c.name, c.references)
+				}
+			}
+			module.Fields = append(module.Fields, field)
+		}
+		if len(module.Fields) == 0 {
+			warn("table %s has no columns besides id and timestamps; the module gets a name field", table.name)
+		}
+		schema.Modules = append(schema.Modules, module)
+	}
+	if err := schema.validate(); err != nil {
+		return nil, nil, err
+	}
+	return schema, warnings, nil
+}
+
+// numericType returns the column type keeping the precision and scale of the
+// numeric column c, or "" when they are those of the decimal type.
+func numericType(c *sqlColumn) string {
+	switch {
+	case c.typeName == "money":
+		return ""
+	case len(c.args) == 0:
+		return c.typeName // unconstrained
+	case len(c.args) == 1:
+		return fmt.Sprintf("%s(%d)", c.typeName, c.args[0])
+	case c.args[0] == 12 && c.args[1] == 2:
+		return ""
+	}
+	return fmt.Sprintf("%s(%d,%d)", c.typeName, c.args[0], c.args[1])
+}
+
+// tableID returns the ID type (see IDTypes) of the module of table: uint
+// for an integer id, uuid, or ulid for a char(26) id. It reports false when
+// the primary key is not such an id column.
+func tableID(t *sqlTable) (string, bool) {
+	c := t.column("id")
+	if len(t.primaryKey) != 1 || t.primaryKey[0] != "id" || c == nil {
+		return "", false
+	}
+	switch sqlFieldTypes[c.typeName] {
+	case "int", "int64":
+		return "", true
+	case "uuid":
+		return "uuid", true
+	case "string":
+		return "ulid", len(c.args) == 1 && c.args[0] == 26
+	}
+	return "", false
+}
+
+// belongsTo returns the belongs_to field for the foreign key column c of
+// table, e.g. "customer" for customer_id. It reports false when the column is
+// not named after the relation, the referenced table is not imported or has
+// no id, the column's type does not match the id, or another column takes
+// the relation's name.
+func belongsTo(table *sqlTable, c *sqlColumn, field SchemaField, referenced *sqlTable) (SchemaField, bool) {
+	name, ok := strings.CutSuffix(c.name, "_id")
+	if !ok || referenced == nil || table.column(name) != nil {
+		return field, false
+	}
+	id, ok := tableID(referenced)
+	switch {
+	case !ok:
+		return field, false
+	case id == "" && field.Type != "int" && field.Type != "int64",
+		id == "uuid" && field.Type != "uuid",
+		id == "ulid" && (field.Type != "string" || field.Size != 26):
+		return field, false
+	}
+	relation := SchemaField{Name: name, Type: "belongs_to", Optional: field.Optional, Unique: field.Unique}
+	if model := toSingular(referenced.name); model != name {
+		relation.Model = model
+	}
+	return relation, true
+}
+
+// toSingular turns a table name into a module name ("order_items" ->
+// "order_item", "categories" -> "category"), undoing toPlural.
+func toSingular(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies") && len(s) > 3:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"), strings.HasSuffix(s, "ches"), strings.HasSuffix(s, "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "ss"), strings.HasSuffix(s, "us"), strings.HasSuffix(s, "is"):
+		return s
+	case strings.HasSuffix(s, "s"):
+		return s[:len(s)-1]
+	}
+	return s
+}
+
+// parseCreateTable parses "CREATE [TEMP|UNLOGGED] TABLE [IF NOT EXISTS] name
+// (...)". It returns nil for other CREATE statements.
+func parseCreateTable(p *sqlParser) (*sqlTable, error) {
+	p.next() // CREATE
+	for p.keyword("temp") || p.keyword("temporary") || p.keyword("unlogged") || p.keyword("global") || p.keyword("local") {
+		p.next()
+	}
+	if !p.keyword("table") {
+		return nil, nil
+	}
+	p.next()
+	if p.keyword("if") {
+		p.next()
+		p.next() // NOT
+		p.next() // EXISTS
+	}
+	table := &sqlTable{name: p.qualifiedName()}
+	if table.name == "" {
+		return nil, p.errorf("expected a table name")
+	}
+	if !p.punct("(") {
+		return nil, nil // CREATE TABLE ... AS / OF / PARTITION OF
+	}
+	p.next()
+	for !p.done() && !p.punct(")") {
+		def := p.element()
+		if err := table.addDefinition(def); err != nil {
+			return nil, fmt.Errorf("table %s: %w", table.name, err)
+		}
+		if p.punct(",") {
+			p.next()
+		}
+	}
+	table.requirePrimaryKey()
+	return table, nil
+}
+
+// addDefinition adds a column or table constraint of a CREATE TABLE.
+func (t *sqlTable) addDefinition(p *sqlParser) error {
+	switch {
+	case p.done():
+		return nil
+	case p.keyword("constraint"), p.keyword("primary"), p.keyword("unique"), p.keyword("foreign"),
+		p.keyword("check"), p.keyword("exclude"), p.keyword("like"):
+		return t.addConstraint(p)
+	}
+
+	c := &sqlColumn{name: p.name()}
+	t.columns = append(t.columns, c)
+	c.parseType(p)
+	if c.typeName == "" {
+		return p.errorf("column %s: expected a type", c.name)
+	}
+	for !p.done() {
+		switch {
+		case p.keyword("not"):
+			p.next()
+			if p.keyword("null") {
+				c.notNull = true
+				p.next()
+			}
+		case p.keyword("primary"):
+			p.next()
+			p.next() // KEY
+			t.primaryKey = []string{c.name}
+			c.notNull = true
+		case p.keyword("unique"):
+			p.next()
+			c.unique = true
+		case p.keyword("references"):
+			p.next()
+			c.references = p.qualifiedName()
+		case p.punct("("):
+			p.group() // CHECK (...), DEFAULT f(...), GENERATED ... AS (...)
+		default:
+			p.next() // NULL, DEFAULT, CONSTRAINT name, COLLATE, ON DELETE, ...
+		}
+	}
+	return nil
+}
+
+// parseType reads a column type such as "varchar(80)" or "integer[]".
+func (c *sqlColumn) parseType(p *sqlParser) {
+	c.typeName = p.typeName()
+	if p.punct("(") {
+		for _, arg := range p.group() {
+			if n, err := strconv.Atoi(arg.text); err == nil {
+				c.args = append(c.args, n)
+			}
+		}
+	}
+	for p.punct("[") {
+		c.array = true
+		p.group()
+	}
+}
+
+// addConstraint adds a table constraint such as "PRIMARY KEY (id)" or
+// "CONSTRAINT fk FOREIGN KEY (customer_id) REFERENCES customers (id)".
+func (t *sqlTable) addConstraint(p *sqlParser) error {
+	if p.keyword("constraint") {
+		p.next()
+		p.next() // constraint name
+	}
+	switch {
+	case p.keyword("primary"):
+		p.next()
+		p.next() // KEY
+		t.primaryKey = p.nameList()
+	case p.keyword("unique"):
+		p.next()
+		if columns := p.nameList(); len(columns) == 1 {
+			if c := t.column(columns[0]); c != nil {
+				c.unique = true
+			}
+		}
+	case p.keyword("foreign"):
+		p.next()
+		p.next() // KEY
+		columns := p.nameList()
+		if !p.keyword("references") {
+			return p.errorf("expected REFERENCES")
+		}
+		p.next()
+		referenced := p.qualifiedName()
+		if len(columns) == 1 {
+			if c := t.column(columns[0]); c != nil {
+				c.references = referenced
+			}
+		}
+	}
+	return nil
+}
+
+// parseAlterTable applies "ALTER TABLE [ONLY] name ADD [CONSTRAINT name] ..."
+// constraints, as written by pg_dump, to the tables created before.
+func parseAlterTable(p *sqlParser, tables map[string]*sqlTable) error {
+	p.next() // ALTER
+	if !p.keyword("table") {
+		return nil
+	}
+	p.next()
+	for p.keyword("only") || p.keyword("if") || p.keyword("exists") {
+		p.next()
+	}
+	table := tables[p.qualifiedName()]
+	if table == nil || !p.keyword("add") {
+		return nil
+	}
+	p.next()
+	if p.keyword("constraint") || p.keyword("primary") || p.keyword("unique") || p.keyword("foreign") {
+		if err := table.addConstraint(p); err != nil {
+			return fmt.Errorf("table %s: %w", table.name, err)
+		}
+		table.requirePrimaryKey()
+	}
+	return nil
+}
+
+// sqlToken is a token of SQL text. Unquoted identifiers and keywords are
+// lower case; quoted identifiers keep their case.
+type sqlToken struct {
+	text   string
+	quoted bool // a "quoted identifier" or 'string'
+	line   int
+}
+
+// sqlTokens splits SQL text into tokens, dropping comments.
+func sqlTokens(sql string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	line := 1
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case unicode.IsSpace(rune(c)):
+			i++
+		case strings.HasPrefix(sql[i:], "--"):
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(sql[i:i+2+end], "\n")
+			i += end + 4
+		case c == '"' || c == '\'':
+			var text strings.Builder
+			start := line
+			j := i + 1
+			for ; j < len(sql); j++ {
+				if sql[j] == c {
+					if j+1 < len(sql) && sql[j+1] == c { // doubled quote
+						text.WriteByte(c)
+						j++
+						continue
+					}
+					break
+				}
+				if sql[j] == '\n' {
+					line++
+				}
+				text.WriteByte(sql[j])
+			}
+			if j >= len(sql) {
+				return nil, fmt.Errorf("line %d: unterminated quote", start)
+			}
+			tokens = append(tokens, sqlToken{text: text.String(), quoted: true, line: start})
+			i = j + 1
+		case c == '$':
+			// Dollar-quoted string, e.g. the body of a function.
+			end := strings.IndexByte(sql[i+1:], '$')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated dollar quote", line)
+			}
+			tag := sql[i : i+end+2]
+			closing := strings.Index(sql[i+len(tag):], tag)
+			if closing < 0 {
+				return nil, fmt.Errorf("line %d: unterminated dollar quote", line)
+			}
+			line += strings.Count(sql[i:i+len(tag)+closing], "\n")
+			tokens = append(tokens, sqlToken{text: sql[i+len(tag) : i+len(tag)+closing], quoted: true, line: line})
+			i += len(tag)*2 + closing
+		case c == '_' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)):
+			j := i
+			for j < len(sql) && (sql[j] == '_' || sql[j] == '$' || unicode.IsLetter(rune(sql[j])) || unicode.IsDigit(rune(sql[j]))) {
+				j++
+			}
+			tokens = append(tokens, sqlToken{text: strings.ToLower(sql[i:j]), line: line})
+			i = j
+		default:
+			tokens = append(tokens, sqlToken{text: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// sqlParser reads a list of tokens.
+type sqlParser struct {
+	tokens []sqlToken
+	pos    int
+}
+
+func (p *sqlParser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *sqlParser) peek() sqlToken {
+	if p.done() {
+		return sqlToken{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *sqlParser) next() sqlToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// keyword reports whether the next token is the unquoted keyword kw.
+func (p *sqlParser) keyword(kw string) bool {
+	t := p.peek()
+	return !t.quoted && t.text == kw
+}
+
+// punct reports whether the next token is the punctuation s.
+func (p *sqlParser) punct(s string) bool { return p.keyword(s) }
+
+func (p *sqlParser) errorf(format string, args ...any) error {
+	line := 0
+	if p.pos < len(p.tokens) {
+		line = p.tokens[p.pos].line
+	} else if len(p.tokens) > 0 {
+		line = p.tokens[len(p.tokens)-1].line
+	}
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+// statement returns a parser for the tokens up to the next top-level ";" and
+// moves past it.
+func (p *sqlParser) statement() *sqlParser {
+	start, depth := p.pos, 0
+	for !p.done() {
+		t := p.next()
+		if t.quoted {
+			continue
+		}
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case ";":
+			if depth <= 0 {
+				return &sqlParser{tokens: p.tokens[start : p.pos-1]}
+			}
+		}
+	}
+	return &sqlParser{tokens: p.tokens[start:]}
+}
+
+// element returns a parser for the tokens up to the next "," or ")" at the
+// current depth and moves to it.
+func (p *sqlParser) element() *sqlParser {
+	start, depth := p.pos, 0
+	for !p.done() {
+		t := p.peek()
+		if !t.quoted {
+			switch {
+			case t.text == "(" || t.text == "[":
+				depth++
+			case (t.text == ")" || t.text == "]") && depth > 0:
+				depth--
+			case (t.text == "," || t.text == ")") && depth == 0:
+				return &sqlParser{tokens: p.tokens[start:p.pos]}
+			}
+		}
+		p.next()
+	}
+	return &sqlParser{tokens: p.tokens[start:]}
+}
+
+// group returns the tokens of a parenthesized or bracketed group, without
+// the brackets and commas, and moves past it.
+func (p *sqlParser) group() []sqlToken {
+	var tokens []sqlToken
+	depth := 0
+	for !p.done() {
+		t := p.next()
+		if !t.quoted {
+			switch t.text {
+			case "(", "[":
+				depth++
+				if depth == 1 {
+					continue
+				}
+			case ")", "]":
+				depth--
+				if depth == 0 {
+					return tokens
+				}
+			case ",":
+				if depth == 1 {
+					continue
+				}
+			}
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens
+}
+
+// name reads an identifier.
+func (p *sqlParser) name() string {
+	return p.next().text
+}
+
+// qualifiedName reads "[schema.]name" and returns name.
+func (p *sqlParser) qualifiedName() string {
+	name := p.name()
+	for p.punct(".") {
+		p.next()
+		name = p.name()
+	}
+	return name
+}
+
+// nameList reads "(a, b)" and returns the names.
+func (p *sqlParser) nameList() []string {
+	if !p.punct("(") {
+		return nil
+	}
+	var names []string
+	for _, t := range p.group() {
+		names = append(names, t.text)
+	}
+	return names
+}
+
+// typeName reads a possibly multi-word type such as "double precision" or
+// "timestamp with time zone", dropping a schema qualifier.
+func (p *sqlParser) typeName() string {
+	words := []string{p.qualifiedName()}
+	for {
+		switch {
+		case words[0] == "character" && p.keyword("varying"),
+			words[0] == "bit" && p.keyword("varying"),
+			words[0] == "double" && p.keyword("precision"):
+			words = append(words, p.next().text)
+		case (words[0] == "timestamp" || words[0] == "time") && (p.keyword("with") || p.keyword("without")):
+			if p.punct("(") {
+				p.group()
+			}
+			words = append(words, p.next().text)
+			words = append(words, p.next().text) // TIME
+			words = append(words, p.next().text) // ZONE
+		default:
+			return strings.Join(words, " ")
+		}
+	}
+}
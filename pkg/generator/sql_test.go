@@ -0,0 +1,238 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSQLSchema(t *testing.T) {
+	tests := []struct {
+		name         string
+		ddl          string
+		want         []SchemaModule
+		wantWarnings []string
+		wantErr      string
+	}{
+		{
+			name: "column types and constraints",
+			ddl: `CREATE TABLE products (
+	id serial PRIMARY KEY,
+	"name" varchar(80) NOT NULL UNIQUE, -- shown in listings
+	notes text,
+	price numeric(12,2) NOT NULL DEFAULT 0,
+	stock integer NOT NULL CHECK (stock >= 0),
+	views bigint,
+	rating double precision,
+	active boolean NOT NULL,
+	released_at timestamp with time zone,
+	sku uuid NOT NULL,
+	created_at timestamptz NOT NULL DEFAULT now()
+);`,
+			want: []SchemaModule{{Name: "product", Fields: []SchemaField{
+				{Name: "name", Type: "string", Size: 80, Unique: true},
+				{Name: "notes", Type: "text", Optional: true},
+				{Name: "price", Type: "decimal"},
+				{Name: "stock", Type: "int"},
+				{Name: "views", Type: "int64", Optional: true},
+				{Name: "rating", Type: "float", Optional: true},
+				{Name: "active", Type: "bool"},
+				{Name: "released_at", Type: "time", Optional: true},
+				{Name: "sku", Type: "uuid"},
+			}}},
+		},
+		{
+			name: "numeric precision and json",
+			ddl: `CREATE TABLE rates (
+	id serial PRIMARY KEY,
+	amount numeric(12,2) NOT NULL,
+	rate numeric(18,4) NOT NULL,
+	units decimal(10),
+	ratio numeric,
+	terms jsonb NOT NULL,
+	extra json
+);`,
+			want: []SchemaModule{{Name: "rate", Fields: []SchemaField{
+				{Name: "amount", Type: "decimal"},
+				{Name: "rate", Type: "decimal", ColumnType: "numeric(18,4)"},
+				{Name: "units", Type: "decimal", Optional: true, ColumnType: "decimal(10)"},
+				{Name: "ratio", Type: "decimal", Optional: true, ColumnType: "numeric"},
+				{Name: "terms", Type: "text", Validate: "required,json", ColumnType: "jsonb"},
+				{Name: "extra", Type: "text", Optional: true, Validate: "omitempty,json", ColumnType: "json"},
+			}}},
+		},
+		{
+			name: "pg_dump output",
+			ddl: `SET statement_timeout = 0;
+CREATE FUNCTION touch() RETURNS trigger AS $$ BEGIN RETURN NEW; END; $$ LANGUAGE plpgsql;
+CREATE TABLE public.categories (
+    id bigint NOT NULL,
+    title character varying NOT NULL,
+    parent_id bigint
+);
+CREATE SEQUENCE public.categories_id_seq;
+ALTER TABLE ONLY public.categories
+    ADD CONSTRAINT categories_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.categories
+    ADD CONSTRAINT categories_title_key UNIQUE (title);
+ALTER TABLE ONLY public.categories
+    ADD CONSTRAINT categories_parent_id_fkey FOREIGN KEY (parent_id) REFERENCES public.categories(id);`,
+			want: []SchemaModule{{Name: "category", Table: "categories", Fields: []SchemaField{
+				{Name: "title", Type: "text", Unique: true},
+				{Name: "parent", Type: "belongs_to", Optional: true, Model: "category"},
+			}}},
+		},
+		{
+			name: "foreign keys",
+			ddl: `CREATE TABLE customers (id serial PRIMARY KEY, name text NOT NULL);
+CREATE TABLE orders (
+	id serial PRIMARY KEY,
+	customer_id integer NOT NULL REFERENCES customers (id),
+	region_id integer REFERENCES regions (id),
+	buyer integer REFERENCES customers (id)
+);`,
+			want: []SchemaModule{
+				{Name: "customer", Fields: []SchemaField{{Name: "name", Type: "text"}}},
+				{Name: "order", Fields: []SchemaField{
+					{Name: "customer", Type: "belongs_to"},
+					{Name: "region_id", Type: "int", Optional: true},
+					{Name: "buyer", Type: "int", Optional: true},
+				}},
+			},
+			wantWarnings: []string{
+				"table orders: foreign key region_id references regions; it is generated as a plain column",
+				"table orders: foreign key buyer references customers; it is generated as a plain column",
+			},
+		},
+		{
+			name: "uuid and ulid ids",
+			ddl: `CREATE TABLE accounts (id uuid PRIMARY KEY DEFAULT gen_random_uuid(), email text NOT NULL);
+CREATE TABLE sessions (
+	id char(26) PRIMARY KEY,
+	account_id uuid NOT NULL REFERENCES accounts (id),
+	parent_id integer REFERENCES sessions (id)
+);
+CREATE TABLE tokens (id text PRIMARY KEY, session_id char(26) REFERENCES sessions (id));`,
+			want: []SchemaModule{
+				{Name: "account", ID: "uuid", Fields: []SchemaField{{Name: "email", Type: "text"}}},
+				{Name: "session", ID: "ulid", Fields: []SchemaField{
+					{Name: "account", Type: "belongs_to"},
+					{Name: "parent_id", Type: "int", Optional: true},
+				}},
+				{Name: "token", Fields: []SchemaField{{Name: "session", Type: "belongs_to", Optional: true}}},
+			},
+			wantWarnings: []string{
+				"table sessions: foreign key parent_id references sessions; it is generated as a plain column",
+				"table tokens: id is text; the model uses an uint id",
+			},
+		},
+		{
+			name: "unsupported columns and keys",
+			ddl: `CREATE TABLE "order_items" (
+	order_id int, line int, tags text[], at point, "Note" text,
+	PRIMARY KEY (order_id, line)
+);`,
+			want: []SchemaModule{{Name: "order_item", Fields: []SchemaField{
+				{Name: "order_id", Type: "int"},
+				{Name: "line", Type: "int"},
+				{Name: "tags", Type: "text", Optional: true},
+				{Name: "at", Type: "text", Optional: true},
+			}}},
+			wantWarnings: []string{
+				"table order_items: primary key (order_id, line) is not an id column; the model uses an uint id",
+				"table order_items: array column tags is generated as text",
+				"table order_items: column at has unsupported type point; it is generated as text",
+				`table order_items: column "Note" skipped; its name is not snake_case`,
+			},
+		},
+		{
+			name:    "no tables",
+			ddl:     "CREATE INDEX products_name ON products (name);",
+			wantErr: "no CREATE TABLE statements found",
+		},
+		{
+			name:    "unterminated comment",
+			ddl:     "CREATE TABLE products (id int);\n/* the end",
+			wantErr: "line 2: unterminated comment",
+		},
+		{
+			name:    "same module twice",
+			ddl:     "CREATE TABLE box (id int); CREATE TABLE boxes (id int);",
+			wantErr: "tables box and boxes both map to module box",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, warnings, err := ParseSQLSchema(tt.ddl)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseSQLSchema() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSQLSchema() error = %v", err)
+			}
+			if !reflect.DeepEqual(schema.Modules, tt.want) {
+				t.Errorf("ParseSQLSchema() modules = %+v, want %+v", schema.Modules, tt.want)
+			}
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("ParseSQLSchema() warnings = %q, want %q", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestToSingular(t *testing.T) {
+	for table, want := range map[string]string{
+		"products":    "product",
+		"categories":  "category",
+		"addresses":   "address",
+		"boxes":       "box",
+		"batches":     "batch",
+		"order_items": "order_item",
+		"status":      "status",
+		"inventory":   "inventory",
+	} {
+		if got := toSingular(table); got != want {
+			t.Errorf("toSingular(%q) = %q, want %q", table, got, want)
+		}
+	}
+}
+
+func TestApply_SQLSchema(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	schema, _, err := ParseSQLSchema(`CREATE TABLE categories (
+	id serial PRIMARY KEY,
+	name varchar(80) NOT NULL,
+	description text,
+	margin numeric(18,4) NOT NULL,
+	attributes jsonb
+);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Apply(schema); err != nil {
+		t.Fatal(err)
+	}
+	model := mustRead(t, mem, "internal/models/category.go")
+	for _, want := range []string{
+		"Name        string         `json:\"name\" gorm:\"size:80;not null\"`",
+		"Description *string        `json:\"description\" gorm:\"type:text\"`",
+		"Margin      float64        `json:\"margin\" gorm:\"type:numeric(18,4);not null\"`",
+		"Attributes  *string        `json:\"attributes\" gorm:\"type:jsonb\"`",
+		`return "categories"`,
+	} {
+		if !strings.Contains(model, want) {
+			t.Errorf("model does not contain %s:\n%s", want, model)
+		}
+	}
+	if request := mustRead(t, mem, "internal/requests/category_request.go"); !strings.Contains(request, `validate:"required,min=1,max=80"`) || !strings.Contains(request, `validate:"omitempty,json"`) {
+		t.Errorf("request does not limit the name to 80 characters or the attributes to JSON:\n%s", request)
+	}
+}
@@ -0,0 +1,131 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// stage holds the writes of a transaction until it commits.
+type stage struct {
+	files map[string][]byte // FS name -> content; nil deletes the file
+	order []string          // FS names in write order
+	dirs  []string          // FS names of directories to create
+}
+
+func (s *stage) write(name string, content []byte) {
+	if _, ok := s.files[name]; !ok {
+		s.order = append(s.order, name)
+	}
+	s.files[name] = content
+}
+
+// transaction runs fn with all writes staged in memory and commits them,
+// together with the updated manifest, only when fn succeeds, so a failed
+// generation leaves the project as it was. Nested transactions run fn as part
+// of the outer one; during a dry run nothing is staged.
+func (g *Generator) transaction(fn func() error) error {
+	if g.txDepth > 0 {
+		return fn()
+	}
+	g.txDepth++
+	if !g.DryRun {
+		g.staged = &stage{files: map[string][]byte{}}
+	}
+	err := fn()
+	if err == nil {
+		err = g.saveManifest()
+	}
+	staged := g.staged
+	g.staged = nil
+	g.txDepth--
+	g.manifest, g.manifestChanged = nil, false
+
+	if staged == nil {
+		return err
+	}
+	if err != nil {
+		if len(staged.order) > 0 {
+			fmt.Printf("Discarded %d staged change(s); no files were changed.\n", len(staged.order))
+		}
+		return err
+	}
+	return g.commit(staged)
+}
+
+// commit writes the staged directories and files and deletes the staged
+// deletions. It attempts every change so all failures are reported, and
+// restores the previous state when any fails.
+func (g *Generator) commit(staged *stage) error {
+	fsys := g.fsys()
+	var created []string // directories and files that did not exist before
+	previous := map[string][]byte{}
+	var errs []error
+
+	for _, dir := range staged.dirs {
+		created = append(created, g.missingDirs(dir)...)
+		if err := fsys.MkdirAll(dir); err != nil {
+			errs = append(errs, fmt.Errorf("failed to create directory %s: %w", dir, err))
+		}
+	}
+	for _, name := range staged.order {
+		content, readErr := fsys.ReadFile(name)
+		if staged.files[name] == nil {
+			if readErr != nil {
+				continue
+			}
+			if err := fsys.Remove(name); err != nil {
+				errs = append(errs, fmt.Errorf("failed to delete file %s: %w", name, err))
+				continue
+			}
+			previous[name] = content
+			continue
+		}
+		if readErr != nil {
+			created = append(created, g.missingDirs(path.Dir(name))...)
+		}
+		if err := fsys.WriteFile(name, staged.files[name]); err != nil {
+			errs = append(errs, fmt.Errorf("failed to write file %s: %w", name, err))
+			continue
+		}
+		if readErr == nil {
+			previous[name] = content
+		} else {
+			created = append(created, name)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	for name, content := range previous {
+		if err := fsys.WriteFile(name, content); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", name, err))
+		}
+	}
+	// Remove children before their parents.
+	sort.Sort(sort.Reverse(sort.StringSlice(created)))
+	for _, name := range created {
+		if err := fsys.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("failed to remove %s: %w", name, err))
+		}
+	}
+	fmt.Println("Rolled back all changes after failed writes.")
+	return errors.Join(errs...)
+}
+
+// missingDirs returns dir and those of its parents that do not exist yet.
+func (g *Generator) missingDirs(dir string) []string {
+	var missing []string
+	for dir != "." && dir != "/" && !strings.HasPrefix(dir, "..") {
+		if _, err := g.fsys().Stat(dir); err == nil {
+			break
+		}
+		missing = append(missing, dir)
+		dir = path.Dir(dir)
+	}
+	return missing
+}
@@ -0,0 +1,222 @@
+package generator
+
+// The package-level functions below generate into the current directory using
+// the package-level option variables (DryRun, TemplateDir, ...). They share
+// one Generator, so they must not be called concurrently; use New for that.
+
+// Init calls Generator.Init in the current directory.
+func Init(projectName string) error {
+	return defaultGenerator().Init(projectName)
+}
+
+// InitWithOptions calls Generator.InitWithOptions in the current directory.
+func InitWithOptions(opts InitOptions) error {
+	return defaultGenerator().InitWithOptions(opts)
+}
+
+// GenerateInitialStructure calls Generator.GenerateInitialStructure in the current directory.
+func GenerateInitialStructure(projectName string) error {
+	return defaultGenerator().GenerateInitialStructure(projectName)
+}
+
+// CreateMainGo calls Generator.CreateMainGo in the current directory.
+func CreateMainGo(projectName string) error {
+	return defaultGenerator().CreateMainGo(projectName)
+}
+
+// CreateSrcDir calls Generator.CreateSrcDir in the current directory.
+func CreateSrcDir() error {
+	return defaultGenerator().CreateSrcDir()
+}
+
+// CreateValidation calls Generator.CreateValidation in the current directory.
+func CreateValidation() error {
+	return defaultGenerator().CreateValidation()
+}
+
+// CreateDatabaseConnection calls Generator.CreateDatabaseConnection in the current directory.
+func CreateDatabaseConnection(projectName string) error {
+	return defaultGenerator().CreateDatabaseConnection(projectName)
+}
+
+// CreateHandleResponse calls Generator.CreateHandleResponse in the current directory.
+func CreateHandleResponse(projectName string) error {
+	return defaultGenerator().CreateHandleResponse(projectName)
+}
+
+// CreateConfigEnv calls Generator.CreateConfigEnv in the current directory.
+func CreateConfigEnv(projectName string) error {
+	return defaultGenerator().CreateConfigEnv(projectName)
+}
+
+// CreateConfigTimezonse calls Generator.CreateConfigTimezonse in the current directory.
+func CreateConfigTimezonse(projectName string) error {
+	return defaultGenerator().CreateConfigTimezonse(projectName)
+}
+
+// CreateAppErrs calls Generator.CreateAppErrs in the current directory.
+func CreateAppErrs() error {
+	return defaultGenerator().CreateAppErrs()
+}
+
+// CreateIDs calls Generator.CreateIDs in the current directory.
+func CreateIDs(projectName string) error {
+	return defaultGenerator().CreateIDs(projectName)
+}
+
+// CreateLoggers calls Generator.CreateLoggers in the current directory.
+func CreateLoggers(projectName string) error {
+	return defaultGenerator().CreateLoggers(projectName)
+}
+
+// CreatePagination calls Generator.CreatePagination in the current directory.
+func CreatePagination(projectName string) error {
+	return defaultGenerator().CreatePagination(projectName)
+}
+
+// CreateRoutes calls Generator.CreateRoutes in the current directory.
+func CreateRoutes() error {
+	return defaultGenerator().CreateRoutes()
+}
+
+// CreateFiberRoutes calls Generator.CreateFiberRoutes in the current directory.
+func CreateFiberRoutes(projectName string) error {
+	return defaultGenerator().CreateFiberRoutes(projectName)
+}
+
+// GenerateModule calls Generator.GenerateModule in the current directory.
+func GenerateModule(moduleName string, fieldSpecs ...string) error {
+	return defaultGenerator().GenerateModule(moduleName, fieldSpecs...)
+}
+
+// GenerateModuleWithID calls Generator.GenerateModuleWithID in the current
+// directory.
+func GenerateModuleWithID(moduleName, idType string, fieldSpecs ...string) error {
+	return defaultGenerator().GenerateModuleWithID(moduleName, idType, fieldSpecs...)
+}
+
+// GenerateModuleWithOptions calls Generator.GenerateModuleWithOptions in the
+// current directory.
+func GenerateModuleWithOptions(moduleName string, opts ModuleOptions, fieldSpecs ...string) error {
+	return defaultGenerator().GenerateModuleWithOptions(moduleName, opts, fieldSpecs...)
+}
+
+// CreateRequests calls Generator.CreateRequests in the current directory.
+func CreateRequests(filename string, fields ...Field) error {
+	return defaultGenerator().CreateRequests(filename, fields...)
+}
+
+// CreateResponses calls Generator.CreateResponses in the current directory.
+func CreateResponses(filename string, fields ...Field) error {
+	return defaultGenerator().CreateResponses(filename, fields...)
+}
+
+// CreateModels calls Generator.CreateModels in the current directory.
+func CreateModels(filename string, fields ...Field) error {
+	return defaultGenerator().CreateModels(filename, fields...)
+}
+
+// CreateRepositories calls Generator.CreateRepositories in the current directory.
+func CreateRepositories(filename string, projectName string) error {
+	return defaultGenerator().CreateRepositories(filename, projectName)
+}
+
+// CreateServices calls Generator.CreateServices in the current directory.
+func CreateServices(filename string, projectName string, fields ...Field) error {
+	return defaultGenerator().CreateServices(filename, projectName, fields...)
+}
+
+// CreateControllers calls Generator.CreateControllers in the current directory.
+func CreateControllers(filename string, projectName string) error {
+	return defaultGenerator().CreateControllers(filename, projectName)
+}
+
+// ResolveProjectName calls Generator.ResolveProjectName in the current directory.
+func ResolveProjectName() (string, error) {
+	return defaultGenerator().ResolveProjectName()
+}
+
+// CreateExampleConfig calls Generator.CreateExampleConfig in the current directory.
+func CreateExampleConfig(projectName string) error {
+	return defaultGenerator().CreateExampleConfig(projectName)
+}
+
+// GenerateTestFiles calls Generator.GenerateTestFiles in the current directory.
+func GenerateTestFiles(moduleName, projectName string) error {
+	return defaultGenerator().GenerateTestFiles(moduleName, projectName)
+}
+
+// GenerateTestFilesForce calls Generator.GenerateTestFilesForce in the current directory.
+func GenerateTestFilesForce(moduleName, projectName string) error {
+	return defaultGenerator().GenerateTestFilesForce(moduleName, projectName)
+}
+
+// GenerateAutoServiceTests calls Generator.GenerateAutoServiceTests in the current directory.
+func GenerateAutoServiceTests(moduleName, projectName string, force bool) error {
+	return defaultGenerator().GenerateAutoServiceTests(moduleName, projectName, force)
+}
+
+// CreateTestsStructure calls Generator.CreateTestsStructure in the current directory.
+func CreateTestsStructure(moduleName string, projectName string) error {
+	return defaultGenerator().CreateTestsStructure(moduleName, projectName)
+}
+
+// CreateMiddleware calls Generator.CreateMiddleware in the current directory.
+func CreateMiddleware(projectName string) error {
+	return defaultGenerator().CreateMiddleware(projectName)
+}
+
+// CreateEmptyMigrations calls Generator.CreateEmptyMigrations in the current directory.
+func CreateEmptyMigrations() error {
+	return defaultGenerator().CreateEmptyMigrations()
+}
+
+// CreateMigrations calls Generator.CreateMigrations in the current directory.
+func CreateMigrations(filename string, projectName string) error {
+	return defaultGenerator().CreateMigrations(filename, projectName)
+}
+
+// TakePlan calls Generator.TakePlan in the current directory.
+func TakePlan() []PlannedChange {
+	return defaultGenerator().TakePlan()
+}
+
+// RegisterModule calls Generator.RegisterModule in the current directory.
+func RegisterModule(moduleName, projectName string) error {
+	return defaultGenerator().RegisterModule(moduleName, projectName)
+}
+
+// EjectTemplate calls Generator.EjectTemplate in the current directory.
+func EjectTemplate(name, dir string, force bool) (string, error) {
+	return defaultGenerator().EjectTemplate(name, dir, force)
+}
+
+// Upgrade calls Generator.Upgrade in the current directory.
+func Upgrade() (UpgradeResult, error) {
+	return defaultGenerator().Upgrade()
+}
+
+// PlanRemoval calls Generator.PlanRemoval in the current directory.
+func PlanRemoval(moduleName string) (ModuleRemoval, error) {
+	return defaultGenerator().PlanRemoval(moduleName)
+}
+
+// RemoveModule calls Generator.RemoveModule in the current directory.
+func RemoveModule(moduleName string) error {
+	return defaultGenerator().RemoveModule(moduleName)
+}
+
+// RenameModule calls Generator.RenameModule in the current directory.
+func RenameModule(oldName, newName string, renameTable bool) (RenameResult, error) {
+	return defaultGenerator().RenameModule(oldName, newName, renameTable)
+}
+
+// Apply calls Generator.Apply in the current directory.
+func Apply(schema *Schema) (ApplyResult, error) {
+	return defaultGenerator().Apply(schema)
+}
+
+// DatabaseDSN calls Generator.DatabaseDSN in the current directory.
+func DatabaseDSN() (string, error) {
+	return defaultGenerator().DatabaseDSN()
+}
@@ -0,0 +1,654 @@
+package generator
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// projectTemplateDir is where `templates eject` copies templates by default.
+const projectTemplateDir = ".go-gen/templates"
+
+// readTemplate returns the content of templatePath (e.g.
+// "templates/controller.tmpl") from the first override that has it:
+// Options.TemplateDir, .go-gen/templates of the project, ~/.go-gen/templates.
+// It falls back to the embedded template.
+func (g *Generator) readTemplate(templatePath string) ([]byte, error) {
+	name := strings.TrimPrefix(templatePath, "templates/")
+	if g.TemplateDir != "" {
+		if content, err := readOverride(g.TemplateDir, name); content != nil || err != nil {
+			return content, err
+		}
+	}
+	content, err := g.readFile(path.Join(projectTemplateDir, name))
+	if err == nil {
+		return content, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if content, err := readOverride(filepath.Join(home, projectTemplateDir), name); content != nil || err != nil {
+			return content, err
+		}
+	}
+	return templatesFS.ReadFile(templatePath)
+}
+
+// readOverride reads the template name from dir on disk. It returns nil and
+// no error when dir has no such template.
+func readOverride(dir, name string) ([]byte, error) {
+	content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return content, err
+}
+
+// TemplateNames returns the names of the built-in templates, e.g.
+// "controller.tmpl".
+func TemplateNames() []string {
+	var names []string
+	_ = fs.WalkDir(templatesFS, "templates", func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			names = append(names, strings.TrimPrefix(p, "templates/"))
+		}
+		return nil
+	})
+	sort.Strings(names)
+	return names
+}
+
+// EjectTemplate copies the built-in template name (with or without the .tmpl
+// extension) into dir on disk so it can be customized; an empty dir means
+// .go-gen/templates of the project. Existing files are only overwritten when
+// force is set. It returns the path of the written file.
+func (g *Generator) EjectTemplate(name, dir string, force bool) (string, error) {
+	name = strings.TrimSuffix(strings.TrimSpace(name), ".tmpl") + ".tmpl"
+	content, err := templatesFS.ReadFile(path.Join("templates", name))
+	if err != nil {
+		return "", fmt.Errorf("unknown template %q (run `go-gen-r templates list`)", name)
+	}
+	if dir == "" {
+		target := filepath.Join(projectTemplateDir, filepath.FromSlash(name))
+		if g.fileExists(target) && !force {
+			return "", fmt.Errorf("%s already exists; use --force to overwrite it", target)
+		}
+		if err := g.fsys().WriteFile(fsName(target), content); err != nil {
+			return "", err
+		}
+		return target, nil
+	}
+	target := filepath.Join(dir, filepath.FromSlash(name))
+	if _, err := os.Stat(target); err == nil && !force {
+		return "", fmt.Errorf("%s already exists; use --force to overwrite it", target)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(target, content, 0644); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// templateData is the single data model passed to every template. Project
+// scaffolds only use ProjectName; module templates use the module fields.
+type templateData struct {
+	ProjectName    string  `json:"projectName,omitempty"`
+	Database       string  `json:"database,omitempty"`    // key of databaseDrivers, e.g. "postgres"
+	WithExample    bool    `json:"withExample,omitempty"` // main.go and routes wire the example module
+	ModuleName     string  `json:"moduleName,omitempty"`  // snake_case module name, e.g. "user_account"
+	ModelName      string  `json:"modelName,omitempty"`   // exported type name, e.g. "UserAccount"
+	VarName        string  `json:"varName,omitempty"`     // unexported identifier prefix, e.g. "userAccount"
+	PluralName     string  `json:"pluralName,omitempty"`  // route group and swagger tag, e.g. "user_accounts"
+	ServiceName    string  `json:"serviceName,omitempty"`
+	RepositoryName string  `json:"repositoryName,omitempty"`
+	Fields         []Field `json:"fields,omitempty"` // columns, including belongs_to foreign keys
+	// Associations are the has_many and many2many fields, which are not
+	// columns.
+	Associations []Field `json:"associations,omitempty"`
+	HasTime      bool    `json:"hasTime,omitempty"`
+	// Endpoints lists the controller endpoints to generate (see
+	// moduleEndpoints); empty for all.
+	Endpoints []string `json:"endpoints,omitempty"`
+	Table     string   `json:"table,omitempty"` // database table; see TableName
+	// Filters names the fields List filters on by equality, read from query
+	// parameters of the same name.
+	Filters []string `json:"filters,omitempty"`
+	// Status overrides the success status of endpoints; see SuccessStatus.
+	Status map[string]int `json:"status,omitempty"`
+	// ID is the primary key type, one of IDTypes; empty for uint.
+	ID string `json:"id,omitempty"`
+	// Pagination is how List paginates, one of PaginationModes; empty for
+	// offset.
+	Pagination string `json:"pagination,omitempty"`
+
+	// Service methods found by auto-test in the module's service file.
+	HasList   bool `json:"hasList,omitempty"`
+	HasGet    bool `json:"hasGet,omitempty"`
+	HasCreate bool `json:"hasCreate,omitempty"`
+	HasUpdate bool `json:"hasUpdate,omitempty"`
+	HasDelete bool `json:"hasDelete,omitempty"`
+
+	// Entries of migrations/migrations.go.
+	MigrationImports []string      `json:"migrationImports,omitempty"`
+	MigrationModels  []string      `json:"migrationModels,omitempty"`
+	MigrationRenames []tableRename `json:"migrationRenames,omitempty"`
+}
+
+// TableName returns the module's database table, by default the module name
+// with an "s" appended.
+func (d templateData) TableName() string {
+	if d.Table != "" {
+		return d.Table
+	}
+	return d.ModuleName + "s"
+}
+
+// Exposes reports whether the controller serves endpoint, one of
+// moduleEndpoints.
+func (d templateData) Exposes(endpoint string) bool {
+	return len(d.Endpoints) == 0 || slices.Contains(d.Endpoints, endpoint)
+}
+
+// ParsesID reports whether an exposed endpoint reads the :id route param.
+func (d templateData) ParsesID() bool {
+	return d.Exposes("get") || d.Exposes("update") || d.Exposes("delete")
+}
+
+// ParsesBody reports whether an exposed endpoint reads a request body.
+func (d templateData) ParsesBody() bool {
+	return d.Exposes("create") || d.Exposes("update")
+}
+
+// ParsesQuery reports whether the list endpoint reads filter query params.
+func (d templateData) ParsesQuery() bool {
+	return d.Exposes("list") && d.Filterable()
+}
+
+// Filterable reports whether List takes filters; see Filters.
+func (d templateData) Filterable() bool {
+	return len(d.Filters) > 0
+}
+
+// FilterFields returns the fields named by Filters.
+func (d templateData) FilterFields() []Field {
+	var fields []Field
+	for _, name := range d.Filters {
+		for _, f := range d.Fields {
+			if f.Name == name {
+				fields = append(fields, f)
+			}
+		}
+	}
+	return fields
+}
+
+// SearchColumns returns the text columns the list endpoint's search matches.
+func (d templateData) SearchColumns() []string {
+	var columns []string
+	for _, f := range d.Fields {
+		if f.Relation == "" && (f.Type == "string" || f.Type == "text" || f.Type == "email") {
+			columns = append(columns, f.Name)
+		}
+	}
+	return columns
+}
+
+// SortColumns returns the columns the list endpoint can order by, the ID
+// first as the default. Cursors cannot point past NULLs, so cursor
+// pagination leaves out optional columns.
+func (d templateData) SortColumns() []string {
+	columns := []string{"id"}
+	for _, f := range d.Fields {
+		if f.IsColumn() && !(d.PaginatesByCursor() && f.Optional) {
+			columns = append(columns, f.Name)
+		}
+	}
+	return append(columns, "created_at", "updated_at")
+}
+
+// DateColumns returns the columns the list endpoint can filter by a date
+// range, created_at first as the default.
+func (d templateData) DateColumns() []string {
+	columns := []string{"created_at", "updated_at"}
+	for _, f := range d.Fields {
+		if f.Relation == "" && f.Type == "time" {
+			columns = append(columns, f.Name)
+		}
+	}
+	return columns
+}
+
+// PaginatesByCursor reports whether List pages with after and before
+// cursors instead of page numbers.
+func (d templateData) PaginatesByCursor() bool {
+	return d.Pagination == "cursor"
+}
+
+// PageType returns the paginates type of the response List returns.
+func (d templateData) PageType() string {
+	if d.PaginatesByCursor() {
+		return "CursorPaginatedResponse"
+	}
+	return "PaginatedResponse"
+}
+
+// endpointStatuses lists the success statuses each endpoint can be generated
+// with; the first is the default.
+var endpointStatuses = map[string][]int{
+	"list":   {http.StatusOK},
+	"get":    {http.StatusOK},
+	"create": {http.StatusCreated, http.StatusOK},
+	"update": {http.StatusOK},
+	"delete": {http.StatusNoContent, http.StatusOK},
+}
+
+// SuccessStatus returns the HTTP status endpoint responds with on success.
+func (d templateData) SuccessStatus(endpoint string) int {
+	if status, ok := d.Status[endpoint]; ok {
+		return status
+	}
+	return endpointStatuses[endpoint][0]
+}
+
+// IDType returns the Go type of the module's ID.
+func (d templateData) IDType() string {
+	return idGoType(d.ID)
+}
+
+// idGormTag returns the gorm tag of the module's ID.
+func (d templateData) idGormTag() string {
+	switch d.ID {
+	case "int64":
+		return "primaryKey;autoIncrement:false"
+	case "uuid":
+		return "type:uuid;primaryKey;default:gen_random_uuid()"
+	case "ulid":
+		return "type:char(26);primaryKey"
+	}
+	return "primaryKey"
+}
+
+// GeneratesID reports whether the model sets its ID before create instead of
+// the database auto incrementing it.
+func (d templateData) GeneratesID() bool {
+	return d.ID != ""
+}
+
+// NewID returns the call of the generated ids package making a new ID.
+func (d templateData) NewID() string {
+	switch d.ID {
+	case "int64":
+		return "ids.NewSnowflake()"
+	case "uuid":
+		return "ids.NewUUID()"
+	}
+	return "ids.NewULID()"
+}
+
+// IDZero returns the zero value of the module's ID.
+func (d templateData) IDZero() string {
+	if d.IDType() == "string" {
+		return `""`
+	}
+	return "0"
+}
+
+// ParseID returns the expression parsing the :id route param into id and err.
+func (d templateData) ParseID() string {
+	switch d.ID {
+	case "int64":
+		return `strconv.ParseInt(ctx.Params("id"), 10, 64)`
+	case "uuid":
+		return `ids.ParseUUID(ctx.Params("id"))`
+	case "ulid":
+		return `ids.ParseULID(ctx.Params("id"))`
+	}
+	return `strconv.ParseUint(ctx.Params("id"), 10, 32)`
+}
+
+// IDArg returns the parsed :id route param as the module's ID.
+func (d templateData) IDArg() string {
+	if d.ID == "" {
+		return "uint(id)"
+	}
+	return "id"
+}
+
+// ParsesIDWithStrconv reports whether the controller parses numeric IDs.
+func (d templateData) ParsesIDWithStrconv() bool {
+	return d.ParsesID() && d.IDType() != "string"
+}
+
+// ParsesIDWithIDs reports whether the controller parses IDs with the
+// generated ids package.
+func (d templateData) ParsesIDWithIDs() bool {
+	return d.ParsesID() && d.IDType() == "string"
+}
+
+// SwaggerIDType returns the swagger type of the :id route param.
+func (d templateData) SwaggerIDType() string {
+	if d.IDType() == "string" {
+		return "string"
+	}
+	return "int"
+}
+
+// IDCond returns the arguments of a GORM query finding the record whose ID
+// is expr. String IDs need a condition: GORM would read a bare string as
+// SQL.
+func (d templateData) IDCond(expr string) string {
+	if d.IDType() == "string" {
+		return `"id = ?", ` + expr
+	}
+	return expr
+}
+
+// TestID returns the typed ID literal the generated service tests use.
+func (d templateData) TestID() string {
+	switch d.ID {
+	case "int64":
+		return "int64(1)"
+	case "uuid":
+		return `"0190a6f2-3c4d-7e5f-8a9b-0c1d2e3f4a5b"`
+	case "ulid":
+		return `"01J1V6X8Q3ZK4N5P6R7S8T9V0W"`
+	}
+	return "uint(1)"
+}
+
+// TestIDValue returns TestID as an untyped value.
+func (d templateData) TestIDValue() string {
+	if d.IDType() == "string" {
+		return d.TestID()
+	}
+	return "1"
+}
+
+// newProjectData returns template data for project-level scaffolds.
+func newProjectData(projectName string) templateData {
+	return templateData{ProjectName: projectName, Database: defaultDatabase, WithExample: true}
+}
+
+// DBConnect returns the name of the generated database connection func.
+func (d templateData) DBConnect() string {
+	return databaseDrivers[d.Database].connect
+}
+
+// newModuleData returns template data for a module. Fields default to
+// name:string when empty; has_many and many2many fields go to Associations.
+func newModuleData(projectName, moduleName string, fields []Field) templateData {
+	var associations []Field
+	fields = slices.DeleteFunc(slices.Clone(fields), func(f Field) bool {
+		if !f.IsColumn() {
+			associations = append(associations, f)
+		}
+		return !f.IsColumn()
+	})
+	if len(fields) == 0 {
+		fields = defaultFields()
+	}
+	fields = withSamples(moduleName, fields)
+	modelName := toPascal(moduleName)
+	return templateData{
+		ProjectName:    projectName,
+		ModuleName:     moduleName,
+		ModelName:      modelName,
+		VarName:        strings.ToLower(modelName[:1]) + modelName[1:],
+		PluralName:     toPlural(moduleName),
+		ServiceName:    modelName + "Service",
+		RepositoryName: modelName + "Repository",
+		Fields:         fields,
+		Associations:   associations,
+		HasTime:        hasTimeField(fields),
+	}
+}
+
+// HasRelations reports whether the model has associations to preload.
+func (d templateData) HasRelations() bool {
+	return len(d.Associations) > 0 || slices.ContainsFunc(d.Fields, func(f Field) bool { return f.Relation != "" })
+}
+
+// Many2Many returns the many2many fields, set from IDs in requests.
+func (d templateData) Many2Many() []Field {
+	var fields []Field
+	for _, f := range d.Associations {
+		if f.Relation == "many2many" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// OmitUpserts returns the arguments of the Omit call that keeps Create from
+// upserting the many2many models, whose IDs come from the request, e.g.
+// `"Tags.*"`.
+func (d templateData) OmitUpserts() string {
+	var names []string
+	for _, f := range d.Many2Many() {
+		names = append(names, fmt.Sprintf("%q", f.GoName+".*"))
+	}
+	return strings.Join(names, ", ")
+}
+
+// SamplesUseTime reports whether the Sample and Updated literals of the
+// fields need the "time" import; those of optional fields are nil.
+func (d templateData) SamplesUseTime() bool {
+	for _, f := range d.Fields {
+		if strings.HasPrefix(f.Sample, "time.") || strings.HasPrefix(f.Updated, "time.") {
+			return true
+		}
+	}
+	return false
+}
+
+// Key returns the aligned composite literal key for f.
+func (d templateData) Key(f Field) string {
+	return alignedKey(f, keyWidth(d.Fields))
+}
+
+// ModelStructFields returns the aligned fields of the GORM model.
+func (d templateData) ModelStructFields() []structField {
+	lines := []structField{{Name: "ID", Type: d.IDType(), Tag: fmt.Sprintf(`json:"id" gorm:"%s"`, d.idGormTag())}}
+	for _, f := range d.Fields {
+		lines = append(lines, structField{
+			Name: f.GoName,
+			Type: f.GoType,
+			Tag:  f.modelTag(),
+		})
+		if f.Relation == "belongs_to" {
+			lines = append(lines, structField{
+				Name: toGoName(f.Association()),
+				Type: "*" + f.ModelName(),
+				Tag:  fmt.Sprintf(`json:"%s,omitempty" gorm:"foreignKey:%s"`, f.Association(), f.GoName),
+			})
+		}
+	}
+	for _, f := range d.Associations {
+		gorm := "foreignKey:" + cmp.Or(f.ForeignKey, d.ModelName+"ID")
+		if f.Relation == "many2many" {
+			gorm = "many2many:" + cmp.Or(f.JoinTable, d.ModuleName+"_"+f.Name)
+			if f.JoinForeignKey != "" {
+				gorm += ";joinForeignKey:" + f.JoinForeignKey
+			}
+			if f.JoinReferences != "" {
+				gorm += ";joinReferences:" + f.JoinReferences
+			}
+		}
+		lines = append(lines, structField{Name: f.GoName, Type: f.GoType, Tag: fmt.Sprintf(`json:"%s,omitempty" gorm:"%s"`, f.Name, gorm)})
+	}
+	lines = append(lines,
+		structField{Name: "CreatedAt", Type: "time.Time", Tag: `json:"created_at"`},
+		structField{Name: "UpdatedAt", Type: "time.Time", Tag: `json:"updated_at"`},
+		structField{Name: "DeletedAt", Type: "gorm.DeletedAt", Tag: `json:"deleted_at,omitempty" gorm:"index"`},
+	)
+	return alignStructFields(lines)
+}
+
+// modelTag returns the struct tag of f on the GORM model.
+func (f Field) modelTag() string {
+	if f.GormTag == "" {
+		return fmt.Sprintf(`json:"%s"`, f.Name)
+	}
+	return fmt.Sprintf(`json:"%s" gorm:"%s"`, f.Name, f.GormTag)
+}
+
+// RequestStructFields returns the aligned fields of the Create/Update requests.
+func (d templateData) RequestStructFields() []structField {
+	lines := make([]structField, 0, len(d.Fields))
+	for _, f := range d.Fields {
+		tag := fmt.Sprintf(`json:"%s"`, f.Name)
+		if f.Validate != "" {
+			tag += fmt.Sprintf(` validate:"%s"`, f.Validate)
+		}
+		lines = append(lines, structField{Name: f.GoName, Type: f.GoType, Tag: tag})
+	}
+	for _, f := range d.Many2Many() {
+		lines = append(lines, structField{Name: f.IDsGoName(), Type: "[]" + idGoType(f.ModelID), Tag: fmt.Sprintf(`json:"%s"`, f.IDsName())})
+	}
+	return alignStructFields(lines)
+}
+
+// ListRequestStructFields returns the aligned fields of the List request.
+// They are pointers, so a missing query parameter does not filter.
+func (d templateData) ListRequestStructFields() []structField {
+	var lines []structField
+	for _, f := range d.FilterFields() {
+		lines = append(lines, structField{Name: f.GoName, Type: "*" + strings.TrimPrefix(f.GoType, "*"), Tag: fmt.Sprintf(`query:"%s"`, f.Name)})
+	}
+	return alignStructFields(lines)
+}
+
+// SwaggerType returns the swagger type of f as a query parameter.
+func (f Field) SwaggerType() string {
+	switch strings.TrimPrefix(f.GoType, "*") {
+	case "int", "int64", "uint":
+		return "integer"
+	case "float64":
+		return "number"
+	case "bool":
+		return "boolean"
+	}
+	return "string"
+}
+
+// ResponseStructFields returns the aligned fields of the module response.
+func (d templateData) ResponseStructFields() []structField {
+	lines := []structField{{Name: "ID", Type: d.IDType(), Tag: `json:"id"`}}
+	for _, f := range d.Fields {
+		lines = append(lines, structField{Name: f.GoName, Type: f.GoType, Tag: fmt.Sprintf(`json:"%s"`, f.Name)})
+	}
+	lines = append(lines,
+		structField{Name: "CreatedAt", Type: "time.Time", Tag: `json:"created_at"`},
+		structField{Name: "UpdatedAt", Type: "time.Time", Tag: `json:"updated_at"`},
+	)
+	return alignStructFields(lines)
+}
+
+type renderResult struct {
+	created bool // the file was created or its content changed
+	kept    bool // the file exists and overwriting it was declined
+}
+
+// renderTemplate renders the template at templatePath (see readTemplate)
+// with data.
+func (g *Generator) renderTemplate(templatePath string, data templateData) ([]byte, error) {
+	rawTemplate, err := g.readTemplate(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s: %w", templatePath, err)
+	}
+
+	tmpl, err := template.New(filepath.Base(templatePath)).Parse(string(rawTemplate))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", templatePath, err)
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %w", templatePath, err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// renderFile renders the template at templatePath for outputPath. Go output
+// is gofmt'ed with grouped imports; a template that emits invalid Go is an
+// error naming the template.
+func (g *Generator) renderFile(templatePath, outputPath string, data templateData) ([]byte, error) {
+	content, err := g.renderTemplate(templatePath, data)
+	if err != nil || !strings.HasSuffix(outputPath, ".go") {
+		return content, err
+	}
+	formatted, err := formatGo(content, data.ProjectName)
+	if err != nil {
+		return nil, fmt.Errorf("template %s produced invalid Go for %s: %w", templatePath, outputPath, err)
+	}
+	return formatted, nil
+}
+
+// renderTemplateToFile renders a template to outputPath, creating parent
+// directories as needed. Existing files are kept unless force is set.
+func (g *Generator) renderTemplateToFile(templatePath, outputPath string, data templateData, force bool) (renderResult, error) {
+	exists := g.fileExists(outputPath)
+	if exists && g.regenerate {
+		return g.regenerateFile(templatePath, outputPath, data)
+	}
+	if exists && !force {
+		if g.DryRun {
+			g.recordChange(ActionSkip, outputPath)
+		} else {
+			fmt.Println("File already exists:", outputPath)
+		}
+		return renderResult{created: false}, nil
+	}
+
+	content, err := g.renderFile(templatePath, outputPath, data)
+	if err != nil {
+		return renderResult{}, err
+	}
+
+	action, unchanged := ActionCreate, false
+	if exists {
+		action = ActionOverwrite
+		current, err := g.readFile(outputPath)
+		unchanged = err == nil && bytes.Equal(current, content)
+		overwrite, err := g.confirmOverwrite(outputPath, content)
+		if err != nil || !overwrite {
+			return renderResult{kept: err == nil}, err
+		}
+	}
+	if err := g.writeFile(outputPath, content, action); err != nil {
+		return renderResult{}, err
+	}
+	if err := g.recordFile(outputPath, templatePath, data, content); err != nil {
+		return renderResult{}, err
+	}
+
+	if g.DryRun {
+		return renderResult{created: !unchanged}, nil
+	}
+	if exists {
+		fmt.Println("Regenerated file:", outputPath)
+	} else {
+		fmt.Println("Created file:", outputPath)
+	}
+	return renderResult{created: !unchanged}, nil
+}
+
+// renderScaffold renders a template to a file that is only created when it
+// does not exist yet.
+func (g *Generator) renderScaffold(templatePath, outputPath string, data templateData) error {
+	_, err := g.renderTemplateToFile(templatePath, outputPath, data, false)
+	return err
+}
@@ -0,0 +1,174 @@
+package generator
+
+import (
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata/golden")
+
+// TestGolden generates a project with an extra module and compares every
+// generated file with testdata/golden/<path>.golden. Run
+// `go test ./pkg/generator -run TestGolden -update` after changing templates.
+func TestGolden(t *testing.T) {
+	goldenDir, err := filepath.Abs(filepath.Join("testdata", "golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("go.mod", []byte("module example.com/app\n\ngo 1.21\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenerateInitialStructure("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenerateModule("product", "name:string", "price:decimal", "stock:int", "released_at:time"); err != nil {
+		t.Fatal(err)
+	}
+
+	generated := map[string]bool{}
+	err = filepath.WalkDir(".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path == "go.mod" || path == ManifestFile {
+			return err
+		}
+		generated[path] = true
+		got, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		golden := filepath.Join(goldenDir, path+".golden")
+		if *updateGolden {
+			if err := os.MkdirAll(filepath.Dir(golden), os.ModePerm); err != nil {
+				return err
+			}
+			return os.WriteFile(golden, got, 0644)
+		}
+		want, err := os.ReadFile(golden)
+		if err != nil {
+			t.Errorf("unexpected generated file %s: %v", path, err)
+			return nil
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s does not match %s; rerun with -update if the change is intended", path, golden)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every golden file must still be generated.
+	err = filepath.WalkDir(goldenDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(goldenDir, path)
+		if rel = rel[:len(rel)-len(".golden")]; !generated[rel] {
+			t.Errorf("golden file %s has no generated counterpart", rel)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTemplateOverrides(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	custom := filepath.Join(dir, "custom")
+	if err := os.MkdirAll(custom, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(custom, "model.tmpl"), []byte("package models // custom {{.ModelName}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(projectTemplateDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	// The --templates directory wins over .go-gen/templates.
+	if err := os.WriteFile(filepath.Join(projectTemplateDir, "model.tmpl"), []byte("package models // project\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectTemplateDir, "request.tmpl"), []byte("package requests // project {{.ModelName}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	TemplateDir = custom
+	defer func() { TemplateDir = "" }()
+
+	CreateModels("product")
+	CreateRequests("product")
+	CreateResponses("product")
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"internal/models/product.go", "package models // custom Product\n"},
+		{"internal/requests/product_request.go", "package requests // project Product\n"},
+	}
+	for _, tt := range tests {
+		got, err := os.ReadFile(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	// Templates without an override fall back to the embedded ones.
+	if got, _ := os.ReadFile("internal/responses/product_response.go"); !strings.Contains(string(got), "type ProductResponse struct") {
+		t.Errorf("response was not rendered from the embedded template: %q", got)
+	}
+}
+
+func TestEjectTemplate(t *testing.T) {
+	dir := t.TempDir()
+	orig, _ := os.Getwd()
+	defer os.Chdir(orig)
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := EjectTemplate("controller", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(projectTemplateDir, "controller.tmpl"); target != want {
+		t.Errorf("EjectTemplate target = %q, want %q", target, want)
+	}
+	got, _ := os.ReadFile(target)
+	want, _ := templatesFS.ReadFile("templates/controller.tmpl")
+	if string(got) != string(want) {
+		t.Error("ejected template differs from the embedded one")
+	}
+
+	if _, err := EjectTemplate("controller.tmpl", "", false); err == nil {
+		t.Error("ejecting over an existing template without force succeeded")
+	}
+	if _, err := EjectTemplate("controller", "", true); err != nil {
+		t.Errorf("ejecting with force = %v", err)
+	}
+	if _, err := EjectTemplate("nope", "", false); err == nil {
+		t.Error("ejecting an unknown template succeeded")
+	}
+}
@@ -0,0 +1,244 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"sort"
+)
+
+// UpgradeResult lists what Upgrade did with the files of the manifest.
+type UpgradeResult struct {
+	Upgraded  []string // pristine files re-rendered with the current templates
+	Merged    []string // edited files the template changes were merged into
+	Conflicts []string // edited files merged with conflict markers
+	Missing   []string // recorded files that no longer exist
+}
+
+// Upgrade re-renders every file recorded in the manifest with the current
+// templates and the recorded template data. Files nobody edited are
+// overwritten. Edited files whose template output changed are merged with it
+// (see mergeThreeWay), with conflict markers where the edits and the
+// template change the same lines; edited files whose template output did not
+// change are left alone.
+func (g *Generator) Upgrade() (UpgradeResult, error) {
+	var result UpgradeResult
+	err := g.transaction(func() error {
+		m, err := g.Manifest()
+		if err != nil {
+			return err
+		}
+		if len(m.Files) == 0 {
+			return fmt.Errorf("no generated files recorded in %s; only projects generated with a manifest can be upgraded", ManifestFile)
+		}
+		names := make([]string, 0, len(m.Files))
+		for name := range m.Files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		version := generatorVersion()
+		for _, name := range names {
+			entry := m.Files[name]
+			current, err := g.readFile(name)
+			if errors.Is(err, fs.ErrNotExist) {
+				fmt.Println("File no longer exists, skipped:", name)
+				result.Missing = append(result.Missing, name)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			upgraded, err := g.renderRecorded(name, entry)
+			if err != nil {
+				return err
+			}
+			if contentHash(upgraded) == entry.Hash {
+				continue // the templates did not change this file
+			}
+
+			pristine := m.Pristine(name, current)
+			written, conflicts, err := g.replaceGenerated(name, g.mergeBase(name, entry), current, upgraded, pristine, "upgraded "+name+" ("+version+")")
+			if err != nil {
+				return err
+			}
+			if !written {
+				continue
+			}
+
+			// The manifest keeps the clean output, the base of the next merge,
+			// so a file whose conflicts are resolved in favor of the upgrade is
+			// pristine again.
+			entry.Generator = version
+			entry.setOutput(upgraded)
+			m.Files[name] = entry
+			g.manifestChanged = true
+
+			switch {
+			case pristine:
+				result.Upgraded = append(result.Upgraded, name)
+				if !g.DryRun {
+					fmt.Println("Upgraded file:", name)
+				}
+			case conflicts == 0:
+				result.Merged = append(result.Merged, name)
+				if !g.DryRun {
+					fmt.Println("Merged template changes into edited file:", name)
+				}
+			default:
+				result.Conflicts = append(result.Conflicts, name)
+				if !g.DryRun {
+					fmt.Println("Conflicts in edited file:", name)
+				}
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// replaceGenerated overwrites the generated file name with its new template
+// output: as is when the file is pristine, otherwise merged with the edits
+// made since the generator wrote base (see mergeThreeWay), or, when base is
+// nil, with conflict markers around each region where current differs from
+// output. It reports whether the file was written, which it is not when
+// nothing changes or the user declines, and the number of conflicts.
+func (g *Generator) replaceGenerated(name string, base, current, output []byte, pristine bool, outputLabel string) (bool, int, error) {
+	content, conflicts := output, 0
+	if !pristine {
+		var merged string
+		if base != nil {
+			merged, conflicts = mergeThreeWay(string(base), string(current), string(output), "current "+name, outputLabel)
+		} else {
+			merged, conflicts = mergeConflicts(string(current), string(output), "current "+name, outputLabel)
+		}
+		content = []byte(merged)
+	}
+	if bytes.Equal(content, current) {
+		return false, 0, nil
+	}
+	overwrite, err := g.confirmOverwrite(name, content)
+	if err != nil || !overwrite {
+		return false, 0, err
+	}
+	return true, conflicts, g.writeFile(name, content, ActionOverwrite)
+}
+
+// mergeBase returns what the generator last wrote to name, as recorded in
+// its manifest entry. Manifests that predate recording it only give it back
+// while the current templates still render the recorded hash; otherwise it
+// is nil.
+func (g *Generator) mergeBase(name string, entry ManifestEntry) []byte {
+	if base, ok := entry.output(); ok {
+		return base
+	}
+	if rendered, err := g.renderRecorded(name, entry); err == nil && contentHash(rendered) == entry.Hash {
+		return rendered
+	}
+	return nil
+}
+
+// regenerateFile renders a template over the existing file outputPath during
+// Apply, merging the output into edited files like Upgrade does.
+func (g *Generator) regenerateFile(templatePath, outputPath string, data templateData) (renderResult, error) {
+	m, err := g.Manifest()
+	if err != nil {
+		return renderResult{}, err
+	}
+	current, err := g.readFile(outputPath)
+	if err != nil {
+		return renderResult{}, err
+	}
+	content, err := g.renderFile(templatePath, outputPath, data)
+	if err != nil {
+		return renderResult{}, err
+	}
+	if entry, ok := m.Files[fsName(outputPath)]; ok && entry.Hash == contentHash(content) {
+		return renderResult{}, nil // the output did not change; keep any edits
+	}
+	pristine := m.Pristine(outputPath, current)
+	written, conflicts, err := g.replaceGenerated(outputPath, g.mergeBase(outputPath, m.Files[fsName(outputPath)]), current, content, pristine, "regenerated "+outputPath)
+	if err != nil || !written {
+		return renderResult{}, err
+	}
+	// As in Upgrade, the manifest keeps the clean output.
+	if err := g.recordFile(outputPath, templatePath, data, content); err != nil {
+		return renderResult{}, err
+	}
+	if conflicts > 0 {
+		g.conflicts = append(g.conflicts, outputPath)
+	}
+	if !g.DryRun {
+		switch {
+		case pristine:
+			fmt.Println("Updated file:", outputPath)
+		case conflicts == 0:
+			fmt.Println("Merged changes into edited file:", outputPath)
+		default:
+			fmt.Println("Conflicts in edited file:", outputPath)
+		}
+	}
+	return renderResult{created: true}, nil
+}
+
+// renderRecorded renders the manifest entry of name with the current
+// templates and re-applies the module registrations recorded for it.
+func (g *Generator) renderRecorded(name string, entry ManifestEntry) ([]byte, error) {
+	var data templateData
+	if err := json.Unmarshal(entry.Data, &data); err != nil {
+		return nil, fmt.Errorf("invalid template data for %s in %s: %w", name, ManifestFile, err)
+	}
+	data, err := data.rederived()
+	if err != nil {
+		return nil, fmt.Errorf("invalid template data for %s in %s: %w", name, ManifestFile, err)
+	}
+	content, err := g.renderFile("templates/"+entry.Template, name, data)
+	if err != nil {
+		return nil, err
+	}
+	for _, moduleName := range entry.Registered {
+		for _, target := range registrationTargets {
+			if target.path != name {
+				continue
+			}
+			out, err := target.rewrite(content, newModuleWiring(moduleName), data.ProjectName)
+			if err != nil {
+				return nil, fmt.Errorf("could not register module %s in upgraded %s: %w", moduleName, name, err)
+			}
+			if out != nil {
+				content = out
+			}
+		}
+	}
+	return content, nil
+}
+
+// rederived returns module data rebuilt from the module name and field specs,
+// so values derived by newer versions (e.g. Go types or GORM tags) are used.
+// Project data is returned unchanged.
+func (d templateData) rederived() (templateData, error) {
+	if d.ModuleName == "" {
+		return d, nil
+	}
+	declared := append(slices.Clone(d.Fields), d.Associations...)
+	specs := make([]string, len(declared))
+	for i, f := range declared {
+		specs[i] = f.spec()
+	}
+	fields, err := ParseFields(specs)
+	if err != nil {
+		return d, err
+	}
+	for i, f := range declared {
+		fields[i] = fields[i].withOptions(f.options()).withModelID(f.ModelID)
+		fields[i].relationKeys = f.relationKeys
+	}
+	fresh := newModuleData(d.ProjectName, d.ModuleName, fields)
+	fresh.Endpoints, fresh.Table, fresh.Filters, fresh.Status, fresh.ID = d.Endpoints, d.Table, d.Filters, d.Status, d.ID
+	fresh.Pagination = d.Pagination
+	fresh.HasList, fresh.HasGet, fresh.HasCreate, fresh.HasUpdate, fresh.HasDelete = d.HasList, d.HasGet, d.HasCreate, d.HasUpdate, d.HasDelete
+	return fresh, nil
+}
@@ -0,0 +1,155 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestUpgrade(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.GenerateModule("product", "name:string", "price:decimal"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Hand edits: the model's template changes below, the logger's does not.
+	editedModel := mustRead(t, mem, "internal/models/product.go") + "\n// Discount is applied at checkout.\n"
+	_ = mem.WriteFile("internal/models/product.go", []byte(editedModel))
+	editedLoggers := mustRead(t, mem, "logs/loggers.go") + "\n// Shared by all modules.\n"
+	_ = mem.WriteFile("logs/loggers.go", []byte(editedLoggers))
+	_ = mem.Remove("errs/errors.go")
+
+	// Newer templates: append a comment to some of them.
+	templates := t.TempDir()
+	for _, name := range []string{"pagination.tmpl", "model.tmpl", "main.tmpl", "loggers.tmpl", "errs.tmpl"} {
+		content, err := templatesFS.ReadFile("templates/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name != "loggers.tmpl" {
+			content = append(content, "\n// Generated by a newer version.\n"...)
+		}
+		if err := os.WriteFile(filepath.Join(templates, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.TemplateDir = templates
+
+	result, err := g.Upgrade()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := UpgradeResult{
+		Upgraded:  []string{"internal/models/example.go", "main.go", "paginates/pagination.go"},
+		Conflicts: []string{"internal/models/product.go"},
+		Missing:   []string{"errs/errors.go"},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Upgrade() = %+v, want %+v", result, want)
+	}
+
+	main := mustRead(t, mem, "main.go")
+	if !strings.Contains(main, "// Generated by a newer version.") || !strings.Contains(main, "productController := controllers.NewProductController") {
+		t.Errorf("upgraded main.go lost the template change or the product registration:\n%s", main)
+	}
+	model := mustRead(t, mem, "internal/models/product.go")
+	for _, want := range []string{
+		"<<<<<<< current internal/models/product.go\n\n// Discount is applied at checkout.\n=======\n\n// Generated by a newer version.\n>>>>>>> upgraded internal/models/product.go",
+		"type Product struct",
+	} {
+		if !strings.Contains(model, want) {
+			t.Errorf("merged model does not contain %q:\n%s", want, model)
+		}
+	}
+	if got := mustRead(t, mem, "logs/loggers.go"); got != editedLoggers {
+		t.Error("edited loggers.go was changed although its template was not")
+	}
+
+	// Upgraded files are pristine again and a second upgrade has nothing to do.
+	m, err := g.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Pristine("paginates/pagination.go", []byte(mustRead(t, mem, "paginates/pagination.go"))) {
+		t.Error("upgraded pagination.go is not pristine")
+	}
+	result, err = g.Upgrade()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Upgraded)+len(result.Conflicts) != 0 {
+		t.Errorf("second Upgrade() = %+v, want no changes", result)
+	}
+}
+
+func TestUpgrade_MergesEdits(t *testing.T) {
+	mem := NewMemFS()
+	g := New("", Options{})
+	g.FS = mem
+	if err := g.Init("example.com/app"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.GenerateModule("product", "name:string", "price:decimal"); err != nil {
+		t.Fatal(err)
+	}
+	discount := "\n// Discount returns the price after a discount of percent.\nfunc (p Product) Discount(percent float64) float64 {\n\treturn p.Price * (100 - percent) / 100\n}\n"
+	_ = mem.WriteFile("internal/models/product.go", []byte(mustRead(t, mem, "internal/models/product.go")+discount))
+
+	// A newer model template changes the doc comment of the struct.
+	templates := t.TempDir()
+	content, err := templatesFS.ReadFile("templates/model.tmpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content = []byte(strings.Replace(string(content), "represents the {{.ModuleName}} entity.", "is a stored {{.ModuleName}}.", 1))
+	if err := os.WriteFile(filepath.Join(templates, "model.tmpl"), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	g.TemplateDir = templates
+
+	result, err := g.Upgrade()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(result.Merged, "internal/models/product.go") || len(result.Conflicts) != 0 {
+		t.Errorf("Upgrade() = %+v, want the product model merged without conflicts", result)
+	}
+	model := mustRead(t, mem, "internal/models/product.go")
+	if !strings.Contains(model, discount) || !strings.Contains(model, "// Product is a stored product.") || strings.Contains(model, conflictStart) {
+		t.Errorf("upgraded model does not keep Discount and take the template change:\n%s", model)
+	}
+
+	// The next upgrade merges from the upgraded output, keeping Discount.
+	result, err = g.Upgrade()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Merged)+len(result.Conflicts) != 0 || mustRead(t, mem, "internal/models/product.go") != model {
+		t.Errorf("second Upgrade() = %+v, want no changes", result)
+	}
+}
+
+func TestUpgrade_NoManifest(t *testing.T) {
+	g := New("", Options{})
+	g.FS = NewMemFS()
+	if _, err := g.Upgrade(); err == nil || !strings.Contains(err.Error(), ManifestFile) {
+		t.Errorf("Upgrade() = %v, want an error naming %s", err, ManifestFile)
+	}
+}
+
+func mustRead(t *testing.T, fsys FS, name string) string {
+	t.Helper()
+	content, err := fsys.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}